@@ -0,0 +1,129 @@
+package main
+
+import (
+	"get.porter.sh/porter/pkg/porter"
+	"github.com/spf13/cobra"
+)
+
+func buildApprovalsCommands(p *porter.Porter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "approvals",
+		Aliases:     []string{"approval"},
+		Annotations: map[string]string{"group": "resource"},
+		Short:       "Approval commands",
+		Long: `Commands for reviewing requests for actions that require approval.
+
+Use the --request-approval flag with upgrade, uninstall or invoke to request an action instead of executing it. Another user must approve the request before the action is executed.`,
+	}
+
+	cmd.AddCommand(buildApprovalsListCommand(p))
+	cmd.AddCommand(buildApprovalsShowCommand(p))
+	cmd.AddCommand(buildApprovalsApproveCommand(p))
+	cmd.AddCommand(buildApprovalsRejectCommand(p))
+
+	return cmd
+}
+
+func buildApprovalsListCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.ApprovalListOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List action requests",
+		Long:  "List the requests for actions that require approval, newest first.",
+		Example: `  porter approvals list
+  porter approvals list --status pending
+  porter approvals list --installation wordpress -o json`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintApprovals(opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.Installation, "installation", "i", "",
+		"Only list requests for the installation.")
+	f.StringVar(&opts.Status, "status", "",
+		"Only list requests with the status. Allowed values: pending, running, approved, failed, rejected")
+	f.StringVarP(&opts.RawFormat, "output", "o", "table",
+		"Specify an output format.  Allowed values: table, json, yaml")
+
+	return cmd
+}
+
+func buildApprovalsShowCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.ApprovalShowOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "show ID",
+		Short: "Show an action request",
+		Long:  "Show an action request, including the plan for the action that is executed when it is approved.",
+		Example: `  porter approvals show 01EB6ADSY4N8JZRM9ZVT1Z5S2M
+  porter approvals show 01EB6ADSY4N8JZRM9ZVT1Z5S2M -o yaml`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.ShowApproval(opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.RawFormat, "output", "o", "table",
+		"Specify an output format.  Allowed values: table, json, yaml")
+
+	return cmd
+}
+
+func buildApprovalsApproveCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.ApprovalDecisionOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "approve ID",
+		Short: "Approve an action request",
+		Long: `Approve an action request and execute the action.
+
+The request must be approved by a different user than the one who requested it. Users are identified by the operating system account that runs porter, so the check does not prevent a user that can run porter as another account, for example with sudo, from approving their own request.
+
+Credential and parameter sets are resolved again when the action is executed, and the approval is recorded on the claim for the action.`,
+		Example: `  porter approvals approve 01EB6ADSY4N8JZRM9ZVT1Z5S2M
+  porter approvals approve 01EB6ADSY4N8JZRM9ZVT1Z5S2M --reason "reviewed the change in the planning meeting"`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.ApproveRequest(opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.Reason, "reason", "",
+		"Reason for approving the request.")
+
+	return cmd
+}
+
+func buildApprovalsRejectCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.ApprovalDecisionOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "reject ID",
+		Short:   "Reject an action request",
+		Long:    "Reject an action request so that the action is never executed.",
+		Example: `  porter approvals reject 01EB6ADSY4N8JZRM9ZVT1Z5S2M --reason "wait until after the release"`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.RejectRequest(opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.Reason, "reason", "",
+		"Reason for rejecting the request.")
+
+	return cmd
+}
@@ -28,6 +28,7 @@ func buildBundleCommands(p *porter.Porter) *cobra.Command {
 	cmd.AddCommand(buildBundleExplainCommand(p))
 	cmd.AddCommand(buildBundleCopyCommand(p))
 	cmd.AddCommand(buildBundleInspectCommand(p))
+	cmd.AddCommand(buildBundleRebaseCommand(p))
 
 	return cmd
 }
@@ -53,8 +54,11 @@ func buildBundleBuildCommand(p *porter.Porter) *cobra.Command {
 		Example: `  porter build
   porter build --name newbuns
   porter build --version 0.1.0
+  porter build --no-cache
   porter build --file path/to/porter.yaml
   porter build --dir path/to/build/context
+  porter build --secret id=npmrc,src=$HOME/.npmrc
+  porter build --secret id=token,secret=github-token
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return opts.Validate(p.Context)
@@ -66,6 +70,7 @@ func buildBundleBuildCommand(p *porter.Porter) *cobra.Command {
 
 	f := cmd.Flags()
 	f.BoolVar(&opts.NoLint, "no-lint", false, "Do not run the linter")
+	f.BoolVar(&opts.NoCache, "no-cache", false, "Do not reuse the cached build output of mixins from previous builds")
 	f.BoolVarP(&opts.Verbose, "verbose", "v", false, "Enable verbose logging")
 	f.StringVar(&opts.Name, "name", "", "Override the bundle name")
 	f.StringVar(&opts.Version, "version", "", "Override the bundle version")
@@ -73,6 +78,8 @@ func buildBundleBuildCommand(p *porter.Porter) *cobra.Command {
 		"Path to the Porter manifest. Defaults to `porter.yaml` in the current directory.")
 	f.StringVarP(&opts.Dir, "dir", "d", "",
 		"Path to the build context directory where all bundle assets are located.")
+	f.StringArrayVar(&opts.Secrets, "secret", nil,
+		"Secret to mount while building the invocation image, in the format id=ID,src=PATH to use a local file or id=ID,secret=NAME to resolve it with the secrets plugin. May be specified multiple times.")
 
 	return cmd
 }
@@ -84,6 +91,8 @@ func buildBundleLintCommand(p *porter.Porter) *cobra.Command {
 		Short: "Lint a bundle",
 		Long: `Check the bundle for problems and adherence to best practices by running linters for porter and the mixins used in the bundle.
 
+Rule packs listed in lint-rule-packs in the porter config file are evaluated as well, so that an organization can enforce its own standards for bundles.
+
 The lint command is run automatically when you build a bundle. The command is available separately so that you can just lint your bundle without also building it.`,
 		Example: `  porter lint
   porter lint --file path/to/porter.yaml
@@ -128,6 +137,7 @@ For example, the 'debug' driver may be specified, which simply logs the info giv
   porter bundle install --driver debug
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.AllowAccessToDockerHostSet = cmd.Flags().Changed("allow-docker-host-access")
 			return opts.Validate(args, p)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -172,8 +182,10 @@ For example, the 'debug' driver may be specified, which simply logs the info giv
   porter bundle upgrade --parameter-set azure --param test-mode=true --param header-color=blue
   porter bundle upgrade --cred azure --cred kubernetes
   porter bundle upgrade --driver debug
+  porter bundle upgrade --request-approval
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.AllowAccessToDockerHostSet = cmd.Flags().Changed("allow-docker-host-access")
 			return opts.Validate(args, p)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -194,8 +206,10 @@ For example, the 'debug' driver may be specified, which simply logs the info giv
 		"Define an individual parameter in the form NAME=VALUE. Overrides parameters otherwise set via --parameter-set. May be specified multiple times.")
 	f.StringSliceVarP(&opts.CredentialIdentifiers, "cred", "c", nil,
 		"Credential to use when installing the bundle. May be either a named set of credentials or a filepath, and specified multiple times.")
-	f.StringVarP(&opts.Driver, "driver", "d", porter.DefaultDriver,
-		"Specify a driver to use. Allowed values: docker, debug")
+	f.StringVarP(&opts.Driver, "driver", "d", "",
+		"Specify a driver to use. Allowed values: docker, debug. Defaults to the driver that the installation used previously, or docker.")
+	f.BoolVar(&opts.RequestApproval, "request-approval", false,
+		"Record a request for the action that another user must approve with porter approvals approve, instead of executing it.")
 	addBundlePullFlags(f, &opts.BundlePullOptions)
 
 	return cmd
@@ -219,8 +233,10 @@ For example, the 'debug' driver may be specified, which simply logs the info giv
   porter bundle invoke --action ACTION  --parameter-set azure --param test-mode=true --param header-color=blue
   porter bundle invoke --action ACTION --cred azure --cred kubernetes
   porter bundle invoke --action ACTION --driver debug
+  porter bundle invoke --action ACTION --request-approval
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.AllowAccessToDockerHostSet = cmd.Flags().Changed("allow-docker-host-access")
 			return opts.Validate(args, p)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -243,8 +259,10 @@ For example, the 'debug' driver may be specified, which simply logs the info giv
 		"Define an individual parameter in the form NAME=VALUE. Overrides parameters otherwise set via --parameter-set. May be specified multiple times.")
 	f.StringSliceVarP(&opts.CredentialIdentifiers, "cred", "c", nil,
 		"Credential to use when installing the bundle. May be either a named set of credentials or a filepath, and specified multiple times.")
-	f.StringVarP(&opts.Driver, "driver", "d", porter.DefaultDriver,
-		"Specify a driver to use. Allowed values: docker, debug")
+	f.StringVarP(&opts.Driver, "driver", "d", "",
+		"Specify a driver to use. Allowed values: docker, debug. Defaults to the driver that the installation used previously, or docker.")
+	f.BoolVar(&opts.RequestApproval, "request-approval", false,
+		"Record a request for the action that another user must approve with porter approvals approve, instead of executing it.")
 	addBundlePullFlags(f, &opts.BundlePullOptions)
 
 	return cmd
@@ -270,8 +288,10 @@ For example, the 'debug' driver may be specified, which simply logs the info giv
   porter bundle uninstall --driver debug
   porter bundle uninstall --delete
   porter bundle uninstall --force-delete
+  porter bundle uninstall --request-approval
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			opts.AllowAccessToDockerHostSet = cmd.Flags().Changed("allow-docker-host-access")
 			return opts.Validate(args, p)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -292,12 +312,14 @@ For example, the 'debug' driver may be specified, which simply logs the info giv
 		"Define an individual parameter in the form NAME=VALUE. Overrides parameters otherwise set via --parameter-set. May be specified multiple times.")
 	f.StringSliceVarP(&opts.CredentialIdentifiers, "cred", "c", nil,
 		"Credential to use when uninstalling the bundle. May be either a named set of credentials or a filepath, and specified multiple times.")
-	f.StringVarP(&opts.Driver, "driver", "d", porter.DefaultDriver,
-		"Specify a driver to use. Allowed values: docker, debug")
+	f.StringVarP(&opts.Driver, "driver", "d", "",
+		"Specify a driver to use. Allowed values: docker, debug. Defaults to the driver that the installation used previously, or docker.")
 	f.BoolVar(&opts.Delete, "delete", false,
 		"Delete all records associated with the installation, assuming the uninstall action succeeds")
 	f.BoolVar(&opts.ForceDelete, "force-delete", false,
 		"UNSAFE. Delete all records associated with the installation, even if uninstall fails. This is intended for cleaning up test data and is not recommended for production environments.")
+	f.BoolVar(&opts.RequestApproval, "request-approval", false,
+		"Record a request for the action that another user must approve with porter approvals approve, instead of executing it.")
 	addBundlePullFlags(f, &opts.BundlePullOptions)
 
 	return cmd
@@ -318,6 +340,7 @@ Note: if overrides for registry/tag/reference are provided, this command only re
   porter bundle publish --archive /tmp/mybuns.tgz --reference myrepo/my-buns:0.1.0
   porter bundle publish --tag latest
   porter bundle publish --registry myregistry.com/myorg
+  porter bundle publish --reference myregistry.com/myorg/mybuns:v0.1.0 --reference myregistry.com/myorg/mybuns:latest
 		`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return opts.Validate(p.Context)
@@ -334,7 +357,8 @@ Note: if overrides for registry/tag/reference are provided, this command only re
 	f.StringVarP(&opts.ArchiveFile, "archive", "a", "", "Path to the bundle archive in .tgz format")
 	f.StringVar(&opts.Tag, "tag", "", "Override the Docker tag portion of the bundle reference, e.g. latest, v0.1.1")
 	f.StringVar(&opts.Registry, "registry", "", "Override the registry portion of the bundle reference, e.g. docker.io, myregistry.com/myorg")
-	addReferenceFlag(f, &opts.BundlePullOptions)
+	f.StringSliceVarP(&opts.References, "reference", "r", nil,
+		"Publish the bundle to the given reference. May be specified multiple times to publish to multiple references. Defaults to the references in the publish section of the manifest.")
 	addInsecureRegistryFlag(f, &opts.BundlePullOptions)
 	// We aren't using addBundlePullFlags because we don't use --force since we are pushing, and that flag isn't needed
 
@@ -363,3 +387,38 @@ func buildBundleArchiveCommand(p *porter.Porter) *cobra.Command {
 
 	return &cmd
 }
+
+func buildBundleRebaseCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.RebaseOptions{}
+	cmd := cobra.Command{
+		Use:   "rebase",
+		Short: "Rebase a bundle onto a new base image",
+		Long: `Rebase the invocation image of a bundle onto a new base image, without rebuilding the bundle.
+
+The layers of the old base image at the bottom of the invocation image are swapped with the layers of the new base image, keeping the layers that porter added on top. The rebased invocation image digest is updated in the bundle, and the rebased bundle is published under a new tag.
+
+The old base image defaults to the base image recorded when the bundle was built. Bundles built with older versions of porter must specify it with --old-base.`,
+		Example: `  porter bundle rebase --reference getporter/hello:v0.1.0 --base debian:stretch-20210621-slim --tag v0.1.0-patch1
+  porter bundle rebase --reference getporter/hello:v0.1.0 --base debian:stretch-20210621-slim --destination myregistry.com/hello:v0.1.0-patch1
+  porter bundle rebase --reference getporter/hello:v0.1.0 --old-base debian:stretch-slim --base debian:stretch-20210621-slim --tag v0.1.0-patch1
+  porter bundle rebase --archive /tmp/hello.tgz --base debian:stretch-20210621-slim --destination myregistry.com/hello:v0.1.0-patch1
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(p.Context)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.Rebase(opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.Base, "base", "", "The new base image of the invocation image.")
+	f.StringVar(&opts.OldBase, "old-base", "", "The base image that the invocation image was built from. Defaults to the base image recorded when the bundle was built.")
+	f.StringVarP(&opts.ArchiveFile, "archive", "a", "", "Path to a bundle archive in .tgz format to rebase, instead of a published bundle.")
+	f.StringVar(&opts.Tag, "tag", "", "The tag of the rebased bundle, in the repository of the original bundle, e.g. v0.1.0-patch1")
+	f.StringVar(&opts.Destination, "destination", "", "The full reference of the rebased bundle, e.g. myregistry.com/myorg/mybuns:v0.1.0-patch1. Overrides --tag.")
+	addReferenceFlag(f, &opts.BundlePullOptions)
+	addInsecureRegistryFlag(f, &opts.BundlePullOptions)
+
+	return &cmd
+}
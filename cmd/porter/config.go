@@ -0,0 +1,50 @@
+package main
+
+import (
+	"get.porter.sh/porter/pkg/porter"
+	"github.com/spf13/cobra"
+)
+
+func buildConfigCommands(p *porter.Porter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Porter configuration commands",
+		Long: `Commands for working with porter's configuration.
+
+Settings are read from the config file in PORTER_HOME, then from the project config file, .porter/config.toml|yaml|json, in the current directory or the closest parent directory that has one, then from environment variables and finally from flags. Each source overrides the ones before it.`,
+		Annotations: map[string]string{
+			"group": "meta",
+		},
+	}
+
+	cmd.AddCommand(buildConfigShowCommand(p))
+
+	return cmd
+}
+
+func buildConfigShowCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.ConfigShowOptions{}
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the configuration settings",
+		Long:  "Show the configuration settings from the config files and environment variables, after they have been layered by precedence.",
+		Example: `  porter config show
+  porter config show --origin
+  porter config show --output json
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.ShowConfig(opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&opts.Origin, "origin", false,
+		"Include where each setting was set, such as a config file or an environment variable")
+	f.StringVarP(&opts.RawFormat, "output", "o", string(porter.ConfigDefaultFormat),
+		"Specify an output format.  Allowed values: plaintext, json, yaml")
+
+	return cmd
+}
@@ -21,6 +21,8 @@ func buildInstallationCommands(p *porter.Porter) *cobra.Command {
 	cmd.AddCommand(buildInstallationOutputsCommands(p))
 	cmd.AddCommand(buildInstallationDeleteCommand(p))
 	cmd.AddCommand(buildInstallationLogCommands(p))
+	cmd.AddCommand(buildInstallationResourcesCommand(p))
+	cmd.AddCommand(buildInstallationImportCNABCommand(p))
 
 	return cmd
 }
@@ -105,3 +107,69 @@ func buildInstallationDeleteCommand(p *porter.Porter) *cobra.Command {
 
 	return &cmd
 }
+
+func buildInstallationResourcesCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.ResourceListOptions{}
+
+	cmd := cobra.Command{
+		Use:   "resources [INSTALLATION]",
+		Short: "List the resources owned by an installation",
+		Long: `List the cloud or cluster resources owned by an installation.
+
+Bundles report the resources that they create in the resources output. Each run reports every resource that the installation owns, so the inventory of an installation is the resources reported by its latest successful run of an action that modifies the installation, until it is successfully uninstalled. Use --history to list the resources reported by each run instead, including failed runs.
+
+The --type and --name flags filter the resources with a pattern, such as aws_*. Resources without a name are matched by their id.
+
+Optional output formats include json and yaml, which include the links to each resource.`,
+		Example: `  porter installations resources wordpress
+  porter installations resources wordpress --type kubernetes/Deployment
+  porter installations resources wordpress --type 'aws_*' --output json
+  porter installations resources wordpress --history`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args, p.Context)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.PrintInstallationResources(opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.Type, "type", "",
+		"Only list resources with a type matching this pattern.")
+	f.StringVar(&opts.ResourceName, "name", "",
+		"Only list resources with a name matching this pattern.")
+	f.BoolVar(&opts.History, "history", false,
+		"List the resources reported by each run of the installation.")
+	f.StringVarP(&opts.RawFormat, "output", "o", "table",
+		"Specify an output format.  Allowed values: table, json, yaml")
+
+	return &cmd
+}
+
+func buildInstallationImportCNABCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.ImportCNABOptions{}
+
+	cmd := cobra.Command{
+		Use:   "import-cnab DIR",
+		Short: "Import installations from another CNAB tool",
+		Long: `Import installation records created by another CNAB tool.
+
+The directory should use the cnab-go filesystem layout, with claims, results and outputs directories. Claims stored in older versions of the claim schema are converted to the current schema, using the same logic as porter storage migrate.
+
+Installations that cannot be read, or that already exist in Porter, are skipped and listed in the report.`,
+		Example: `  porter installations import-cnab ~/.cnab
+  porter installations import-cnab ~/.cnab --output json`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate(args, p.Context)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.ImportCNABClaims(opts)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&opts.RawFormat, "output", "o", "table",
+		"Specify an output format.  Allowed values: table, json, yaml")
+
+	return &cmd
+}
@@ -29,9 +29,14 @@ func buildInstallationLogShowCommand(p *porter.Porter) *cobra.Command {
 		Short: "Show the logs from an installation",
 		Long: `Show the logs from an installation.
 
-Either display the logs from a specific run of a bundle with --run, or use --installation to display the logs from its most recent run.`,
+Either display the logs from a specific run of a bundle with --run, or use --installation to display the logs from its most recent run.
+
+The logs of each step are recorded separately. Use --summary to list the steps of the run with their status and the size of their logs, then display the logs of a single step with --step, or the logs of the step that failed with --failed-step.`,
 		Example: `  porter installation logs show --installation wordpress
-  porter installations logs show --run 01EZSWJXFATDE24XDHS5D5PWK6`,
+  porter installations logs show --run 01EZSWJXFATDE24XDHS5D5PWK6
+  porter installations logs show --run 01EZSWJXFATDE24XDHS5D5PWK6 --summary
+  porter installations logs show --run 01EZSWJXFATDE24XDHS5D5PWK6 --step 2
+  porter installations logs show --installation wordpress --failed-step`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return opts.Validate(p.Context)
 		},
@@ -45,6 +50,12 @@ Either display the logs from a specific run of a bundle with --run, or use --ins
 		"The installation that generated the logs.")
 	f.StringVarP(&opts.ClaimID, "run", "r", "",
 		"The bundle run that generated the logs.")
+	f.IntVar(&opts.Step, "step", 0,
+		"Only show the logs of the step with this number, starting from 1.")
+	f.BoolVar(&opts.FailedStep, "failed-step", false,
+		"Only show the logs of the step that failed.")
+	f.BoolVar(&opts.Summary, "summary", false,
+		"List the steps of the run with their status and the size of their logs.")
 
 	return cmd
 }
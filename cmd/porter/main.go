@@ -3,6 +3,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
 	"get.porter.sh/porter/pkg/config/datastore"
@@ -15,15 +16,28 @@ import (
 var includeDocsCommand = false
 
 func main() {
-	cmd := buildRootCommand()
-	if err := cmd.Execute(); err != nil {
+	p := porter.New()
+	cmd := buildRootCommandFrom(p)
+	err := cmd.Execute()
+
+	// Save the final state of an ephemeral command, even when it failed
+	if saveErr := p.SaveEphemeralState(); saveErr != nil {
+		fmt.Fprintln(os.Stderr, "Error:", saveErr)
+		os.Exit(1)
+	}
+
+	if err != nil {
 		os.Exit(1)
 	}
 }
 
 func buildRootCommand() *cobra.Command {
-	p := porter.New()
+	return buildRootCommandFrom(porter.New())
+}
+
+func buildRootCommandFrom(p *porter.Porter) *cobra.Command {
 	var printVersion bool
+	var ephemeralOpts porter.EphemeralOptions
 
 	cmd := &cobra.Command{
 		Use:   "porter",
@@ -43,6 +57,10 @@ func buildRootCommand() *cobra.Command {
 			p.Out = cmd.OutOrStdout()
 			p.Err = cmd.OutOrStderr()
 
+			if p.Ephemeral {
+				return p.UseEphemeralStorage(ephemeralOpts)
+			}
+
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -61,11 +79,18 @@ func buildRootCommand() *cobra.Command {
 
 	cmd.PersistentFlags().BoolVar(&p.Debug, "debug", false, "Enable debug logging")
 	cmd.PersistentFlags().BoolVar(&p.DebugPlugins, "debug-plugins", false, "Enable plugin debug logging")
+	cmd.PersistentFlags().BoolVar(&p.ReadOnly, "read-only", false, "Prevent changes to Porter's data, such as installations, credentials and parameters. Commands that only read data are allowed.")
+	cmd.PersistentFlags().BoolVar(&p.Ephemeral, "ephemeral", false, "Keep installations and secrets in memory instead of PORTER_HOME, for throwaway environments such as CI jobs. Commands that only make sense with persistent data are refused.")
+	cmd.PersistentFlags().StringVar(&ephemeralOpts.SecretsFile, "ephemeral-secrets", "", "Path to a yaml or json file that maps secret names to their values, used to seed the in-memory secrets when --ephemeral is set. Secrets may also be set with PORTER_EPHEMERAL_SECRET_NAME environment variables.")
+	cmd.PersistentFlags().StringVar(&ephemeralOpts.StateFile, "ephemeral-state", "", "Path to a file where the installations, including their claims and outputs, are saved when porter exits and loaded from when porter starts, when --ephemeral is set.")
+	cmd.PersistentFlags().BoolVar(&ephemeralOpts.IncludeSensitive, "ephemeral-state-sensitive", false, "Save the values of sensitive parameters and outputs in the --ephemeral-state file, which are omitted by default.")
 
 	cmd.Flags().BoolVarP(&printVersion, "version", "v", false, "Print the application version")
 
 	cmd.AddCommand(buildVersionCommand(p))
+	cmd.AddCommand(buildSelfUpdateCommand(p))
 	cmd.AddCommand(buildSchemaCommand(p))
+	cmd.AddCommand(buildConfigCommands(p))
 	cmd.AddCommand(buildStorageCommand(p))
 	cmd.AddCommand(buildRunCommand(p))
 	cmd.AddCommand(buildBundleCommands(p))
@@ -74,6 +99,7 @@ func buildRootCommand() *cobra.Command {
 	cmd.AddCommand(buildPluginsCommands(p))
 	cmd.AddCommand(buildCredentialsCommands(p))
 	cmd.AddCommand(buildParametersCommands(p))
+	cmd.AddCommand(buildApprovalsCommands(p))
 
 	for _, alias := range buildAliasCommands(p) {
 		cmd.AddCommand(alias)
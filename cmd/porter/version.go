@@ -1,6 +1,7 @@
 package main
 
 import (
+	"get.porter.sh/porter/pkg/pkgmgmt"
 	"get.porter.sh/porter/pkg/porter"
 	"get.porter.sh/porter/pkg/porter/version"
 	"github.com/spf13/cobra"
@@ -11,6 +12,13 @@ func buildVersionCommand(p *porter.Porter) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Print the application version",
+		Long: `Print the application version.
+
+Use --check to compare the installed version against the latest version of porter published on the mirror. By default porter is checked against https://cdn.porter.sh. To check against a mirror, set the environment variable PORTER_MIRROR, or mirror in the Porter config file, with the value to replace https://cdn.porter.sh with.`,
+		Example: `  porter version
+  porter version --system
+  porter version --check
+`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return opts.Validate()
 		},
@@ -26,6 +34,46 @@ func buildVersionCommand(p *porter.Porter) *cobra.Command {
 	f.StringVarP(&opts.RawFormat, "output", "o", string(version.DefaultVersionFormat),
 		"Specify an output format.  Allowed values: json, plaintext")
 	f.BoolVarP(&opts.System, "system", "s", false, "Print system debug information")
+	f.BoolVar(&opts.Check, "check", false, "Check if a newer version of porter is available")
+	f.StringVar(&opts.Mirror, "mirror", pkgmgmt.DefaultPackageMirror,
+		"Mirror of official Porter assets")
+
+	return cmd
+}
+
+func buildSelfUpdateCommand(p *porter.Porter) *cobra.Command {
+	opts := porter.SelfUpdateOptions{}
+	cmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Update porter to the latest version",
+		Long: `Update the porter client and runtime binaries in PORTER_HOME.
+
+The binaries are downloaded from the mirror, and their checksums are verified before they replace the installed binaries. The binaries that were replaced are kept so that the update can be undone with --rollback.
+
+By default porter is downloaded from https://cdn.porter.sh. To download from a mirror, set the environment variable PORTER_MIRROR, or mirror in the Porter config file, with the value to replace https://cdn.porter.sh with.`,
+		Example: `  porter self-update
+  porter self-update --version v0.38.0
+  porter self-update --version canary
+  porter self-update --rollback
+`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return p.SelfUpdate(opts)
+		},
+	}
+	cmd.Annotations = map[string]string{
+		"group": "meta",
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.Version, "version", "",
+		"The porter version to install, such as v1.2.3, latest or canary. Defaults to latest.")
+	f.BoolVar(&opts.Rollback, "rollback", false,
+		"Roll back to the version of porter that was installed before the last update")
+	f.StringVar(&opts.Mirror, "mirror", pkgmgmt.DefaultPackageMirror,
+		"Mirror of official Porter assets")
 
 	return cmd
 }
@@ -1,6 +1,7 @@
 package releases
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -20,6 +21,9 @@ var must = shx.CommandBuilder{StopOnError: true}
 
 const (
 	packagesRepo = "bin/mixins/.packages"
+
+	// checksumsFile is the name of the file with the checksums of a release's binaries
+	checksumsFile = "checksums.txt"
 )
 
 // Prepares bin directory for publishing a package
@@ -124,6 +128,10 @@ func PublishPlugin(plugin string) {
 }
 
 func publishPackageFeed(pkgType string, name string) {
+	publishFeed(pkgType, name, func() { generatePackageFeed(pkgType) })
+}
+
+func publishFeed(pkgType string, name string, generate func()) {
 	info := mage.LoadMetadata()
 
 	if !(info.Permalink == "canary" || info.IsTaggedRelease) {
@@ -142,8 +150,9 @@ func publishPackageFeed(pkgType string, name string) {
 	must.RunV("git", "clone", "--depth=1", remote, packagesRepo)
 	configureGitBotIn(packagesRepo)
 
-	generatePackageFeed(pkgType)
+	generate()
 
+	must.Command("git", "add", ".").In(packagesRepo).RunV()
 	must.Command("git", "commit", "--signoff", "--author='Porter Bot<bot@porter.sh>'", "-am", fmt.Sprintf("Add %s@%s to %s feed", name, info.Version, pkgType)).
 		In(packagesRepo).RunV()
 	must.Command("git", "push").In(packagesRepo).RunV()
@@ -165,6 +174,41 @@ func generatePackageFeed(pkgType string) {
 	must.RunV("bin/porter", "mixins", "feed", "generate", "-d", filepath.Join("bin", pkgDir), "-f", feedFile, "-t", "build/atom-template.xml")
 }
 
+// Generate an updated feed of porter releases and publishes it.
+func PublishPorterFeed() {
+	publishFeed("porter", "porter", GeneratePorterFeed)
+}
+
+// Generate a feed of porter releases from any porter versions in bin.
+// The feed is used by porter version --check and porter self-update.
+func GeneratePorterFeed() {
+	// bin also holds the mixins and plugins, so only link the porter binaries
+	// from each version directory into the directory that the feed is generated from
+	feedDir, err := ioutil.TempDir("bin", ".porter-feed")
+	mgx.Must(errors.Wrap(err, "error creating temp directory"))
+	defer os.RemoveAll(feedDir)
+
+	versions, err := ioutil.ReadDir("bin")
+	mgx.Must(errors.Wrap(err, "error listing files in bin"))
+	for _, version := range versions {
+		if !version.IsDir() {
+			continue
+		}
+
+		binaries, err := filepath.Glob(filepath.Join("bin", version.Name(), "porter-*"))
+		mgx.Must(errors.Wrapf(err, "error listing the porter binaries in bin/%s", version.Name()))
+		for _, bin := range binaries {
+			dest := filepath.Join(feedDir, version.Name(), filepath.Base(bin))
+			mgx.Must(os.MkdirAll(filepath.Dir(dest), 0755))
+			// Hard links keep the modified time, which is used as the date of the release
+			mgx.Must(errors.Wrapf(os.Link(bin, dest), "error linking %s", bin))
+		}
+	}
+
+	feedFile := filepath.Join(packagesRepo, "porter", "atom.xml")
+	must.RunV("bin/porter", "mixins", "feed", "generate", "-d", feedDir, "-f", feedFile, "-t", "build/porter-atom-template.xml")
+}
+
 // Generate a mixin feed from any mixin versions in bin/mixins.
 func GenerateMixinFeed() {
 	generatePackageFeed("mixin")
@@ -175,6 +219,27 @@ func GeneratePluginFeed() {
 	generatePackageFeed("plugin")
 }
 
+// GenerateChecksums writes the sha256 checksum of each file in the directory
+// to checksums.txt, in the format used by sha256sum.
+func GenerateChecksums(dir string) {
+	files, err := ioutil.ReadDir(dir)
+	mgx.Must(errors.Wrapf(err, "error listing files in %s", dir))
+
+	var checksums strings.Builder
+	for _, fi := range files {
+		if fi.IsDir() || fi.Name() == checksumsFile {
+			continue
+		}
+
+		contents, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		mgx.Must(errors.Wrapf(err, "error reading %s", fi.Name()))
+		fmt.Fprintf(&checksums, "%x  %s\n", sha256.Sum256(contents), fi.Name())
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, checksumsFile), []byte(checksums.String()), 0644)
+	mgx.Must(errors.Wrapf(err, "error writing %s", checksumsFile))
+}
+
 // AddFilesToRelease uploads the files in the specified directory to a GitHub release.
 // If the release does not exist already, it will be created with empty release notes.
 func AddFilesToRelease(repo string, tag string, dir string) {
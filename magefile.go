@@ -238,6 +238,7 @@ func PublishPorter() {
 
 	porterVersionDir := filepath.Join("bin", info.Version)
 	execVersionDir := filepath.Join("bin/mixins/exec", info.Version)
+	releases.GenerateChecksums(porterVersionDir)
 	var repo = os.Getenv("PORTER_RELEASE_REPOSITORY")
 	if repo == "" {
 		repo = "github.com/getporter/porter"
@@ -0,0 +1,223 @@
+package approvals
+
+import (
+	"time"
+
+	cnabprovider "get.porter.sh/porter/pkg/cnab/provider"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/claim"
+	"github.com/pkg/errors"
+)
+
+const (
+	// StatusPending indicates that the request is waiting to be approved or rejected.
+	StatusPending = "pending"
+
+	// StatusRunning indicates that the request was approved and the action is
+	// being executed. A request that stays running was interrupted, and the
+	// installation should be checked before the action is requested again.
+	StatusRunning = "running"
+
+	// StatusApproved indicates that the request was approved and the action was executed.
+	StatusApproved = "approved"
+
+	// StatusFailed indicates that the request was approved but the action failed.
+	StatusFailed = "failed"
+
+	// StatusRejected indicates that the request was rejected and the action was not executed.
+	StatusRejected = "rejected"
+
+	// ClaimExtensionKey is the key in the custom section of a claim where the
+	// approval for the action is recorded.
+	ClaimExtensionKey = "sh.porter.approval"
+)
+
+// ActionRequest is a request to execute an action against an installation.
+// The action is only executed after another user approves the request.
+type ActionRequest struct {
+	// ID of the request.
+	ID string `json:"id" yaml:"id"`
+
+	// Created timestamp of the request.
+	Created time.Time `json:"created" yaml:"created"`
+
+	// RequestedBy is the user that requested the action.
+	RequestedBy string `json:"requestedBy" yaml:"requestedBy"`
+
+	// RequestedByID is the account id of the user that requested the action.
+	RequestedByID string `json:"requestedById,omitempty" yaml:"requestedById,omitempty"`
+
+	// Status of the request: pending, running, approved, failed or rejected.
+	Status string `json:"status" yaml:"status"`
+
+	// Error returned by the action, when it failed after the request was approved.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+
+	// Arguments used to execute the action. Values resolved from credential and
+	// parameter sets are not stored, the sets are resolved again when the
+	// request is approved.
+	Arguments cnabprovider.ActionArguments `json:"arguments" yaml:"arguments"`
+
+	// AllowDockerHostAccessSet indicates that docker host access was specified
+	// for the action, instead of using the setting recorded with the installation.
+	AllowDockerHostAccessSet bool `json:"allowDockerHostAccessSet,omitempty" yaml:"allowDockerHostAccessSet,omitempty"`
+
+	// ParameterSets to resolve when the action is executed.
+	ParameterSets []string `json:"parameterSets,omitempty" yaml:"parameterSets,omitempty"`
+
+	// Bundle definition to use for the action, when it is not the bundle that
+	// is already installed.
+	Bundle *bundle.Bundle `json:"bundle,omitempty" yaml:"bundle,omitempty"`
+
+	// RelocationMapping is the contents of the relocation mapping for the bundle, if one exists.
+	RelocationMapping string `json:"relocationMapping,omitempty" yaml:"relocationMapping,omitempty"`
+
+	// Delete the installation after it is uninstalled.
+	Delete bool `json:"delete,omitempty" yaml:"delete,omitempty"`
+
+	// ForceDelete the installation after it is uninstalled, even when the uninstall fails.
+	ForceDelete bool `json:"forceDelete,omitempty" yaml:"forceDelete,omitempty"`
+
+	// Plan summarizes the changes that the action makes to the installation.
+	Plan Plan `json:"plan" yaml:"plan"`
+
+	// Decision made by the user that approved or rejected the request.
+	Decision *Decision `json:"decision,omitempty" yaml:"decision,omitempty"`
+}
+
+// Plan summarizes the changes that an action makes to an installation, so
+// that the approver can review them.
+type Plan struct {
+	// Revision of the installation when the action was requested. The request
+	// can only be approved while the installation is still at this revision.
+	Revision string `json:"revision" yaml:"revision"`
+
+	// CurrentBundle is the name and version of the bundle that is installed.
+	CurrentBundle string `json:"currentBundle" yaml:"currentBundle"`
+
+	// Bundle is the name and version of the bundle used to execute the action.
+	Bundle string `json:"bundle" yaml:"bundle"`
+
+	// BundleReference is the reference to the bundle used to execute the action, if one was specified.
+	BundleReference string `json:"bundleReference,omitempty" yaml:"bundleReference,omitempty"`
+
+	// Parameters that are set on the command line.
+	Parameters []string `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+
+	// ParameterSets that are resolved when the action is executed.
+	ParameterSets []string `json:"parameterSets,omitempty" yaml:"parameterSets,omitempty"`
+
+	// CredentialSets that are resolved when the action is executed.
+	CredentialSets []string `json:"credentialSets,omitempty" yaml:"credentialSets,omitempty"`
+}
+
+// Decision records who approved or rejected a request.
+type Decision struct {
+	// By is the user that made the decision.
+	By string `json:"by" yaml:"by"`
+
+	// ByID is the account id of the user that made the decision.
+	ByID string `json:"byId,omitempty" yaml:"byId,omitempty"`
+
+	// Timestamp of the decision.
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+
+	// Reason given for the decision.
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// Approval is the approval chain for an action, recorded on the claim of the
+// action that was executed.
+type Approval struct {
+	// RequestID is the ID of the approved request.
+	RequestID string `json:"requestId" yaml:"requestId"`
+
+	// RequestedBy is the user that requested the action.
+	RequestedBy string `json:"requestedBy" yaml:"requestedBy"`
+
+	// Requested is the timestamp of the request.
+	Requested time.Time `json:"requested" yaml:"requested"`
+
+	// ApprovedBy is the user that approved the action.
+	ApprovedBy string `json:"approvedBy" yaml:"approvedBy"`
+
+	// Approved is the timestamp of the approval.
+	Approved time.Time `json:"approved" yaml:"approved"`
+}
+
+// NewActionRequest creates a pending request for an action.
+func NewActionRequest(requestedBy User, args cnabprovider.ActionArguments) (ActionRequest, error) {
+	id, err := claim.NewULID()
+	if err != nil {
+		return ActionRequest{}, errors.Wrap(err, "could not generate an id for the request")
+	}
+
+	return ActionRequest{
+		ID:            id,
+		Created:       time.Now(),
+		RequestedBy:   requestedBy.Name,
+		RequestedByID: requestedBy.ID,
+		Status:        StatusPending,
+		Arguments:     args,
+	}, nil
+}
+
+// Approve the request, returning the approval chain to record on the claim.
+func (r *ActionRequest) Approve(approvedBy User) (Approval, error) {
+	if err := r.validateDecision(); err != nil {
+		return Approval{}, err
+	}
+	if r.isRequester(approvedBy) {
+		return Approval{}, errors.Errorf("request %s must be approved by a different user than the one that requested it, %s", r.ID, r.RequestedBy)
+	}
+
+	r.Status = StatusRunning
+	r.Decision = &Decision{By: approvedBy.Name, ByID: approvedBy.ID, Timestamp: time.Now()}
+	return Approval{
+		RequestID:   r.ID,
+		RequestedBy: r.RequestedBy,
+		Requested:   r.Created,
+		ApprovedBy:  approvedBy.Name,
+		Approved:    r.Decision.Timestamp,
+	}, nil
+}
+
+// RecordResult records the outcome of the action executed for an approved
+// request, marking the request as approved when the action succeeded, and
+// as failed otherwise.
+func (r *ActionRequest) RecordResult(actionErr error) {
+	if actionErr == nil {
+		r.Status = StatusApproved
+		return
+	}
+	r.Status = StatusFailed
+	r.Error = actionErr.Error()
+}
+
+// Reject the request so that the action is never executed.
+func (r *ActionRequest) Reject(rejectedBy User, reason string) error {
+	if err := r.validateDecision(); err != nil {
+		return err
+	}
+
+	r.Status = StatusRejected
+	r.Decision = &Decision{By: rejectedBy.Name, ByID: rejectedBy.ID, Timestamp: time.Now(), Reason: reason}
+	return nil
+}
+
+// isRequester determines if the user requested the action. Users are compared
+// by their account id, falling back to the name for requests that did not
+// record the id.
+func (r *ActionRequest) isRequester(u User) bool {
+	if r.RequestedByID != "" {
+		return u.ID == r.RequestedByID
+	}
+	return u.Name == r.RequestedBy
+}
+
+func (r *ActionRequest) validateDecision() error {
+	if r.Status != StatusPending {
+		return errors.Errorf("request %s is already %s", r.ID, r.Status)
+	}
+	return nil
+}
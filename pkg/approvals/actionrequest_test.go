@@ -0,0 +1,87 @@
+package approvals
+
+import (
+	"os"
+	"os/user"
+	"testing"
+
+	cnabprovider "get.porter.sh/porter/pkg/cnab/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionRequest_Approve(t *testing.T) {
+	alice := User{Name: "alice", ID: "1000"}
+	r, err := NewActionRequest(alice, cnabprovider.ActionArguments{Action: "upgrade", Installation: "wordpress"})
+	require.NoError(t, err, "NewActionRequest failed")
+	assert.Equal(t, StatusPending, r.Status)
+	assert.Equal(t, "1000", r.RequestedByID)
+
+	_, err = r.Approve(alice)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be approved by a different user")
+	assert.Equal(t, StatusPending, r.Status, "the request should still be pending")
+
+	_, err = r.Approve(User{Name: "bob", ID: "1000"})
+	require.Error(t, err, "the requester should not be able to approve under another name")
+	assert.Contains(t, err.Error(), "must be approved by a different user")
+
+	approval, err := r.Approve(User{Name: "bob", ID: "1001"})
+	require.NoError(t, err, "Approve failed")
+	assert.Equal(t, StatusRunning, r.Status, "the request should be running until the result is recorded")
+	assert.Equal(t, r.ID, approval.RequestID)
+	assert.Equal(t, "alice", approval.RequestedBy)
+	assert.Equal(t, "bob", approval.ApprovedBy)
+	assert.Equal(t, "bob", r.Decision.By)
+	assert.Equal(t, "1001", r.Decision.ByID)
+
+	err = r.Reject(User{Name: "carol", ID: "1002"}, "too late")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is already running")
+
+	_, err = r.Approve(User{Name: "carol", ID: "1002"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is already running")
+
+	r.RecordResult(nil)
+	assert.Equal(t, StatusApproved, r.Status)
+}
+
+func TestActionRequest_Reject(t *testing.T) {
+	alice := User{Name: "alice", ID: "1000"}
+	r, err := NewActionRequest(alice, cnabprovider.ActionArguments{Action: "uninstall", Installation: "wordpress"})
+	require.NoError(t, err, "NewActionRequest failed")
+
+	err = r.Reject(alice, "changed my mind")
+	require.NoError(t, err, "the requester should be able to withdraw their request")
+	assert.Equal(t, StatusRejected, r.Status)
+	assert.Equal(t, "changed my mind", r.Decision.Reason)
+
+	_, err = r.Approve(User{Name: "bob", ID: "1001"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is already rejected")
+}
+
+func TestActionRequest_Approve_RequestWithoutUserID(t *testing.T) {
+	r := ActionRequest{ID: "abc123", RequestedBy: "alice", Status: StatusPending}
+
+	_, err := r.Approve(User{Name: "alice", ID: "1000"})
+	require.Error(t, err, "requests without an account id should be compared by name")
+	assert.Contains(t, err.Error(), "must be approved by a different user")
+}
+
+func TestOSUserProvider_CurrentUser(t *testing.T) {
+	if orig, ok := os.LookupEnv("USER"); ok {
+		defer os.Setenv("USER", orig)
+	} else {
+		defer os.Unsetenv("USER")
+	}
+	os.Setenv("USER", "someone-else")
+
+	want, err := user.Current()
+	require.NoError(t, err)
+
+	got, err := OSUserProvider{}.CurrentUser()
+	require.NoError(t, err)
+	assert.Equal(t, want.Uid, got.ID, "the user should be identified by the account that runs porter")
+}
@@ -0,0 +1 @@
+package approvals // import "get.porter.sh/porter/pkg/approvals"
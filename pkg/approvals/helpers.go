@@ -0,0 +1,25 @@
+package approvals
+
+var _ UserProvider = &TestUserProvider{}
+
+// TestUserProvider impersonates users in tests.
+type TestUserProvider struct {
+	User User
+}
+
+// NewTestUserProvider creates a user provider that returns the named user.
+func NewTestUserProvider(name string) *TestUserProvider {
+	p := &TestUserProvider{}
+	p.SetUser(name)
+	return p
+}
+
+// SetUser changes the current user, using the name as the account id.
+func (p *TestUserProvider) SetUser(name string) {
+	p.User = User{Name: name, ID: name}
+}
+
+// CurrentUser returns the impersonated user.
+func (p *TestUserProvider) CurrentUser() (User, error) {
+	return p.User, nil
+}
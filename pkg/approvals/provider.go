@@ -0,0 +1,9 @@
+package approvals
+
+// ApprovalProvider interface for managing action requests.
+type ApprovalProvider interface {
+	List() ([]string, error)
+	Save(ActionRequest) error
+	Read(id string) (ActionRequest, error)
+	ReadAll() ([]ActionRequest, error)
+}
@@ -0,0 +1,79 @@
+package approvals
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cnabio/cnab-go/utils/crud"
+)
+
+// ItemType is the location in the backing store where action requests are persisted.
+const ItemType = "approvals"
+
+// ErrNotFound represents an action request not found in storage
+var ErrNotFound = errors.New("Action request does not exist")
+
+var _ ApprovalProvider = Store{}
+
+// Store is a persistent store for action requests.
+type Store struct {
+	backingStore crud.ManagedStore
+}
+
+// NewApprovalStore creates a persistent store for action requests using the
+// specified backing key-blob store.
+func NewApprovalStore(store crud.ManagedStore) Store {
+	return Store{
+		backingStore: store,
+	}
+}
+
+// List the IDs of the stored action requests.
+func (s Store) List() ([]string, error) {
+	return s.backingStore.List(ItemType, "")
+}
+
+// Save an action request. Any previous version of the request is overwritten.
+func (s Store) Save(r ActionRequest) error {
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.backingStore.Save(ItemType, "", r.ID, bytes)
+}
+
+// Read loads the action request with the given ID from the store.
+func (s Store) Read(id string) (ActionRequest, error) {
+	bytes, err := s.backingStore.Read(ItemType, id)
+	if err != nil {
+		if strings.Contains(err.Error(), crud.ErrRecordDoesNotExist.Error()) {
+			return ActionRequest{}, ErrNotFound
+		}
+		return ActionRequest{}, err
+	}
+	r := ActionRequest{}
+	err = json.Unmarshal(bytes, &r)
+	return r, err
+}
+
+// ReadAll retrieves all the action requests.
+func (s Store) ReadAll() ([]ActionRequest, error) {
+	results, err := s.backingStore.ReadAll(ItemType, "")
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make([]ActionRequest, len(results))
+	for i, bytes := range results {
+		var r ActionRequest
+		err = json.Unmarshal(bytes, &r)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshaling action request: %v", err)
+		}
+		requests[i] = r
+	}
+
+	return requests, nil
+}
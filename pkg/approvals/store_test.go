@@ -0,0 +1,44 @@
+package approvals
+
+import (
+	"testing"
+
+	cnabprovider "get.porter.sh/porter/pkg/cnab/provider"
+	"github.com/cnabio/cnab-go/utils/crud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	s := NewApprovalStore(crud.NewBackingStore(crud.NewMockStore()))
+
+	ids, err := s.List()
+	require.NoError(t, err)
+	require.Empty(t, ids, "List should return no entries")
+
+	r, err := NewActionRequest(User{Name: "alice", ID: "1000"}, cnabprovider.ActionArguments{
+		Action:       "upgrade",
+		Installation: "wordpress",
+		Params:       map[string]string{"color": "blue"},
+	})
+	require.NoError(t, err, "NewActionRequest failed")
+	r.Created = r.Created.UTC().Round(0)
+
+	err = s.Save(r)
+	require.NoError(t, err, "Save failed")
+
+	ids, err = s.List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{r.ID}, ids)
+
+	got, err := s.Read(r.ID)
+	require.NoError(t, err, "Read failed")
+	assert.Equal(t, r, got)
+
+	all, err := s.ReadAll()
+	require.NoError(t, err, "ReadAll failed")
+	assert.Equal(t, []ActionRequest{r}, all)
+
+	_, err = s.Read("missing")
+	assert.Equal(t, ErrNotFound, err)
+}
@@ -0,0 +1,39 @@
+package approvals
+
+import (
+	"os/user"
+
+	"github.com/pkg/errors"
+)
+
+// User is the operating system account of a user that requests an action or
+// decides on a request.
+type User struct {
+	// Name of the account.
+	Name string
+
+	// ID of the account, the uid on Linux and macOS or the SID on Windows.
+	ID string
+}
+
+// UserProvider identifies the user running porter.
+type UserProvider interface {
+	CurrentUser() (User, error)
+}
+
+var _ UserProvider = OSUserProvider{}
+
+// OSUserProvider identifies the user from the account that runs the porter
+// process, so that it cannot be changed by setting an environment variable
+// such as USER. Users that can run porter as another account, for example
+// with sudo, can still act as that account.
+type OSUserProvider struct{}
+
+// CurrentUser looks up the account that runs porter.
+func (OSUserProvider) CurrentUser() (User, error) {
+	u, err := user.Current()
+	if err != nil {
+		return User{}, errors.Wrap(err, "could not determine the current user")
+	}
+	return User{Name: u.Username, ID: u.Uid}, nil
+}
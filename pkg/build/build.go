@@ -30,3 +30,34 @@ var (
 	// INJECT_PORTER_MIXINS_TOKEN can control where mixin instructions will be placed in Dockerfile.
 	INJECT_PORTER_MIXINS_TOKEN = "# PORTER_MIXINS"
 )
+
+const (
+	// NonRootUID is the user id that runs the invocation image when the bundle
+	// is configured to run as a non-root user. It is numeric so that container
+	// runtimes can verify that the image does not run as root.
+	NonRootUID = 65532
+
+	// NonRootUser is the name of the user that runs the invocation image when
+	// the bundle is configured to run as a non-root user.
+	NonRootUser = "nonroot"
+)
+
+// ImageOptions are the options used when building the invocation image.
+type ImageOptions struct {
+	// Secrets to mount while building the invocation image. Each secret must
+	// have a Source file containing its value.
+	Secrets []Secret
+}
+
+// ApplicationImageOptions are the options used when building an application
+// image that is declared in the manifest.
+type ApplicationImageOptions struct {
+	// Image is the reference of the built image, REGISTRY/NAME:TAG.
+	Image string
+
+	// Context is the path to the build context directory.
+	Context string
+
+	// Dockerfile is the path to the Dockerfile, relative to the build context directory.
+	Dockerfile string
+}
@@ -5,10 +5,12 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"path"
 	"path/filepath"
 	"strings"
 
 	"get.porter.sh/porter/pkg/config"
+	portercontext "get.porter.sh/porter/pkg/context"
 	"get.porter.sh/porter/pkg/manifest"
 	"get.porter.sh/porter/pkg/mixin/query"
 	"get.porter.sh/porter/pkg/pkgmgmt"
@@ -21,6 +23,9 @@ type DockerfileGenerator struct {
 	*manifest.Manifest
 	*templates.Templates
 	Mixins pkgmgmt.PackageManager
+
+	// NoCache disables reusing the cached build output of mixins.
+	NoCache bool
 }
 
 func NewDockerfileGenerator(
@@ -79,6 +84,7 @@ func (g *DockerfileGenerator) buildDockerfile() ([]string, error) {
 
 	lines = append(lines, g.buildPorterSection()...)
 	lines = append(lines, g.buildCNABSection()...)
+	lines = append(lines, g.buildUSERSection()...)
 	lines = append(lines, g.buildWORKDIRSection())
 	lines = append(lines, g.buildCMDSection())
 
@@ -147,6 +153,38 @@ func (g *DockerfileGenerator) getBaseDockerfile() ([]string, error) {
 	return lines, nil
 }
 
+// GetBaseImage returns the image that the invocation image is built from,
+// which is the image in the last FROM instruction of the Dockerfile. An empty
+// string is returned when the image is set with a build argument.
+func (g *DockerfileGenerator) GetBaseImage() (string, error) {
+	lines, err := g.getBaseDockerfile()
+	if err != nil {
+		return "", err
+	}
+
+	var baseImage string
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+
+		// Skip flags, such as --platform, to find the image
+		baseImage = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "--") {
+				baseImage = field
+				break
+			}
+		}
+	}
+
+	if strings.Contains(baseImage, "$") {
+		return "", nil
+	}
+	return baseImage, nil
+}
+
 func (g *DockerfileGenerator) buildPorterSection() []string {
 	// The user-provided manifest may be located separate from the build context directory.
 	// Therefore, we only need to add lines if the relative manifest path exists inside of
@@ -165,10 +203,40 @@ func (g *DockerfileGenerator) buildPorterSection() []string {
 }
 
 func (g *DockerfileGenerator) buildCNABSection() []string {
+	// Putting RUN before COPY here as a workaround for https://github.com/moby/moby/issues/37965, back to back COPY statements in the same directory (e.g. /cnab) _may_ result in an error from Docker depending on unpredictable factors
+	lines := []string{`RUN rm -fr $BUNDLE_DIR/.cnab`}
+
+	if !g.Manifest.NonRoot {
+		return append(lines, `COPY .cnab /cnab`)
+	}
+
+	// Create the unprivileged user and give it ownership of the bundle
+	// directory, the directories that the runtime writes outputs to, and its
+	// home directory. Ownership is set before copying .cnab so that the
+	// mixins and runtimes are not duplicated into another image layer.
+	// The root group is given the same permissions as the user so that the
+	// image also works when it is run with an arbitrary user id, which is
+	// always a member of the root group.
+	home := path.Join("/home", NonRootUser)
+	writableDirs := strings.Join([]string{"/cnab", home}, " ")
+	return append(lines,
+		fmt.Sprintf(`RUN mkdir -p %s %s %s && \`, home, config.BundleOutputsDir, portercontext.MixinOutputsDir),
+		fmt.Sprintf(`    (grep -q ":x:%d:" /etc/passwd || echo "%s:x:%d:0:%s:%s:/sbin/nologin" >> /etc/passwd) && \`, NonRootUID, NonRootUser, NonRootUID, NonRootUser, home),
+		fmt.Sprintf(`    chown -R %d:0 %s && chmod -R g=u %s`, NonRootUID, writableDirs, writableDirs),
+		fmt.Sprintf(`COPY --chown=%d:0 .cnab /cnab`, NonRootUID),
+	)
+}
+
+// buildUSERSection switches to the unprivileged user when the bundle is
+// configured to run as a non-root user.
+func (g *DockerfileGenerator) buildUSERSection() []string {
+	if !g.Manifest.NonRoot {
+		return nil
+	}
+
 	return []string{
-		// Putting RUN before COPY here as a workaround for https://github.com/moby/moby/issues/37965, back to back COPY statements in the same directory (e.g. /cnab) _may_ result in an error from Docker depending on unpredictable factors
-		`RUN rm -fr $BUNDLE_DIR/.cnab`,
-		`COPY .cnab /cnab`,
+		fmt.Sprintf(`ENV HOME=%s`, path.Join("/home", NonRootUser)),
+		fmt.Sprintf(`USER %d`, NonRootUID),
 	}
 }
 
@@ -181,22 +249,87 @@ func (g *DockerfileGenerator) buildCMDSection() string {
 }
 
 func (g *DockerfileGenerator) buildMixinsSection() ([]string, error) {
-	q := query.New(g.Context, g.Mixins)
-	q.RequireAllMixinResponses = true
-	q.LogMixinErrors = true
-	results, err := q.Execute("build", query.NewManifestGenerator(g.Manifest))
-	if err != nil {
-		return nil, err
+	inputs := query.NewManifestGenerator(g.Manifest)
+	cache := mixinBuildCache{g.Config}
+
+	mixinNames := inputs.ListMixins()
+	results := make(map[string]string, len(mixinNames))
+	cacheKeys := make(map[string]string, len(mixinNames))
+	var uncached []string
+	for _, mixinName := range mixinNames {
+		key, err := g.getMixinCacheKey(inputs, mixinName)
+		if err != nil {
+			if g.Debug {
+				fmt.Fprintln(g.Err, errors.Wrapf(err, "not caching the build output of the %s mixin", mixinName))
+			}
+		} else {
+			cacheKeys[mixinName] = key
+			if !g.NoCache {
+				if output, ok := cache.Get(mixinName, key); ok {
+					if g.IsVerbose() {
+						fmt.Fprintf(g.Out, "Using cached build output for the %s mixin\n", mixinName)
+					}
+					results[mixinName] = output
+					continue
+				}
+			}
+		}
+		uncached = append(uncached, mixinName)
+	}
+
+	if len(uncached) > 0 {
+		q := query.New(g.Context, g.Mixins)
+		q.RequireAllMixinResponses = true
+		q.LogMixinErrors = true
+		built, err := q.Execute("build", mixinSubset{MixinInputGenerator: inputs, mixins: uncached})
+		if err != nil {
+			return nil, err
+		}
+
+		for mixinName, output := range built {
+			results[mixinName] = output
+			if key, ok := cacheKeys[mixinName]; ok {
+				if err := cache.Put(mixinName, key, output); err != nil && g.Debug {
+					fmt.Fprintln(g.Err, err)
+				}
+			}
+		}
 	}
 
+	// Keep the mixin instructions in the order that the mixins are declared
 	lines := make([]string, 0)
-	for _, result := range results {
-		l := strings.Split(result, "\n")
+	for _, mixinName := range mixinNames {
+		l := strings.Split(results[mixinName], "\n")
 		lines = append(lines, l...)
 	}
 	return lines, nil
 }
 
+// getMixinCacheKey identifies the build output of a mixin by its version and its input.
+func (g *DockerfileGenerator) getMixinCacheKey(inputs query.MixinInputGenerator, mixinName string) (string, error) {
+	meta, err := g.Mixins.GetMetadata(mixinName)
+	if err != nil {
+		return "", err
+	}
+
+	input, err := inputs.BuildInput(mixinName)
+	if err != nil {
+		return "", err
+	}
+
+	return mixinBuildCacheKey(mixinName, meta.GetVersionInfo(), input), nil
+}
+
+// mixinSubset limits a query to the specified mixins.
+type mixinSubset struct {
+	query.MixinInputGenerator
+	mixins []string
+}
+
+func (s mixinSubset) ListMixins() []string {
+	return s.mixins
+}
+
 func (g *DockerfileGenerator) PrepareFilesystem() error {
 	fmt.Fprintf(g.Out, "Copying porter runtime ===> \n")
 
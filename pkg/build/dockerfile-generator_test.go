@@ -5,8 +5,10 @@ import (
 	"testing"
 
 	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/context"
 	"get.porter.sh/porter/pkg/manifest"
 	"get.porter.sh/porter/pkg/mixin"
+	"get.porter.sh/porter/pkg/pkgmgmt"
 	"get.porter.sh/porter/pkg/templates"
 	"github.com/carolynvs/aferox"
 	"github.com/spf13/afero"
@@ -52,6 +54,50 @@ func TestPorter_buildDockerfile(t *testing.T) {
 	assert.Equal(t, wantlines, gotlines)
 }
 
+func TestPorter_buildDockerfile_nonRoot(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	tmpl := templates.NewTemplates()
+	configTpl, err := tmpl.GetManifest()
+	require.Nil(t, err)
+	c.TestContext.AddTestFileContents(configTpl, config.Name)
+
+	m, err := manifest.LoadManifestFrom(c.Context, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	// ignore mixins in the unit tests
+	m.Mixins = []manifest.MixinDeclaration{}
+	m.NonRoot = true
+
+	mp := mixin.NewTestMixinProvider()
+	g := NewDockerfileGenerator(c.Config, m, tmpl, mp)
+	gotlines, err := g.buildDockerfile()
+	require.NoError(t, err)
+
+	wantlines := []string{
+		"FROM debian:stretch-slim",
+		"",
+		"ARG BUNDLE_DIR",
+		"",
+		"RUN apt-get update && apt-get install -y ca-certificates",
+		"",
+		"",
+		"COPY . $BUNDLE_DIR",
+		"RUN rm $BUNDLE_DIR/porter.yaml",
+		"RUN rm -fr $BUNDLE_DIR/.cnab",
+		`RUN mkdir -p /home/nonroot /cnab/app/outputs /cnab/app/porter/outputs && \`,
+		`    (grep -q ":x:65532:" /etc/passwd || echo "nonroot:x:65532:0:nonroot:/home/nonroot:/sbin/nologin" >> /etc/passwd) && \`,
+		`    chown -R 65532:0 /cnab /home/nonroot && chmod -R g=u /cnab /home/nonroot`,
+		"COPY --chown=65532:0 .cnab /cnab",
+		"ENV HOME=/home/nonroot",
+		"USER 65532",
+		"WORKDIR $BUNDLE_DIR",
+		"CMD [\"/cnab/app/run\"]",
+	}
+	assert.Equal(t, wantlines, gotlines)
+}
+
 func TestPorter_buildDockerfile_alternateManifestLocation(t *testing.T) {
 	t.Parallel()
 
@@ -434,3 +480,105 @@ func TestPorter_buildMixinsSection_mixinErr(t *testing.T) {
 	_, err = g.buildMixinsSection()
 	require.EqualError(t, err, "1 error occurred:\n\t* error encountered from mixin \"exec\": encountered build error\n\n")
 }
+
+func TestPorter_buildMixinsSection_cache(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	tmpl := templates.NewTemplates()
+	configTpl, err := tmpl.GetManifest()
+	require.Nil(t, err)
+	c.TestContext.AddTestFileContents(configTpl, config.Name)
+
+	m, err := manifest.LoadManifestFrom(c.Context, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	mp := mixin.NewTestMixinProvider()
+	builds := 0
+	mp.RunAssertions = append(mp.RunAssertions, func(pkgContext *context.Context, name string, commandOpts pkgmgmt.CommandOptions) error {
+		if commandOpts.Command == "build" {
+			builds++
+		}
+		return nil
+	})
+
+	g := NewDockerfileGenerator(c.Config, m, tmpl, mp)
+	wantLines, err := g.buildMixinsSection()
+	require.NoError(t, err)
+	assert.Equal(t, 1, builds, "the mixin should be called when the cache is empty")
+
+	t.Run("cache hit", func(t *testing.T) {
+		c.TestContext.ClearOutputs()
+
+		gotLines, err := g.buildMixinsSection()
+		require.NoError(t, err)
+		assert.Equal(t, wantLines, gotLines, "the cached output should be used")
+		assert.Equal(t, 1, builds, "the mixin should not be called when its output is cached")
+		assert.Contains(t, c.TestContext.GetOutput(), "Using cached build output for the exec mixin")
+	})
+
+	t.Run("no cache", func(t *testing.T) {
+		g.NoCache = true
+		defer func() { g.NoCache = false }()
+
+		_, err := g.buildMixinsSection()
+		require.NoError(t, err)
+		assert.Equal(t, 2, builds, "the mixin should be called when the cache is disabled")
+	})
+
+	t.Run("manifest changed", func(t *testing.T) {
+		m.Install[0].Data["exec"].(map[string]interface{})["description"] = "Install something else"
+
+		_, err := g.buildMixinsSection()
+		require.NoError(t, err)
+		assert.Equal(t, 3, builds, "the mixin should be called when its steps changed")
+	})
+
+	t.Run("mixin upgraded", func(t *testing.T) {
+		mp.Packages[0].(*mixin.Metadata).VersionInfo.Version = "v1.1"
+
+		_, err := g.buildMixinsSection()
+		require.NoError(t, err)
+		assert.Equal(t, 4, builds, "the mixin should be called when it was upgraded")
+	})
+}
+
+func TestDockerfileGenerator_GetBaseImage(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name       string
+		dockerfile string
+		want       string
+	}{
+		{name: "default template", want: "debian:stretch-slim"},
+		{name: "custom", dockerfile: "FROM ubuntu:latest\nARG BUNDLE_DIR\n", want: "ubuntu:latest"},
+		{name: "multi-stage", dockerfile: "FROM golang:1.16 AS builder\nRUN go build\nfrom --platform=linux/amd64 alpine:3.14\nARG BUNDLE_DIR\n", want: "alpine:3.14"},
+		{name: "build argument", dockerfile: "ARG BASE=ubuntu\nFROM $BASE\nARG BUNDLE_DIR\n", want: ""},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := config.NewTestConfig(t)
+			tmpl := templates.NewTemplates()
+			configTpl, err := tmpl.GetManifest()
+			require.NoError(t, err)
+			c.TestContext.AddTestFileContents(configTpl, config.Name)
+
+			m, err := manifest.LoadManifestFrom(c.Context, config.Name)
+			require.NoError(t, err, "could not load manifest")
+			if tc.dockerfile != "" {
+				m.Dockerfile = "Dockerfile.tmpl"
+				c.TestContext.AddTestFileContents([]byte(tc.dockerfile), m.Dockerfile)
+			}
+
+			g := NewDockerfileGenerator(c.Config, m, tmpl, mixin.NewTestMixinProvider())
+			got, err := g.GetBaseImage()
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
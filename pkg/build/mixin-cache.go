@@ -0,0 +1,95 @@
+package build
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"time"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"github.com/pkg/errors"
+)
+
+// mixinCacheMaxAge is how long cached mixin build output is kept after it was last used.
+const mixinCacheMaxAge = 30 * 24 * time.Hour
+
+// mixinBuildCache stores the output of each mixin's build command under
+// PORTER_HOME so that it can be reused by later builds when neither the mixin
+// nor its input from the manifest has changed.
+type mixinBuildCache struct {
+	*config.Config
+}
+
+// mixinBuildCacheKey identifies the build output of a mixin. The input contains
+// the mixin's declared config and the steps in the manifest that use the mixin.
+func mixinBuildCacheKey(mixin string, version pkgmgmt.VersionInfo, input []byte) string {
+	h := sha256.New()
+	for _, part := range []string{mixin, version.Version, version.Commit} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Write(input)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c mixinBuildCache) path(mixin string, key string) (string, error) {
+	dir, err := c.GetMixinBuildCache()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, mixin, key), nil
+}
+
+// Get returns the cached build output for the mixin, and if it was found.
+func (c mixinBuildCache) Get(mixin string, key string) (string, bool) {
+	path, err := c.path(mixin, key)
+	if err != nil {
+		return "", false
+	}
+
+	output, err := c.FileSystem.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	// Remember that the entry was used so that it isn't pruned
+	now := time.Now()
+	c.FileSystem.Chtimes(path, now, now)
+
+	return string(output), true
+}
+
+// Put saves the build output for the mixin, and removes entries for the
+// mixin that haven't been used recently.
+func (c mixinBuildCache) Put(mixin string, key string, output string) error {
+	path, err := c.path(mixin, key)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if err := c.FileSystem.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrapf(err, "could not create the mixin build cache directory %s", dir)
+	}
+
+	if err := c.FileSystem.WriteFile(path, []byte(output), 0644); err != nil {
+		return errors.Wrapf(err, "could not cache the build output of the %s mixin", mixin)
+	}
+
+	c.prune(dir)
+	return nil
+}
+
+func (c mixinBuildCache) prune(dir string) {
+	entries, err := c.FileSystem.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if time.Since(entry.ModTime()) > mixinCacheMaxAge {
+			c.FileSystem.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
@@ -12,7 +13,9 @@ import (
 	"github.com/docker/cli/cli/command"
 	clibuild "github.com/docker/cli/cli/command/image/build"
 	cliflags "github.com/docker/cli/cli/flags"
+	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/term"
@@ -29,8 +32,12 @@ func NewDockerBuilder(cxt *portercontext.Context) *DockerBuilder {
 	}
 }
 
-func (b *DockerBuilder) BuildInvocationImage(manifest *manifest.Manifest) error {
+func (b *DockerBuilder) BuildInvocationImage(manifest *manifest.Manifest, opts build.ImageOptions) error {
 	fmt.Fprintf(b.Out, "\nStarting Invocation Image Build (%s) =======> \n", manifest.Image)
+	if len(opts.Secrets) > 0 {
+		return b.buildWithSecrets(manifest, opts)
+	}
+
 	buildOptions := types.ImageBuildOptions{
 		SuppressOutput: false,
 		PullParent:     false,
@@ -42,22 +49,38 @@ func (b *DockerBuilder) BuildInvocationImage(manifest *manifest.Manifest) error
 		},
 	}
 
-	excludes, err := clibuild.ReadDockerignore(b.Getwd())
+	return b.buildImage(b.Getwd(), buildOptions)
+}
+
+// BuildApplicationImage builds an application image that is declared in the manifest.
+func (b *DockerBuilder) BuildApplicationImage(opts build.ApplicationImageOptions) error {
+	fmt.Fprintf(b.Out, "\nStarting Image Build (%s) =======> \n", opts.Image)
+
+	buildOptions := types.ImageBuildOptions{
+		SuppressOutput: false,
+		PullParent:     false,
+		Remove:         true,
+		Tags:           []string{opts.Image},
+		Dockerfile:     filepath.ToSlash(opts.Dockerfile),
+	}
+	return b.buildImage(opts.Context, buildOptions)
+}
+
+// buildImage builds an image from the build context directory with the docker daemon.
+func (b *DockerBuilder) buildImage(contextDir string, buildOptions types.ImageBuildOptions) error {
+	excludes, err := clibuild.ReadDockerignore(contextDir)
 	if err != nil {
 		return err
 	}
 	excludes = clibuild.TrimBuildFilesFromExcludes(excludes, buildOptions.Dockerfile, false)
 
-	tar, err := archive.TarWithOptions(b.Getwd(), &archive.TarOptions{ExcludePatterns: excludes})
+	tar, err := archive.TarWithOptions(contextDir, &archive.TarOptions{ExcludePatterns: excludes})
 	if err != nil {
 		return err
 	}
 
-	cli, err := command.NewDockerCli()
+	cli, err := b.getDockerClient()
 	if err != nil {
-		return errors.Wrap(err, "could not create new docker client")
-	}
-	if err := cli.Initialize(cliflags.NewClientOptions()); err != nil {
 		return err
 	}
 
@@ -82,12 +105,41 @@ func (b *DockerBuilder) BuildInvocationImage(manifest *manifest.Manifest) error
 	return nil
 }
 
+// buildWithSecrets builds the invocation image with the docker CLI and BuildKit,
+// which is required to mount secrets during the build. Secrets are never
+// persisted in the resulting image.
+func (b *DockerBuilder) buildWithSecrets(manifest *manifest.Manifest, opts build.ImageOptions) error {
+	args := []string{"build",
+		"--file", filepath.ToSlash(build.DOCKER_FILE),
+		"--tag", manifest.Image,
+		"--build-arg", "BUNDLE_DIR=" + build.BUNDLE_DIR,
+		"--progress", "plain",
+	}
+	for _, secret := range opts.Secrets {
+		args = append(args, "--secret", secret.String())
+	}
+	args = append(args, ".")
+
+	cmd := b.NewCommand("docker", args...)
+	cmd.Env = append(b.Environ(), "DOCKER_BUILDKIT=1")
+
+	// Only include the build output in the error when it wasn't already printed
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	if b.IsVerbose() {
+		cmd.Stdout = b.Out
+		cmd.Stderr = b.Err
+	}
+
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "docker build failed\n%s", stderr.String())
+	}
+	return nil
+}
+
 func (b *DockerBuilder) TagInvocationImage(origTag, newTag string) error {
-	cli, err := command.NewDockerCli()
+	cli, err := b.getDockerClient()
 	if err != nil {
-		return errors.Wrap(err, "could not create new docker client")
-	}
-	if err := cli.Initialize(cliflags.NewClientOptions()); err != nil {
 		return err
 	}
 
@@ -96,3 +148,80 @@ func (b *DockerBuilder) TagInvocationImage(origTag, newTag string) error {
 	}
 	return nil
 }
+
+// ResolveImageDigest returns the repository digest of an image in the docker
+// image cache, pulling the image when it is not cached, so that it is the
+// image that a build uses. An empty digest is returned for images that were
+// never pushed to or pulled from a registry.
+func (b *DockerBuilder) ResolveImageDigest(img string) (string, error) {
+	ref, err := reference.ParseNormalizedNamed(img)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid image reference %s", img)
+	}
+	if digested, ok := ref.(reference.Digested); ok {
+		return digested.Digest().String(), nil
+	}
+
+	cli, err := b.getDockerClient()
+	if err != nil {
+		return "", err
+	}
+
+	ctx := context.Background()
+	inspect, _, err := cli.Client().ImageInspectWithRaw(ctx, img)
+	if err != nil {
+		if !client.IsErrNotFound(err) {
+			return "", errors.Wrapf(err, "could not inspect image %s", img)
+		}
+		if err = b.pullImage(ctx, cli, reference.TagNameOnly(ref).String()); err != nil {
+			return "", err
+		}
+		if inspect, _, err = cli.Client().ImageInspectWithRaw(ctx, img); err != nil {
+			return "", errors.Wrapf(err, "could not inspect image %s", img)
+		}
+	}
+
+	for _, repoDigest := range inspect.RepoDigests {
+		digestRef, err := reference.ParseNormalizedNamed(repoDigest)
+		if err != nil {
+			continue
+		}
+		if digested, ok := digestRef.(reference.Digested); ok && digestRef.Name() == ref.Name() {
+			return digested.Digest().String(), nil
+		}
+	}
+	return "", nil
+}
+
+// pullImage pulls an image into the docker image cache.
+func (b *DockerBuilder) pullImage(ctx context.Context, cli *command.DockerCli, img string) error {
+	encodedAuth, err := command.RetrieveAuthTokenFromImage(ctx, cli, img)
+	if err != nil {
+		return errors.Wrapf(err, "could not resolve the registry credentials for image %s", img)
+	}
+
+	response, err := cli.Client().ImagePull(ctx, img, types.ImagePullOptions{RegistryAuth: encodedAuth})
+	if err != nil {
+		return errors.Wrapf(err, "could not pull image %s", img)
+	}
+	defer response.Close()
+
+	dockerOutput := ioutil.Discard
+	if b.IsVerbose() {
+		dockerOutput = b.Out
+	}
+	termFd, _ := term.GetFdInfo(dockerOutput)
+	err = jsonmessage.DisplayJSONMessagesStream(response, dockerOutput, termFd, false, nil)
+	return errors.Wrapf(err, "could not pull image %s", img)
+}
+
+func (b *DockerBuilder) getDockerClient() (*command.DockerCli, error) {
+	cli, err := command.NewDockerCli()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create new docker client")
+	}
+	if err := cli.Initialize(cliflags.NewClientOptions()); err != nil {
+		return nil, err
+	}
+	return cli, nil
+}
@@ -0,0 +1,74 @@
+package build
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Secret is made available to the invocation image build as a BuildKit
+// secret mount, for example RUN --mount=type=secret,id=npmrc. Secrets are
+// only mounted while the RUN instruction executes and are never persisted in
+// the image.
+type Secret struct {
+	// ID of the secret, used to reference it in the Dockerfile template.
+	ID string
+
+	// Source is the path to the file containing the value of the secret.
+	Source string
+
+	// Name of the secret in the secret store, used when the value is resolved
+	// with porter's secrets plugin instead of read from a file.
+	Name string
+}
+
+// ParseSecret parses the value of the --secret flag, in the format
+// id=ID,src=PATH to use a local file or id=ID,secret=NAME to use a secret
+// resolved with porter's secrets plugin.
+func ParseSecret(value string) (Secret, error) {
+	var s Secret
+	for _, field := range strings.Split(value, ",") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return Secret{}, errors.Errorf("invalid secret %q: expected a comma separated list of KEY=VALUE pairs", value)
+		}
+
+		key, fieldValue := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+		switch key {
+		case "id":
+			s.ID = fieldValue
+		case "src", "source":
+			s.Source = fieldValue
+		case "secret":
+			s.Name = fieldValue
+		default:
+			return Secret{}, errors.Errorf("invalid secret %q: unsupported key %q, supported keys are id, src and secret", value, parts[0])
+		}
+	}
+
+	if s.ID == "" {
+		return Secret{}, errors.Errorf("invalid secret %q: id is required", value)
+	}
+	if (s.Source == "") == (s.Name == "") {
+		return Secret{}, errors.Errorf("invalid secret %q: exactly one of src or secret must be specified", value)
+	}
+
+	return s, nil
+}
+
+// String returns the secret in the format used by docker build --secret.
+func (s Secret) String() string {
+	return fmt.Sprintf("id=%s,src=%s", s.ID, s.Source)
+}
+
+// sensitiveArgExpr matches the names of build arguments that are likely to
+// hold a secret value.
+var sensitiveArgExpr = regexp.MustCompile(`(?i)(token|passw(or)?d|secret|api_?key|access_?key|private_?key|credential)`)
+
+// IsSensitiveBuildArg determines if the name of a build argument suggests that
+// it is used to pass a secret into the image build.
+func IsSensitiveBuildArg(name string) bool {
+	return sensitiveArgExpr.MatchString(name)
+}
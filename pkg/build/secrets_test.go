@@ -0,0 +1,52 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSecret(t *testing.T) {
+	testcases := []struct {
+		value     string
+		want      Secret
+		wantError string
+	}{
+		{value: "id=npmrc,src=/home/me/.npmrc", want: Secret{ID: "npmrc", Source: "/home/me/.npmrc"}},
+		{value: "id=npmrc,source=.npmrc", want: Secret{ID: "npmrc", Source: ".npmrc"}},
+		{value: "id=token,secret=github-token", want: Secret{ID: "token", Name: "github-token"}},
+		{value: "src=.npmrc", wantError: "id is required"},
+		{value: "id=npmrc", wantError: "exactly one of src or secret must be specified"},
+		{value: "id=npmrc,src=.npmrc,secret=npmrc", wantError: "exactly one of src or secret must be specified"},
+		{value: "id=npmrc,env=NPM_TOKEN", wantError: `unsupported key "env"`},
+		{value: "npmrc", wantError: "expected a comma separated list of KEY=VALUE pairs"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := ParseSecret(tc.value)
+			if tc.wantError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSecret_String(t *testing.T) {
+	s := Secret{ID: "npmrc", Source: "/home/me/.npmrc"}
+	assert.Equal(t, "id=npmrc,src=/home/me/.npmrc", s.String())
+}
+
+func TestIsSensitiveBuildArg(t *testing.T) {
+	assert.True(t, IsSensitiveBuildArg("NPM_TOKEN"))
+	assert.True(t, IsSensitiveBuildArg("db_password"))
+	assert.True(t, IsSensitiveBuildArg("AWS_SECRET_ACCESS_KEY"))
+	assert.True(t, IsSensitiveBuildArg("APIKEY"))
+	assert.False(t, IsSensitiveBuildArg("BUNDLE_DIR"))
+	assert.False(t, IsSensitiveBuildArg("NODE_VERSION"))
+}
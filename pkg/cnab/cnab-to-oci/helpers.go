@@ -11,6 +11,8 @@ type TestRegistry struct {
 	MockPullBundle          func(tag string, insecureRegistry bool) (bun bundle.Bundle, reloMap *relocation.ImageRelocationMap, err error)
 	MockPushBundle          func(bun bundle.Bundle, tag string, insecureRegistry bool) (reloMap *relocation.ImageRelocationMap, err error)
 	MockPushInvocationImage func(invocationImage string) (imageDigest string, err error)
+	MockPushImage           func(image string) (imageDigest string, err error)
+	MockRebaseImage         func(opts RebaseImageOptions) (RebasedImage, error)
 }
 
 func NewTestRegistry() *TestRegistry {
@@ -39,3 +41,17 @@ func (t TestRegistry) PushInvocationImage(invocationImage string) (string, error
 	}
 	return "", nil
 }
+
+func (t TestRegistry) PushImage(image string) (string, error) {
+	if t.MockPushImage != nil {
+		return t.MockPushImage(image)
+	}
+	return "", nil
+}
+
+func (t TestRegistry) RebaseImage(opts RebaseImageOptions) (RebasedImage, error) {
+	if t.MockRebaseImage != nil {
+		return t.MockRebaseImage(opts)
+	}
+	return RebasedImage{}, nil
+}
@@ -17,4 +17,12 @@ type RegistryProvider interface {
 	// the expected format of the invocationImage is REGISTRY/NAME:TAG.
 	// Returns the image digest from the registry.
 	PushInvocationImage(invocationImage string) (string, error)
+
+	// PushImage pushes an image from the Docker image cache to the specified location
+	// the expected format of the image is REGISTRY/NAME:TAG.
+	// Returns the image digest from the registry.
+	PushImage(image string) (string, error)
+
+	// RebaseImage replaces the base of an image and pushes the result.
+	RebaseImage(opts RebaseImageOptions) (RebasedImage, error)
 }
@@ -0,0 +1,133 @@
+package cnabtooci
+
+import (
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pivotal/image-relocation/pkg/image"
+	"github.com/pivotal/image-relocation/pkg/registry/ggcr"
+	"github.com/pkg/errors"
+)
+
+// RebaseImageOptions are the options for replacing the base of an image.
+type RebaseImageOptions struct {
+	// Image to rebase.
+	Image string
+
+	// OldBase is the image that Image was built from.
+	OldBase string
+
+	// NewBase is the image that replaces OldBase.
+	NewBase string
+
+	// Destination is where the rebased image is pushed.
+	Destination string
+
+	// LayoutDir is an OCI layout that contains Image. When it is not set,
+	// Image is read from its registry.
+	LayoutDir string
+
+	// InsecureRegistry allows connecting to registries without TLS.
+	InsecureRegistry bool
+}
+
+// RebasedImage is the result of rebasing an image.
+type RebasedImage struct {
+	// Digest of the rebased image.
+	Digest string
+
+	// NewBaseDigest is the digest of the new base image, so that the
+	// rebased image can be rebased again after the new base's tag moves.
+	NewBaseDigest string
+}
+
+// RebaseImage replaces the layers of the old base image at the bottom of an
+// image with the layers of the new base image, keeping the layers that were
+// added on top of the old base, and pushes the result to the destination.
+func (r *Registry) RebaseImage(opts RebaseImageOptions) (RebasedImage, error) {
+	var nameOpts []name.Option
+	if opts.InsecureRegistry {
+		nameOpts = append(nameOpts, name.Insecure)
+	}
+
+	orig, err := r.readImage(opts.Image, opts.LayoutDir, nameOpts)
+	if err != nil {
+		return RebasedImage{}, err
+	}
+	oldBase, err := r.readImage(opts.OldBase, "", nameOpts)
+	if err != nil {
+		return RebasedImage{}, err
+	}
+	newBase, err := r.readImage(opts.NewBase, "", nameOpts)
+	if err != nil {
+		return RebasedImage{}, err
+	}
+
+	rebased, err := mutate.Rebase(orig, oldBase, newBase)
+	if err != nil {
+		return RebasedImage{}, errors.Wrapf(err, "could not rebase %s from %s onto %s", opts.Image, opts.OldBase, opts.NewBase)
+	}
+
+	dest, err := name.ParseReference(opts.Destination, nameOpts...)
+	if err != nil {
+		return RebasedImage{}, errors.Wrapf(err, "invalid destination image %s", opts.Destination)
+	}
+	if err = remote.Write(dest, rebased, remote.WithAuthFromKeychain(authn.DefaultKeychain)); err != nil {
+		return RebasedImage{}, errors.Wrapf(err, "could not push the rebased image to %s", opts.Destination)
+	}
+
+	digest, err := rebased.Digest()
+	if err != nil {
+		return RebasedImage{}, errors.Wrap(err, "could not calculate the digest of the rebased image")
+	}
+	newBaseDigest, err := newBase.Digest()
+	if err != nil {
+		return RebasedImage{}, errors.Wrapf(err, "could not calculate the digest of %s", opts.NewBase)
+	}
+	return RebasedImage{Digest: digest.String(), NewBaseDigest: newBaseDigest.String()}, nil
+}
+
+// readImage reads an image from an OCI layout, when layoutDir is set, or
+// otherwise from its registry.
+func (r *Registry) readImage(img string, layoutDir string, nameOpts []name.Option) (v1.Image, error) {
+	if layoutDir != "" {
+		return readLayoutImage(img, layoutDir)
+	}
+
+	ref, err := name.ParseReference(img, nameOpts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid image reference %s", img)
+	}
+	result, err := remote.Image(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	return result, errors.Wrapf(err, "could not pull image %s", img)
+}
+
+func readLayoutImage(img string, layoutDir string) (v1.Image, error) {
+	imgName, err := image.NewName(img)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid image reference %s", img)
+	}
+
+	l, err := ggcr.NewRegistryClient().ReadLayout(layoutDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the OCI layout %s", layoutDir)
+	}
+	digest, err := l.Find(imgName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find image %s in the OCI layout %s", img, layoutDir)
+	}
+
+	hash, err := v1.NewHash(digest.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid digest %s for image %s", digest, img)
+	}
+	lp, err := layout.FromPath(layoutDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the OCI layout %s", layoutDir)
+	}
+	result, err := lp.Image(hash)
+	return result, errors.Wrapf(err, "could not read image %s from the OCI layout %s", img, layoutDir)
+}
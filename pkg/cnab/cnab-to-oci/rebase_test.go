@@ -0,0 +1,123 @@
+package cnabtooci
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	portercontext "get.porter.sh/porter/pkg/context"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_RebaseImage(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(ioutil.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+
+	push := func(img v1.Image, repo string) string {
+		ref := fmt.Sprintf("%s/%s", u.Host, repo)
+		tag, err := name.NewTag(ref, name.Insecure)
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(tag, img))
+		return ref
+	}
+
+	oldBase, err := random.Image(64, 2)
+	require.NoError(t, err)
+	newBase, err := random.Image(64, 3)
+	require.NoError(t, err)
+	porterLayer, err := random.Layer(64, types.DockerLayer)
+	require.NoError(t, err)
+	orig, err := mutate.AppendLayers(oldBase, porterLayer)
+	require.NoError(t, err)
+
+	r := NewRegistry(portercontext.NewTestContext(t).Context)
+	opts := RebaseImageOptions{
+		Image:            push(orig, "mybuns-installer:v0.1.0"),
+		OldBase:          push(oldBase, "base:old"),
+		NewBase:          push(newBase, "base:new"),
+		Destination:      fmt.Sprintf("%s/mybuns-installer:v0.1.0-patch1", u.Host),
+		InsecureRegistry: true,
+	}
+
+	t.Run("rebase", func(t *testing.T) {
+		result, err := r.RebaseImage(opts)
+		require.NoError(t, err)
+
+		dest, err := name.ParseReference(opts.Destination, name.Insecure)
+		require.NoError(t, err)
+		rebased, err := remote.Image(dest)
+		require.NoError(t, err)
+
+		gotDigest, err := rebased.Digest()
+		require.NoError(t, err)
+		assert.Equal(t, gotDigest.String(), result.Digest)
+		assert.Equal(t, mustImageDigest(t, newBase), result.NewBaseDigest)
+
+		layers, err := rebased.Layers()
+		require.NoError(t, err)
+		require.Len(t, layers, 4, "the rebased image should have the 3 new base layers and the porter layer")
+		newBaseLayers, err := newBase.Layers()
+		require.NoError(t, err)
+		for i, l := range newBaseLayers {
+			assert.Equal(t, mustDigest(t, l), mustDigest(t, layers[i]), "layer %d should be from the new base", i)
+		}
+		assert.Equal(t, mustDigest(t, porterLayer), mustDigest(t, layers[3]), "the porter layer should be kept on top")
+	})
+
+	t.Run("from an OCI layout", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "porter")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		lp, err := layout.Write(dir, empty.Index)
+		require.NoError(t, err)
+		archivedImage := "example.com/mybuns-installer:v0.1.0"
+		err = lp.AppendImage(orig, layout.WithAnnotations(map[string]string{
+			"org.opencontainers.image.ref.name": archivedImage,
+		}))
+		require.NoError(t, err)
+
+		fromLayout := opts
+		fromLayout.Image = archivedImage
+		fromLayout.LayoutDir = dir
+		fromLayout.Destination = fmt.Sprintf("%s/mybuns-installer:v0.1.0-patch2", u.Host)
+		_, err = r.RebaseImage(fromLayout)
+		require.NoError(t, err)
+	})
+
+	t.Run("not based on the old base", func(t *testing.T) {
+		wrongBase := opts
+		wrongBase.OldBase = wrongBase.NewBase
+		_, err := r.RebaseImage(wrongBase)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not rebase")
+	})
+}
+
+func mustDigest(t *testing.T, l v1.Layer) string {
+	d, err := l.Digest()
+	require.NoError(t, err)
+	return d.String()
+}
+
+func mustImageDigest(t *testing.T, img v1.Image) string {
+	d, err := img.Digest()
+	require.NoError(t, err)
+	return d.String()
+}
@@ -118,6 +118,19 @@ func (r *Registry) PushBundle(bun bundle.Bundle, tag string, insecureRegistry bo
 // the expected format of the invocationImage is REGISTRY/NAME:TAG.
 // Returns the image digest from the registry.
 func (r *Registry) PushInvocationImage(invocationImage string) (string, error) {
+	fmt.Fprintln(r.Out, "Pushing CNAB invocation image...")
+	return r.pushImage(invocationImage)
+}
+
+// PushImage pushes an image from the Docker image cache to the specified location,
+// the expected format of the image is REGISTRY/NAME:TAG.
+// Returns the image digest from the registry.
+func (r *Registry) PushImage(image string) (string, error) {
+	fmt.Fprintf(r.Out, "Pushing image %s...\n", image)
+	return r.pushImage(image)
+}
+
+func (r *Registry) pushImage(image string) (string, error) {
 	cli, err := r.getDockerClient()
 	if err != nil {
 		return "", err
@@ -125,7 +138,7 @@ func (r *Registry) PushInvocationImage(invocationImage string) (string, error) {
 
 	ctx := context.Background()
 
-	ref, err := ParseOCIReference(invocationImage)
+	ref, err := ParseOCIReference(image)
 	if err != nil {
 		return "", err
 	}
@@ -144,8 +157,7 @@ func (r *Registry) PushInvocationImage(invocationImage string) (string, error) {
 		RegistryAuth: encodedAuth,
 	}
 
-	fmt.Fprintln(r.Out, "Pushing CNAB invocation image...")
-	pushResponse, err := cli.Client().ImagePush(ctx, invocationImage, options)
+	pushResponse, err := cli.Client().ImagePush(ctx, image, options)
 	if err != nil {
 		return "", errors.Wrap(err, "docker push failed")
 	}
@@ -162,7 +174,7 @@ func (r *Registry) PushInvocationImage(invocationImage string) (string, error) {
 		}
 		return "", errors.Wrap(err, "failed to stream docker push stdout")
 	}
-	dist, err := cli.Client().DistributionInspect(ctx, invocationImage, encodedAuth)
+	dist, err := cli.Client().DistributionInspect(ctx, image, encodedAuth)
 	if err != nil {
 		return "", errors.Wrap(err, "unable to inspect docker image")
 	}
@@ -10,6 +10,7 @@ import (
 	"get.porter.sh/porter/pkg/manifest"
 	"get.porter.sh/porter/pkg/mixin"
 	"get.porter.sh/porter/pkg/parameters"
+	"get.porter.sh/porter/pkg/runtime/resources"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-go/bundle/definition"
 )
@@ -22,6 +23,9 @@ type ManifestConverter struct {
 	Manifest     *manifest.Manifest
 	ImageDigests map[string]string
 	Mixins       []mixin.Metadata
+
+	// BuildRecord is optional metadata about the build that is saved in the stamp.
+	BuildRecord *BuildRecord
 }
 
 func NewManifestConverter(
@@ -49,6 +53,9 @@ func (c *ManifestConverter) ToBundle() (bundle.Bundle, error) {
 		Name:          c.Manifest.Name,
 		Description:   c.Manifest.Description,
 		Version:       c.Manifest.Version,
+		Maintainers:   c.generateBundleMaintainers(),
+		License:       c.Manifest.License,
+		Keywords:      c.Manifest.Keywords,
 		Custom:        make(map[string]interface{}, 1),
 	}
 	image := bundle.InvocationImage{
@@ -64,7 +71,7 @@ func (c *ManifestConverter) ToBundle() (bundle.Bundle, error) {
 	b.InvocationImages = []bundle.InvocationImage{image}
 	b.Parameters = c.generateBundleParameters(&b.Definitions)
 	b.Outputs = c.generateBundleOutputs(&b.Definitions)
-	b.Credentials = c.generateBundleCredentials()
+	b.Credentials = c.generateBundleCredentials(&b.Definitions)
 	b.Images = c.generateBundleImages()
 	b.Custom = c.generateCustomExtensions(&b)
 	b.RequiredExtensions = c.generateRequiredExtensions(b)
@@ -74,6 +81,22 @@ func (c *ManifestConverter) ToBundle() (bundle.Bundle, error) {
 	return b, nil
 }
 
+func (c *ManifestConverter) generateBundleMaintainers() []bundle.Maintainer {
+	if len(c.Manifest.Maintainers) == 0 {
+		return nil
+	}
+
+	maintainers := make([]bundle.Maintainer, 0, len(c.Manifest.Maintainers))
+	for _, m := range c.Manifest.Maintainers {
+		maintainers = append(maintainers, bundle.Maintainer{
+			Name:  m.Name,
+			Email: m.Email,
+			URL:   m.URL,
+		})
+	}
+	return maintainers
+}
+
 func (c *ManifestConverter) generateCustomActionDefinitions() map[string]bundle.Action {
 	if len(c.Manifest.CustomActions) == 0 {
 		return nil
@@ -222,6 +245,10 @@ func (c *ManifestConverter) generateBundleOutputs(defs *definition.Definitions)
 				output.Schema.WriteOnly = toBool(true)
 			}
 
+			if output.Name == resources.OutputName && output.Path == "" {
+				c.defaultResourcesOutput(&output)
+			}
+
 			if output.Type == nil {
 				// Default to a file type if the param is stored in a file
 				if output.Path != "" {
@@ -241,6 +268,20 @@ func (c *ManifestConverter) generateBundleOutputs(defs *definition.Definitions)
 	return outputs
 }
 
+// defaultResourcesOutput defaults the resources output to an empty value, so
+// that runs where no step reports resources still record the output. An empty
+// value is different from an empty list, which a step reports when the
+// installation no longer owns any resources, so that a run that does not
+// report resources leaves the inventory of the installation unchanged.
+func (c *ManifestConverter) defaultResourcesOutput(output *manifest.OutputDefinition) {
+	if output.Type == nil {
+		output.Type = "string"
+	}
+	if output.Default == nil {
+		output.Default = ""
+	}
+}
+
 func (c *ManifestConverter) addDefinition(name string, kind string, def definition.Schema, defs *definition.Definitions) string {
 	defName := name + "-" + kind
 
@@ -273,7 +314,7 @@ func (c *ManifestConverter) buildDefaultPorterParameters() []manifest.ParameterD
 	}
 }
 
-func (c *ManifestConverter) generateBundleCredentials() map[string]bundle.Credential {
+func (c *ManifestConverter) generateBundleCredentials(defs *definition.Definitions) map[string]bundle.Credential {
 	params := map[string]bundle.Credential{}
 	for _, cred := range c.Manifest.Credentials {
 		l := bundle.Credential{
@@ -286,6 +327,42 @@ func (c *ManifestConverter) generateBundleCredentials() map[string]bundle.Creden
 			ApplyTo: cred.ApplyTo,
 		}
 		params[cred.Name] = l
+
+		if cred.Schema != nil {
+			c.addDefinition(cred.Name, "credential", *cred.Schema, defs)
+		}
+	}
+	return params
+}
+
+func (c *ManifestConverter) generateCredentialTypes() extensions.CredentialTypeDefinitions {
+	types := extensions.CredentialTypeDefinitions{}
+	for _, cred := range c.Manifest.Credentials {
+		if cred.Type == "" && cred.Schema == nil {
+			continue
+		}
+
+		ct := extensions.CredentialType{Type: cred.Type}
+		if cred.Schema != nil {
+			ct.Definition = cred.Name + "-credential"
+		}
+		types[cred.Name] = ct
+	}
+	return types
+}
+
+func (c *ManifestConverter) generatePorterParameters() extensions.PorterParameters {
+	params := extensions.PorterParameters{}
+	for _, param := range c.Manifest.Parameters {
+		deprecated := param.GetDeprecation()
+		if deprecated == "" && len(param.Aliases) == 0 {
+			continue
+		}
+
+		params[param.Name] = extensions.PorterParameter{
+			Deprecated: deprecated,
+			Aliases:    param.Aliases,
+		}
 	}
 	return params
 }
@@ -336,8 +413,11 @@ func (c *ManifestConverter) generateDependencies() *extensions.Dependencies {
 
 	for _, dep := range c.Manifest.Dependencies {
 		dependencyRef := extensions.Dependency{
-			Name:   dep.Name,
-			Bundle: dep.Reference,
+			Name:      dep.Name,
+			Bundle:    dep.Reference,
+			Path:      dep.Path,
+			Optional:  dep.Optional,
+			Condition: dep.Condition,
 		}
 		if len(dep.Versions) > 0 || dep.AllowPrereleases {
 			dependencyRef.Version = &extensions.DependencyVersion{
@@ -515,6 +595,24 @@ func (c *ManifestConverter) generateCustomExtensions(b *bundle.Bundle) map[strin
 		customExtensions[extensions.ParameterSourcesExtensionKey] = ps
 	}
 
+	// Add the credential types extension
+	if types := c.generateCredentialTypes(); len(types) > 0 {
+		customExtensions[extensions.CredentialTypesExtensionKey] = types
+	}
+
+	// Add the parameters extension
+	if params := c.generatePorterParameters(); len(params) > 0 {
+		customExtensions[extensions.ParametersExtensionKey] = params
+	}
+
+	// Add the links extension
+	if links := c.Manifest.Links; links != nil && (links.Source != "" || links.Documentation != "") {
+		customExtensions[extensions.LinksExtensionKey] = extensions.Links{
+			Source:        links.Source,
+			Documentation: links.Documentation,
+		}
+	}
+
 	// Add entries for user-specified required extensions, like docker
 	for _, ext := range c.Manifest.Required {
 		customExtensions[lookupExtensionKey(ext.Name)] = ext.Config
@@ -77,6 +77,56 @@ func TestManifestConverter_generateBundleCredentials(t *testing.T) {
 	assert.Equal(t, "/tmp/password", password.Path, "credential.Path was not populated")
 }
 
+func TestManifestConverter_generateCredentialTypes(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	c.TestContext.AddTestFile("testdata/porter-with-typed-credentials.yaml", config.Name)
+
+	m, err := manifest.LoadManifestFrom(c.Context, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	a := NewManifestConverter(c.Context, m, nil, nil)
+
+	bun, err := a.ToBundle()
+	require.NoError(t, err, "ToBundle failed")
+
+	types, err := extensions.ReadCredentialTypes(bun)
+	require.NoError(t, err, "ReadCredentialTypes failed")
+	assert.Equal(t, extensions.CredentialTypeDefinitions{
+		"kubeconfig": {Type: extensions.CredentialTypeKubeconfig},
+		"azure":      {Type: extensions.CredentialTypeJSON, Definition: "azure-credential"},
+	}, types)
+	assert.NotContains(t, bun.RequiredExtensions, extensions.CredentialTypesExtensionKey, "credential types are validated on the host and should not be required")
+
+	require.Contains(t, bun.Definitions, "azure-credential")
+	assert.Equal(t, []string{"clientId", "clientSecret"}, bun.Definitions["azure-credential"].Required)
+}
+
+func TestManifestConverter_generatePorterParameters(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	c.TestContext.AddTestFile("testdata/porter-with-deprecated-parameters.yaml", config.Name)
+
+	m, err := manifest.LoadManifestFrom(c.Context, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	a := NewManifestConverter(c.Context, m, nil, nil)
+
+	bun, err := a.ToBundle()
+	require.NoError(t, err, "ToBundle failed")
+
+	params, err := extensions.ReadPorterParameters(bun)
+	require.NoError(t, err, "ReadPorterParameters failed")
+	assert.Equal(t, extensions.PorterParameters{
+		"region":      {Aliases: []string{"location"}},
+		"tshirt-size": {Deprecated: "use node-count instead"},
+		"debug":       {Deprecated: "it will be removed in a future version of the bundle"},
+	}, params)
+	assert.NotContains(t, bun.RequiredExtensions, extensions.ParametersExtensionKey, "the parameters extension is informational and should not be required")
+}
+
 func TestManifestConverter_generateBundleParametersSchema(t *testing.T) {
 	testcases := []struct {
 		propname  string
@@ -475,6 +525,35 @@ func TestManifestConverter_generateBundleOutputs(t *testing.T) {
 	require.Equal(t, wantDefinitions, defs)
 }
 
+func TestManifestConverter_generateBundleOutputs_Resources(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	c.TestContext.AddTestFile("../../manifest/testdata/simple.porter.yaml", config.Name)
+
+	m, err := manifest.LoadManifestFrom(c.Context, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	a := NewManifestConverter(c.Context, m, nil, nil)
+	a.Manifest.Outputs = manifest.OutputDefinitions{
+		"resources": {
+			Name: "resources",
+		},
+	}
+
+	defs := make(definition.Definitions, len(a.Manifest.Outputs))
+	outputs := a.generateBundleOutputs(&defs)
+
+	require.Contains(t, outputs, "resources")
+	wantDefinitions := definition.Definitions{
+		"resources-output": &definition.Schema{
+			Type:    "string",
+			Default: "",
+		},
+	}
+	require.Equal(t, wantDefinitions, defs, "the resources output should default to an empty value")
+}
+
 func TestManifestConverter_generateDependencies(t *testing.T) {
 	t.Parallel()
 
@@ -540,6 +619,26 @@ func TestManifestConverter_generateDependencies(t *testing.T) {
 	}
 }
 
+func TestManifestConverter_generateDependencies_Optional(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	c.TestContext.AddTestFile("testdata/porter-with-deps.yaml", config.Name)
+
+	m, err := manifest.LoadManifestFrom(c.Context, config.Name)
+	require.NoError(t, err, "could not load manifest")
+	m.Dependencies[0].Optional = true
+	m.Dependencies[0].Condition = "bundle.parameters.mysql-enabled"
+
+	a := NewManifestConverter(c.Context, m, nil, nil)
+
+	deps := a.generateDependencies()
+	mysql := deps.Requires["mysql"]
+	assert.True(t, mysql.Optional, "optional was not copied to the dependencies extension")
+	assert.Equal(t, "bundle.parameters.mysql-enabled", mysql.Condition, "condition was not copied to the dependencies extension")
+	assert.False(t, deps.Requires["ad"].Optional, "dependencies should not be optional by default")
+}
+
 func TestManifestConverter_generateRequiredExtensions_Dependencies(t *testing.T) {
 	t.Parallel()
 
@@ -815,3 +914,32 @@ func TestManifestConverter_generateCustomMetadata(t *testing.T) {
 
 	assert.Contains(t, string(bundleData), expectedCustomMetaData, "Created bundle should be equal to expected bundle ")
 }
+
+func TestManifestConverter_generateBundleMetadata(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewTestConfig(t)
+	c.TestContext.AddTestFile("./testdata/porter-with-metadata.yaml", config.Name)
+
+	m, err := manifest.LoadManifestFrom(c.Context, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	a := NewManifestConverter(c.Context, m, nil, nil)
+
+	bun, err := a.ToBundle()
+	require.NoError(t, err, "ToBundle failed")
+
+	assert.Equal(t, "Apache-2.0", bun.License)
+	assert.Equal(t, []string{"hello", "demo"}, bun.Keywords)
+	assert.Equal(t, []bundle.Maintainer{
+		{Name: "Jane Doe", Email: "jane@example.com", URL: "https://example.com/jane"},
+		{Name: "John Doe"},
+	}, bun.Maintainers)
+
+	require.Contains(t, bun.Custom, extensions.LinksExtensionKey)
+	links, err := extensions.ReadLinks(bun)
+	require.NoError(t, err, "ReadLinks failed")
+	assert.Equal(t, "https://github.com/getporter/hello", links.Source)
+	assert.Equal(t, "https://porter.sh/hello", links.Documentation)
+	assert.NotContains(t, bun.RequiredExtensions, extensions.LinksExtensionKey, "links are informational and should not be required")
+}
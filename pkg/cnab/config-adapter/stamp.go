@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"get.porter.sh/porter/pkg"
 	"get.porter.sh/porter/pkg/config"
@@ -34,6 +35,30 @@ type Stamp struct {
 	// Version and commit define the version of the Porter used when a bundle was built.
 	Version string `json:"version"`
 	Commit  string `json:"commit"`
+
+	// Build describes when and from which source the bundle was built.
+	Build *BuildRecord `json:"build,omitempty"`
+}
+
+// BuildRecord describes when and from which source a bundle was built.
+type BuildRecord struct {
+	// Time when the bundle was built.
+	Time time.Time `json:"time"`
+
+	// VersionOverride is the bundle version that was used in place of the
+	// version in the manifest, either specified with porter build --version
+	// or derived from git with version: auto.
+	VersionOverride string `json:"versionOverride,omitempty"`
+
+	// Commit of the git repository containing the bundle.
+	Commit string `json:"commit,omitempty"`
+
+	// Dirty indicates that the bundle directory had uncommitted changes.
+	Dirty bool `json:"dirty,omitempty"`
+
+	// BaseImage is the image that the invocation image was built from, which
+	// is replaced when the bundle is rebased.
+	BaseImage string `json:"baseImage,omitempty"`
 }
 
 // DecodeManifest base64 decodes the manifest stored in the stamp
@@ -92,6 +117,7 @@ func (c *ManifestConverter) GenerateStamp() (Stamp, error) {
 
 	stamp.Version = pkg.Version
 	stamp.Commit = pkg.Commit
+	stamp.Build = c.BuildRecord
 
 	return stamp, nil
 }
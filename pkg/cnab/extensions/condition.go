@@ -0,0 +1,344 @@
+package extensions
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// conditionParameterPrefix is the prefix used to reference a parameter of the
+// parent bundle in a dependency condition.
+const conditionParameterPrefix = "bundle.parameters."
+
+// DependencyCondition is a parsed dependency condition, such as
+// "bundle.parameters.mysql-enabled" or "bundle.parameters.db == 'mysql'".
+//
+// A condition is made up of comparisons joined with && and ||, which may be
+// negated with ! and grouped with parentheses. A parameter reference on its
+// own is true unless it is empty, false or 0.
+type DependencyCondition struct {
+	root conditionNode
+}
+
+// ParseDependencyCondition parses a dependency condition expression.
+func ParseDependencyCondition(expression string) (*DependencyCondition, error) {
+	tokens, err := tokenizeCondition(expression)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid condition %q", expression)
+	}
+	if len(tokens) == 0 {
+		return nil, errors.Errorf("invalid condition %q, the condition is empty", expression)
+	}
+
+	p := &conditionParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid condition %q", expression)
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, errors.Errorf("invalid condition %q, unexpected %q", expression, tok.value)
+	}
+
+	return &DependencyCondition{root: root}, nil
+}
+
+// Parameters returns the names of the parameters referenced by the condition.
+func (c *DependencyCondition) Parameters() []string {
+	var params []string
+	c.root.visit(func(o conditionOperand) {
+		if o.parameter != "" {
+			params = append(params, o.parameter)
+		}
+	})
+	return params
+}
+
+// Evaluate the condition using the specified parameter values.
+func (c *DependencyCondition) Evaluate(params map[string]string) bool {
+	return c.root.eval(params)
+}
+
+type conditionNode interface {
+	eval(params map[string]string) bool
+	visit(fn func(o conditionOperand))
+}
+
+type conditionAnd struct{ left, right conditionNode }
+
+func (n conditionAnd) eval(params map[string]string) bool {
+	return n.left.eval(params) && n.right.eval(params)
+}
+
+func (n conditionAnd) visit(fn func(o conditionOperand)) {
+	n.left.visit(fn)
+	n.right.visit(fn)
+}
+
+type conditionOr struct{ left, right conditionNode }
+
+func (n conditionOr) eval(params map[string]string) bool {
+	return n.left.eval(params) || n.right.eval(params)
+}
+
+func (n conditionOr) visit(fn func(o conditionOperand)) {
+	n.left.visit(fn)
+	n.right.visit(fn)
+}
+
+type conditionNot struct{ node conditionNode }
+
+func (n conditionNot) eval(params map[string]string) bool {
+	return !n.node.eval(params)
+}
+
+func (n conditionNot) visit(fn func(o conditionOperand)) {
+	n.node.visit(fn)
+}
+
+type conditionCompare struct {
+	left, right conditionOperand
+	equal       bool
+}
+
+func (n conditionCompare) eval(params map[string]string) bool {
+	left := n.left.value(params)
+	right := n.right.value(params)
+
+	// Compare booleans and numbers by value so that True == true and 1.0 == 1
+	same := left == right
+	if lb, err := strconv.ParseBool(left); err == nil {
+		if rb, err := strconv.ParseBool(right); err == nil {
+			same = lb == rb
+		}
+	}
+	if lf, err := strconv.ParseFloat(left, 64); err == nil {
+		if rf, err := strconv.ParseFloat(right, 64); err == nil {
+			same = lf == rf
+		}
+	}
+
+	return same == n.equal
+}
+
+func (n conditionCompare) visit(fn func(o conditionOperand)) {
+	fn(n.left)
+	fn(n.right)
+}
+
+// conditionOperand is either a reference to a parameter or a literal value.
+type conditionOperand struct {
+	parameter string
+	literal   string
+}
+
+func (o conditionOperand) value(params map[string]string) string {
+	if o.parameter != "" {
+		return params[o.parameter]
+	}
+	return o.literal
+}
+
+func (o conditionOperand) eval(params map[string]string) bool {
+	switch strings.ToLower(strings.TrimSpace(o.value(params))) {
+	case "", "false", "0":
+		return false
+	default:
+		return true
+	}
+}
+
+func (o conditionOperand) visit(fn func(o conditionOperand)) {
+	fn(o)
+}
+
+type conditionTokenKind int
+
+const (
+	conditionWord conditionTokenKind = iota
+	conditionString
+	conditionSymbol
+)
+
+type conditionToken struct {
+	kind  conditionTokenKind
+	value string
+}
+
+func tokenizeCondition(expression string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, conditionToken{conditionSymbol, string(r)})
+			i++
+		case r == '!' || r == '=' || r == '&' || r == '|':
+			if i+1 < len(runes) {
+				op := string(runes[i : i+2])
+				switch op {
+				case "==", "!=", "&&", "||":
+					tokens = append(tokens, conditionToken{conditionSymbol, op})
+					i += 2
+					continue
+				}
+			}
+			if r != '!' {
+				return nil, errors.Errorf("unexpected %q", string(r))
+			}
+			tokens = append(tokens, conditionToken{conditionSymbol, "!"})
+			i++
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, conditionToken{conditionString, string(runes[i+1 : end])})
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && isConditionWordRune(runes[end]) {
+				end++
+			}
+			if end == i {
+				return nil, errors.Errorf("unexpected %q", string(r))
+			}
+			tokens = append(tokens, conditionToken{conditionWord, string(runes[i:end])})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+func isConditionWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_'
+}
+
+// conditionParser is a recursive descent parser for dependency conditions.
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+}
+
+func (p *conditionParser) peek() (conditionToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return conditionToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *conditionParser) peekSymbol(symbol string) bool {
+	tok, ok := p.peek()
+	return ok && tok.kind == conditionSymbol && tok.value == symbol
+}
+
+func (p *conditionParser) parseOr() (conditionNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekSymbol("||") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = conditionOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (conditionNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekSymbol("&&") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = conditionAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (conditionNode, error) {
+	if p.peekSymbol("!") {
+		p.pos++
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return conditionNot{node}, nil
+	}
+
+	if p.peekSymbol("(") {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peekSymbol(")") {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		p.pos++
+		return node, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peekSymbol("==") || p.peekSymbol("!=") {
+		tok, _ := p.peek()
+		p.pos++
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return conditionCompare{left: left, right: right, equal: tok.value == "=="}, nil
+	}
+
+	if left.parameter == "" {
+		return nil, errors.Errorf("%q must be compared to a parameter", left.literal)
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseOperand() (conditionOperand, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return conditionOperand{}, errors.New("unexpected end of condition")
+	}
+
+	switch tok.kind {
+	case conditionString:
+		p.pos++
+		return conditionOperand{literal: tok.value}, nil
+	case conditionWord:
+		p.pos++
+		if strings.HasPrefix(tok.value, conditionParameterPrefix) {
+			param := strings.TrimPrefix(tok.value, conditionParameterPrefix)
+			if param == "" {
+				return conditionOperand{}, errors.Errorf("missing parameter name in %q", tok.value)
+			}
+			return conditionOperand{parameter: param}, nil
+		}
+		if strings.HasPrefix(tok.value, "bundle.") {
+			return conditionOperand{}, errors.Errorf("unsupported reference %q, only bundle.parameters.NAME may be used in a condition", tok.value)
+		}
+		return conditionOperand{literal: tok.value}, nil
+	default:
+		return conditionOperand{}, errors.Errorf("unexpected %q", tok.value)
+	}
+}
@@ -0,0 +1,90 @@
+package extensions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependencyCondition_Evaluate(t *testing.T) {
+	params := map[string]string{
+		"mysql-enabled": "true",
+		"replicas":      "3",
+		"db":            "mysql",
+		"empty":         "",
+		"disabled":      "False",
+	}
+
+	testcases := []struct {
+		condition string
+		want      bool
+	}{
+		{condition: "bundle.parameters.mysql-enabled", want: true},
+		{condition: "!bundle.parameters.mysql-enabled", want: false},
+		{condition: "bundle.parameters.disabled", want: false},
+		{condition: "bundle.parameters.empty", want: false},
+		{condition: "bundle.parameters.missing", want: false},
+		{condition: "bundle.parameters.db == 'mysql'", want: true},
+		{condition: `bundle.parameters.db != "mysql"`, want: false},
+		{condition: "bundle.parameters.db == postgres", want: false},
+		{condition: "bundle.parameters.replicas == 3.0", want: true},
+		{condition: "bundle.parameters.disabled == false", want: true},
+		{condition: "bundle.parameters.empty || bundle.parameters.mysql-enabled", want: true},
+		{condition: "bundle.parameters.empty || bundle.parameters.disabled && bundle.parameters.mysql-enabled", want: false},
+		{condition: "!(bundle.parameters.db == 'mysql' && bundle.parameters.disabled)", want: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.condition, func(t *testing.T) {
+			c, err := ParseDependencyCondition(tc.condition)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, c.Evaluate(params))
+		})
+	}
+}
+
+func TestParseDependencyCondition(t *testing.T) {
+	testcases := []struct {
+		condition  string
+		wantParams []string
+		wantErr    string
+	}{
+		{condition: "bundle.parameters.a && bundle.parameters.b == 'x'", wantParams: []string{"a", "b"}},
+		{condition: "", wantErr: "the condition is empty"},
+		{condition: "true", wantErr: `"true" must be compared to a parameter`},
+		{condition: "bundle.parameters.a ==", wantErr: "unexpected end of condition"},
+		{condition: "bundle.parameters.a = 1", wantErr: `unexpected "="`},
+		{condition: "(bundle.parameters.a", wantErr: "missing closing parenthesis"},
+		{condition: "bundle.parameters.a bundle.parameters.b", wantErr: `unexpected "bundle.parameters.b"`},
+		{condition: "bundle.parameters.a == 'oops", wantErr: "unterminated string"},
+		{condition: "bundle.outputs.a", wantErr: "only bundle.parameters.NAME may be used in a condition"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.condition, func(t *testing.T) {
+			c, err := ParseDependencyCondition(tc.condition)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantParams, c.Parameters())
+		})
+	}
+}
+
+func TestDependency_ShouldExecute(t *testing.T) {
+	params := map[string]string{"mysql-enabled": "false"}
+
+	required := Dependency{Name: "mysql"}
+	execute, err := required.ShouldExecute(params)
+	require.NoError(t, err)
+	assert.True(t, execute, "dependencies that are not optional should always execute")
+
+	optional := Dependency{Name: "mysql", Optional: true, Condition: "bundle.parameters.mysql-enabled"}
+	execute, err = optional.ShouldExecute(params)
+	require.NoError(t, err)
+	assert.False(t, execute)
+}
@@ -0,0 +1,209 @@
+package extensions
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+
+	"get.porter.sh/porter/pkg/yaml"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/pkg/errors"
+)
+
+// CredentialTypesExtensionKey represents the full key for the custom extension
+// that declares the type and schema of a bundle's credentials. It is only used
+// to validate credentials on the host and is not added to the bundle's required
+// extensions.
+const CredentialTypesExtensionKey = PorterExtensionsPrefix + "credential-types"
+
+const (
+	// CredentialTypeJSON is a credential that contains a JSON document, such as a service principal.
+	CredentialTypeJSON = "json"
+
+	// CredentialTypePEM is a credential that contains one or more PEM encoded blocks, such as a certificate.
+	CredentialTypePEM = "pem"
+
+	// CredentialTypeKubeconfig is a credential that contains a kubeconfig file.
+	CredentialTypeKubeconfig = "kubeconfig"
+
+	// CredentialTypeFile is a credential that contains the contents of a file.
+	CredentialTypeFile = "file"
+)
+
+// CredentialTypes are the well-known types of credentials that Porter validates.
+var CredentialTypes = []string{CredentialTypeJSON, CredentialTypePEM, CredentialTypeKubeconfig, CredentialTypeFile}
+
+// IsCredentialType checks if the type is a well-known credential type.
+func IsCredentialType(t string) bool {
+	for _, ct := range CredentialTypes {
+		if t == ct {
+			return true
+		}
+	}
+	return false
+}
+
+// CredentialTypeDefinitions describes the type of each credential on a bundle
+// that declared one, keyed by the credential name.
+type CredentialTypeDefinitions map[string]CredentialType
+
+// CredentialType describes what a credential's value must look like.
+type CredentialType struct {
+	// Type is a well-known type of credential, e.g. json or pem.
+	Type string `json:"type,omitempty" mapstructure:"type"`
+
+	// Definition is the name of the json schema in the bundle's definitions that the credential must be valid against.
+	Definition string `json:"definition,omitempty" mapstructure:"definition"`
+}
+
+// ReadCredentialTypes returns the credential types defined on the bundle. When
+// the bundle does not define any, an empty set is returned.
+func ReadCredentialTypes(bun bundle.Bundle) (CredentialTypeDefinitions, error) {
+	data, ok := bun.Custom[CredentialTypesExtensionKey]
+	if !ok {
+		return CredentialTypeDefinitions{}, nil
+	}
+
+	dataB, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not marshal the untyped credential types extension data %q", string(dataB))
+	}
+
+	types := CredentialTypeDefinitions{}
+	err = json.Unmarshal(dataB, &types)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not unmarshal the credential types extension %q", string(dataB))
+	}
+
+	return types, nil
+}
+
+// ValidateCredentials checks that the resolved credentials match the types
+// declared on the bundle. The returned errors describe what is wrong with
+// a credential without including its value, so they are safe to print.
+func ValidateCredentials(bun bundle.Bundle, creds map[string]string) error {
+	types, err := ReadCredentialTypes(bun)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		value, ok := creds[name]
+		if !ok {
+			// Missing credentials are reported by the cnab-go credential validation
+			continue
+		}
+
+		ct := types[name]
+		var schema *definition.Schema
+		if ct.Definition != "" {
+			schema, ok = bun.Definitions[ct.Definition]
+			if !ok {
+				return errors.Errorf("the definition %s for credential %s was not found in the bundle", ct.Definition, name)
+			}
+		}
+
+		if err := ct.Validate(value, schema); err != nil {
+			problems = append(problems, fmt.Sprintf("credential %s %s", name, err.Error()))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("invalid credentials:\n  * %s", strings.Join(problems, "\n  * "))
+	}
+	return nil
+}
+
+// Validate checks that the value is a well-formed credential of the type
+// and is valid against the schema, when one is specified. When the credential
+// is json, the schema validates the parsed document, otherwise it validates
+// the value as a string.
+func (ct CredentialType) Validate(value string, schema *definition.Schema) error {
+	var doc interface{} = value
+
+	switch ct.Type {
+	case CredentialTypeJSON:
+		if err := json.Unmarshal([]byte(value), &doc); err != nil {
+			// The json errors include part of the value, so only report where it failed
+			if syntaxErr, ok := err.(*json.SyntaxError); ok {
+				return errors.Errorf("is not valid JSON, the error is at offset %d", syntaxErr.Offset)
+			}
+			return errors.New("is not valid JSON")
+		}
+	case CredentialTypePEM:
+		block, _ := pem.Decode([]byte(value))
+		if block == nil {
+			return errors.New("is not PEM encoded")
+		}
+	case CredentialTypeKubeconfig:
+		var kubeconfig struct {
+			Kind     string        `yaml:"kind"`
+			Clusters []interface{} `yaml:"clusters"`
+		}
+		if err := yaml.Unmarshal([]byte(value), &kubeconfig); err != nil {
+			return errors.New("is not a valid kubeconfig, it is not valid YAML")
+		}
+		if kubeconfig.Kind != "Config" {
+			return errors.New("is not a valid kubeconfig, the kind is not Config")
+		}
+		if len(kubeconfig.Clusters) == 0 {
+			return errors.New("is not a valid kubeconfig, it does not define any clusters")
+		}
+	case CredentialTypeFile:
+		if value == "" {
+			return errors.New("is an empty file")
+		}
+	}
+
+	if schema == nil {
+		return nil
+	}
+
+	valErrs, err := schema.Validate(doc)
+	if err != nil {
+		return errors.Wrap(err, "could not be validated against its schema")
+	}
+	if len(valErrs) == 0 {
+		return nil
+	}
+
+	msgs := make([]string, len(valErrs))
+	for i, valErr := range valErrs {
+		msg := redactValues(valErr.Error, doc)
+		if valErr.Path != "" && valErr.Path != "/" {
+			msg = fmt.Sprintf("%s: %s", valErr.Path, msg)
+		}
+		msgs[i] = msg
+	}
+	return errors.Errorf("does not match its schema: %s", strings.Join(msgs, ", "))
+}
+
+// redactValues removes the string values in a document from a schema
+// validation message, some of which include the value that failed.
+func redactValues(msg string, doc interface{}) string {
+	switch v := doc.(type) {
+	case string:
+		if v != "" {
+			msg = strings.ReplaceAll(msg, v, "*******")
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			msg = redactValues(msg, item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			msg = redactValues(msg, item)
+		}
+	}
+	return msg
+}
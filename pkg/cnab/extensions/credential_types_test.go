@@ -0,0 +1,147 @@
+package extensions
+
+import (
+	"testing"
+
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://127.0.0.1:6443
+`
+
+const testCertificate = `-----BEGIN CERTIFICATE-----
+MIIBszCCAVmgAwIBAgIUQ0VSVElGSUNBVEVGT1JURVNUSU5HMAoGCCqGSM49BAMC
+-----END CERTIFICATE-----
+`
+
+func TestReadCredentialTypes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defined", func(t *testing.T) {
+		bun := bundle.Bundle{
+			Custom: map[string]interface{}{
+				CredentialTypesExtensionKey: map[string]interface{}{
+					"kubeconfig": map[string]interface{}{"type": "kubeconfig"},
+					"azure":      map[string]interface{}{"type": "json", "definition": "azure-credential"},
+				},
+			},
+		}
+
+		types, err := ReadCredentialTypes(bun)
+		require.NoError(t, err)
+		assert.Equal(t, CredentialTypeDefinitions{
+			"kubeconfig": {Type: CredentialTypeKubeconfig},
+			"azure":      {Type: CredentialTypeJSON, Definition: "azure-credential"},
+		}, types)
+	})
+
+	t.Run("undefined", func(t *testing.T) {
+		types, err := ReadCredentialTypes(bundle.Bundle{})
+		require.NoError(t, err)
+		assert.Empty(t, types)
+	})
+}
+
+func TestCredentialType_Validate(t *testing.T) {
+	t.Parallel()
+
+	spSchema := &definition.Schema{
+		Type:     "object",
+		Required: []string{"clientId", "clientSecret"},
+		Properties: map[string]*definition.Schema{
+			"clientId":     {Type: "string"},
+			"clientSecret": {Type: "string", MinLength: toInt(20)},
+		},
+	}
+
+	testcases := []struct {
+		name    string
+		ct      CredentialType
+		schema  *definition.Schema
+		value   string
+		wantErr string
+	}{
+		{name: "untyped", value: "anything"},
+		{name: "valid json", ct: CredentialType{Type: CredentialTypeJSON}, value: `{"clientId": "abc"}`},
+		{name: "invalid json", ct: CredentialType{Type: CredentialTypeJSON}, value: `{"clientId": topsecret}`,
+			wantErr: "is not valid JSON, the error is at offset 15"},
+		{name: "valid pem", ct: CredentialType{Type: CredentialTypePEM}, value: testCertificate},
+		{name: "invalid pem", ct: CredentialType{Type: CredentialTypePEM}, value: "topsecret",
+			wantErr: "is not PEM encoded"},
+		{name: "valid kubeconfig", ct: CredentialType{Type: CredentialTypeKubeconfig}, value: testKubeconfig},
+		{name: "kubeconfig without clusters", ct: CredentialType{Type: CredentialTypeKubeconfig}, value: "kind: Config\n",
+			wantErr: "is not a valid kubeconfig, it does not define any clusters"},
+		{name: "kubeconfig that is not yaml", ct: CredentialType{Type: CredentialTypeKubeconfig}, value: "topsecret: [",
+			wantErr: "is not a valid kubeconfig, it is not valid YAML"},
+		{name: "empty file", ct: CredentialType{Type: CredentialTypeFile}, value: "",
+			wantErr: "is an empty file"},
+		{name: "valid schema", ct: CredentialType{Type: CredentialTypeJSON}, schema: spSchema,
+			value: `{"clientId": "abc", "clientSecret": "abcdefghijklmnopqrstuvwxyz"}`},
+		{name: "missing property", ct: CredentialType{Type: CredentialTypeJSON}, schema: spSchema,
+			value: `{"clientId": "abc"}`, wantErr: `does not match its schema: "clientSecret" value is required`},
+		{name: "invalid property", ct: CredentialType{Type: CredentialTypeJSON}, schema: spSchema,
+			value:   `{"clientId": "abc", "clientSecret": "topsecret"}`,
+			wantErr: "does not match its schema: /clientSecret: min length of 20 characters required: *******"},
+		{name: "string schema", schema: &definition.Schema{Type: "string", MaxLength: toInt(3)}, value: "topsecret",
+			wantErr: "does not match its schema: max length of 3 characters exceeded: *******"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.ct.Validate(tc.value, tc.schema)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.wantErr)
+			assert.NotContains(t, err.Error(), "topsecret", "the credential value should not be included in the error")
+		})
+	}
+}
+
+func TestValidateCredentials(t *testing.T) {
+	t.Parallel()
+
+	bun := bundle.Bundle{
+		Definitions: definition.Definitions{
+			"token-credential": {Type: "string", MinLength: toInt(10)},
+		},
+		Custom: map[string]interface{}{
+			CredentialTypesExtensionKey: CredentialTypeDefinitions{
+				"kubeconfig": {Type: CredentialTypeKubeconfig},
+				"cert":       {Type: CredentialTypePEM},
+				"token":      {Definition: "token-credential"},
+			},
+		},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		creds := map[string]string{"kubeconfig": testKubeconfig, "token": "abcdefghijkl"}
+		err := ValidateCredentials(bun, creds)
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		creds := map[string]string{"kubeconfig": "topsecret", "cert": "topsecret", "token": "short"}
+		err := ValidateCredentials(bun, creds)
+		require.EqualError(t, err, `invalid credentials:
+  * credential cert is not PEM encoded
+  * credential kubeconfig is not a valid kubeconfig, it is not valid YAML
+  * credential token does not match its schema: min length of 10 characters required: *******`)
+	})
+}
+
+func toInt(v int) *int {
+	return &v
+}
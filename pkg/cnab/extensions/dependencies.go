@@ -45,8 +45,34 @@ type Dependency struct {
 	// Bundle is the location of the bundle in a registry, for example REGISTRY/NAME:TAG
 	Bundle string `json:"bundle" mapstructure:"bundle"`
 
+	// Path is the location of a local bundle directory, relative to the porter
+	// manifest of the parent bundle. Path dependencies are only used during
+	// development and are replaced with Bundle when the parent is published.
+	Path string `json:"path,omitempty" mapstructure:"path"`
+
 	// Version is a set of allowed versions
 	Version *DependencyVersion `json:"version,omitempty" mapstructure:"version"`
+
+	// Optional dependencies are only executed when their Condition is true.
+	Optional bool `json:"optional,omitempty" mapstructure:"optional"`
+
+	// Condition is an expression evaluated against the parameters of the parent
+	// bundle that determines if an optional dependency is executed.
+	Condition string `json:"condition,omitempty" mapstructure:"condition"`
+}
+
+// ShouldExecute evaluates the dependency's condition against the parameters of
+// the parent bundle. Dependencies that are not optional are always executed.
+func (d Dependency) ShouldExecute(params map[string]string) (bool, error) {
+	if !d.Optional {
+		return true, nil
+	}
+
+	condition, err := ParseDependencyCondition(d.Condition)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid dependency %s", d.Name)
+	}
+	return condition.Evaluate(params), nil
 }
 
 // DependencyVersion is a set of allowed versions for a dependency
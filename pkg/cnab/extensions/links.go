@@ -0,0 +1,45 @@
+package extensions
+
+import (
+	"encoding/json"
+
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/pkg/errors"
+)
+
+// LinksExtensionKey represents the full key for the custom extension that
+// holds urls to additional information about the bundle. It is informational
+// only and is not added to the bundle's required extensions.
+const LinksExtensionKey = PorterExtensionsPrefix + "links"
+
+// Links are urls to additional information about a bundle, used by catalogs
+// to direct users to the bundle's source and documentation.
+type Links struct {
+	// Source is the url of the bundle's source code
+	Source string `json:"source,omitempty" mapstructure:"source"`
+
+	// Documentation is the url of the bundle's documentation
+	Documentation string `json:"documentation,omitempty" mapstructure:"documentation"`
+}
+
+// ReadLinks returns the links defined on the bundle. When the bundle
+// does not define any links, an empty Links is returned.
+func ReadLinks(bun bundle.Bundle) (Links, error) {
+	data, ok := bun.Custom[LinksExtensionKey]
+	if !ok {
+		return Links{}, nil
+	}
+
+	dataB, err := json.Marshal(data)
+	if err != nil {
+		return Links{}, errors.Wrapf(err, "could not marshal the untyped links extension data %q", string(dataB))
+	}
+
+	links := Links{}
+	err = json.Unmarshal(dataB, &links)
+	if err != nil {
+		return Links{}, errors.Wrapf(err, "could not unmarshal the links extension %q", string(dataB))
+	}
+
+	return links, nil
+}
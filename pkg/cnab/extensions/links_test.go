@@ -0,0 +1,35 @@
+package extensions
+
+import (
+	"testing"
+
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLinks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defined", func(t *testing.T) {
+		bun := bundle.Bundle{
+			Custom: map[string]interface{}{
+				LinksExtensionKey: map[string]interface{}{
+					"source":        "https://github.com/getporter/hello",
+					"documentation": "https://porter.sh/hello",
+				},
+			},
+		}
+
+		links, err := ReadLinks(bun)
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.com/getporter/hello", links.Source)
+		assert.Equal(t, "https://porter.sh/hello", links.Documentation)
+	})
+
+	t.Run("undefined", func(t *testing.T) {
+		links, err := ReadLinks(bundle.Bundle{})
+		require.NoError(t, err)
+		assert.Equal(t, Links{}, links)
+	})
+}
@@ -0,0 +1,77 @@
+package extensions
+
+import (
+	"encoding/json"
+
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/pkg/errors"
+)
+
+// ParametersExtensionKey represents the full key for the custom extension
+// that holds the settings of a bundle's parameters that are not part of the
+// CNAB spec, such as deprecations and aliases. It is not added to the bundle's
+// required extensions, so that other tools can still run the bundle.
+const ParametersExtensionKey = PorterExtensionsPrefix + "parameters"
+
+// PorterParameters are the Porter specific settings for a bundle's parameters,
+// keyed by the parameter name.
+type PorterParameters map[string]PorterParameter
+
+// PorterParameter are the Porter specific settings for a parameter.
+type PorterParameter struct {
+	// Deprecated is a message explaining why the parameter is deprecated and what to use instead.
+	Deprecated string `json:"deprecated,omitempty" mapstructure:"deprecated"`
+
+	// Aliases are previous names of the parameter that are still accepted.
+	Aliases []string `json:"aliases,omitempty" mapstructure:"aliases"`
+}
+
+// ReadPorterParameters returns the Porter specific parameter settings defined
+// on the bundle. When the bundle does not define any, an empty set is returned.
+func ReadPorterParameters(bun bundle.Bundle) (PorterParameters, error) {
+	data, ok := bun.Custom[ParametersExtensionKey]
+	if !ok {
+		return PorterParameters{}, nil
+	}
+
+	dataB, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not marshal the untyped parameters extension data %q", string(dataB))
+	}
+
+	params := PorterParameters{}
+	err = json.Unmarshal(dataB, &params)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not unmarshal the parameters extension %q", string(dataB))
+	}
+
+	return params, nil
+}
+
+// ResolveName returns the current name of a parameter, when the name is an
+// alias of a renamed parameter, and true. Otherwise the name is returned
+// unchanged and false.
+func (p PorterParameters) ResolveName(name string) (string, bool) {
+	if _, ok := p[name]; ok {
+		return name, false
+	}
+
+	for paramName, param := range p {
+		for _, alias := range param.Aliases {
+			if alias == name {
+				return paramName, true
+			}
+		}
+	}
+	return name, false
+}
+
+// GetDeprecation returns the deprecation message for a parameter, and true
+// when the parameter is deprecated.
+func (p PorterParameters) GetDeprecation(name string) (string, bool) {
+	param, ok := p[name]
+	if !ok || param.Deprecated == "" {
+		return "", false
+	}
+	return param.Deprecated, true
+}
@@ -0,0 +1,76 @@
+package extensions
+
+import (
+	"testing"
+
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPorterParameters(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defined", func(t *testing.T) {
+		bun := bundle.Bundle{
+			Custom: map[string]interface{}{
+				ParametersExtensionKey: map[string]interface{}{
+					"region":      map[string]interface{}{"aliases": []string{"location"}},
+					"tshirt-size": map[string]interface{}{"deprecated": "use node-count instead"},
+				},
+			},
+		}
+
+		params, err := ReadPorterParameters(bun)
+		require.NoError(t, err)
+		assert.Equal(t, PorterParameters{
+			"region":      {Aliases: []string{"location"}},
+			"tshirt-size": {Deprecated: "use node-count instead"},
+		}, params)
+	})
+
+	t.Run("undefined", func(t *testing.T) {
+		params, err := ReadPorterParameters(bundle.Bundle{})
+		require.NoError(t, err)
+		assert.Empty(t, params)
+	})
+}
+
+func TestPorterParameters_ResolveName(t *testing.T) {
+	t.Parallel()
+
+	params := PorterParameters{
+		"region": {Aliases: []string{"location", "zone"}},
+	}
+
+	name, aliased := params.ResolveName("zone")
+	assert.Equal(t, "region", name)
+	assert.True(t, aliased)
+
+	name, aliased = params.ResolveName("region")
+	assert.Equal(t, "region", name)
+	assert.False(t, aliased)
+
+	name, aliased = params.ResolveName("size")
+	assert.Equal(t, "size", name)
+	assert.False(t, aliased)
+}
+
+func TestPorterParameters_GetDeprecation(t *testing.T) {
+	t.Parallel()
+
+	params := PorterParameters{
+		"region":      {Aliases: []string{"location"}},
+		"tshirt-size": {Deprecated: "use node-count instead"},
+	}
+
+	msg, deprecated := params.GetDeprecation("tshirt-size")
+	assert.True(t, deprecated)
+	assert.Equal(t, "use node-count instead", msg)
+
+	_, deprecated = params.GetDeprecation("region")
+	assert.False(t, deprecated)
+
+	_, deprecated = params.GetDeprecation("missing")
+	assert.False(t, deprecated)
+}
@@ -13,6 +13,10 @@ import (
 type DependencyLock struct {
 	Alias     string
 	Reference string
+
+	// Path is the local bundle directory of a path dependency, which is used
+	// instead of Reference.
+	Path string
 }
 
 type DependencySolver struct {
@@ -33,21 +37,33 @@ func (s *DependencySolver) ResolveDependencies(bun bundle.Bundle) ([]DependencyL
 
 	q := make([]DependencyLock, 0, len(orderedDeps))
 	for _, dep := range orderedDeps {
-		ref, err := s.ResolveVersion(dep.Name, dep)
+		lock, err := s.ResolveDependency(dep)
 		if err != nil {
 			return nil, err
 		}
-
-		lock := DependencyLock{
-			Alias:     dep.Name,
-			Reference: reference.FamiliarString(ref),
-		}
 		q = append(q, lock)
 	}
 
 	return q, nil
 }
 
+// ResolveDependency locks a single dependency to a specific bundle reference.
+func (s *DependencySolver) ResolveDependency(dep Dependency) (DependencyLock, error) {
+	if dep.Path != "" {
+		return DependencyLock{Alias: dep.Name, Path: dep.Path}, nil
+	}
+
+	ref, err := s.ResolveVersion(dep.Name, dep)
+	if err != nil {
+		return DependencyLock{}, err
+	}
+
+	return DependencyLock{
+		Alias:     dep.Name,
+		Reference: reference.FamiliarString(ref),
+	}, nil
+}
+
 // ResolveVersion returns the bundle name, its version and any error.
 func (s *DependencySolver) ResolveVersion(name string, dep Dependency) (reference.NamedTagged, error) {
 	ref, err := reference.ParseNormalizedNamed(dep.Bundle)
@@ -22,6 +22,9 @@ func TestDependencySolver_ResolveDependencies(t *testing.T) {
 					"nginx": {
 						Bundle: "localhost:5000/nginx:1.19",
 					},
+					"redis": {
+						Path: "../redis",
+					},
 				},
 			},
 		},
@@ -30,21 +33,26 @@ func TestDependencySolver_ResolveDependencies(t *testing.T) {
 	s := DependencySolver{}
 	locks, err := s.ResolveDependencies(bun)
 	require.NoError(t, err)
-	require.Len(t, locks, 2)
+	require.Len(t, locks, 3)
 
 	var mysql DependencyLock
 	var nginx DependencyLock
+	var redis DependencyLock
 	for _, lock := range locks {
 		switch lock.Alias {
 		case "mysql":
 			mysql = lock
 		case "nginx":
 			nginx = lock
+		case "redis":
+			redis = lock
 		}
 	}
 
 	assert.Equal(t, "getporter/mysql:5.7", mysql.Reference)
 	assert.Equal(t, "localhost:5000/nginx:1.19", nginx.Reference)
+	assert.Equal(t, "../redis", redis.Path)
+	assert.Empty(t, redis.Reference)
 }
 
 func TestDependencySolver_ResolveVersion(t *testing.T) {
@@ -2,12 +2,17 @@ package cnabprovider
 
 import (
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
 
 	cnabaction "github.com/cnabio/cnab-go/action"
 	"github.com/cnabio/cnab-go/bundle"
 
 	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/runtime/steplogs"
 	"get.porter.sh/porter/pkg/yaml"
 	"github.com/cnabio/cnab-go/action"
 	"github.com/cnabio/cnab-go/claim"
@@ -45,8 +50,23 @@ type ActionArguments struct {
 	// Path to an optional relocation mapping file
 	RelocationMapping string
 
+	// RelocationMappingContent is used instead of RelocationMapping when the
+	// relocation mapping is not in a file, for example when it was recorded
+	// with the installation.
+	RelocationMappingContent string
+
 	// Give the bundle privileged access to the docker daemon.
 	AllowDockerHostAccess bool
+
+	// Custom extension data to record on the claim, such as the approval for
+	// the action. It is merged into the custom data carried over from the
+	// previous claim, and a key set to nil is removed from the claim.
+	Custom map[string]interface{}
+
+	// SkippedDependencies are the optional dependencies that are not executed
+	// because their condition is false. Their outputs are not used to set
+	// parameters, because they may be left over from a previous run.
+	SkippedDependencies []string
 }
 
 func (r *Runtime) ApplyConfig(args ActionArguments) action.OperationConfigs {
@@ -54,12 +74,14 @@ func (r *Runtime) ApplyConfig(args ActionArguments) action.OperationConfigs {
 		r.SetOutput(),
 		r.AddFiles(args),
 		r.AddRelocation(args),
+		r.AddProxyAndCACertificates(),
 	}
 }
 
 func (r *Runtime) SetOutput() action.OperationConfigFunc {
 	return func(op *driver.Operation) error {
-		op.Out = r.Out
+		// The step markers are kept in the saved logs, but are not displayed
+		op.Out = steplogs.NewFilterWriter(r.Out)
 		op.Err = r.Err
 		return nil
 	}
@@ -89,14 +111,20 @@ func (r *Runtime) AddFiles(args ActionArguments) action.OperationConfigFunc {
 // to the operation's files.
 func (r *Runtime) AddRelocation(args ActionArguments) action.OperationConfigFunc {
 	return func(op *driver.Operation) error {
+		b := []byte(args.RelocationMappingContent)
 		if args.RelocationMapping != "" {
-			b, err := r.FileSystem.ReadFile(args.RelocationMapping)
+			var err error
+			b, err = r.FileSystem.ReadFile(args.RelocationMapping)
 			if err != nil {
 				return errors.Wrap(err, "unable to add relocation mapping")
 			}
+		}
+		if len(b) > 0 {
 			op.Files["/cnab/app/relocation-mapping.json"] = string(b)
 			var reloMap relocation.ImageRelocationMap
-			err = json.Unmarshal(b, &reloMap)
+			if err := json.Unmarshal(b, &reloMap); err != nil {
+				return errors.Wrap(err, "invalid relocation mapping")
+			}
 			// If the invocation image is present in the relocation mapping, we need
 			// to update the operation and set the new image reference. Unfortunately,
 			// the relocation mapping is just reference => reference, so there isn't a
@@ -109,6 +137,40 @@ func (r *Runtime) AddRelocation(args ActionArguments) action.OperationConfigFunc
 	}
 }
 
+// AddProxyAndCACertificates adds the proxy environment variables and the CA
+// certificates from the porter config file to the operation. The porter
+// runtime installs the certificates into the trust store of the invocation
+// image before the first step runs.
+func (r *Runtime) AddProxyAndCACertificates() action.OperationConfigFunc {
+	return func(op *driver.Operation) error {
+		if r.Data == nil {
+			return nil
+		}
+
+		for k, v := range r.Data.GetProxyEnv() {
+			// Don't overwrite a value set by the bundle's parameters or credentials
+			if _, ok := op.Environment[k]; !ok {
+				op.Environment[k] = v
+			}
+		}
+
+		for i, certPath := range r.Data.CACertificates {
+			b, err := r.FileSystem.ReadFile(certPath)
+			if err != nil {
+				return errors.Wrapf(err, "unable to add CA certificate %s", certPath)
+			}
+			if block, _ := pem.Decode(b); block == nil {
+				return errors.Errorf("CA certificate %s is not PEM encoded", certPath)
+			}
+
+			// Number the files so that certificates with the same file name don't collide
+			name := fmt.Sprintf("%02d-%s.crt", i+1, strings.TrimSuffix(filepath.Base(certPath), filepath.Ext(certPath)))
+			op.Files[path.Join(config.CACertificatesDir, name)] = string(b)
+		}
+		return nil
+	}
+}
+
 func (r *Runtime) Execute(args ActionArguments) error {
 	if args.Action == "" {
 		return errors.New("action is required")
@@ -160,6 +222,8 @@ func (r *Runtime) Execute(args ActionArguments) error {
 		return err
 	}
 
+	c.Custom = mergeCustom(c.Custom, args.Custom)
+
 	// Validate the action we are about to perform
 	err = c.Validate()
 	if err != nil {
@@ -251,3 +315,32 @@ func (r *Runtime) printDebugInfo(creds valuesource.Set, params map[string]interf
 		fmt.Fprintf(r.Err, "params: %v\ncreds: %v\n", paramKeys, credKeys)
 	}
 }
+
+// mergeCustom merges the custom data for an action into the custom data
+// carried over from the previous claim. Keys set to nil are removed, so that
+// data that only applies to a single action is not carried over to the next
+// one. Custom data that is not a map cannot be merged and is replaced.
+func mergeCustom(existing interface{}, custom map[string]interface{}) interface{} {
+	if len(custom) == 0 {
+		return existing
+	}
+
+	merged := map[string]interface{}{}
+	if existingMap, ok := existing.(map[string]interface{}); ok {
+		for k, v := range existingMap {
+			merged[k] = v
+		}
+	}
+	for k, v := range custom {
+		if v == nil {
+			delete(merged, k)
+		} else {
+			merged[k] = v
+		}
+	}
+
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
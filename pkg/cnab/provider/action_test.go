@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"testing"
 
+	"get.porter.sh/porter/pkg/config"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-go/driver"
 	"github.com/stretchr/testify/assert"
@@ -42,3 +43,39 @@ func TestAddReloccation(t *testing.T) {
 	assert.Equal(t, "my.registry/microservice@sha256:cca460afa270d4c527981ef9ca4989346c56cf9b20217dcea37df1ece8120687", op.Image.Image)
 
 }
+
+func TestAddProxyAndCACertificates(t *testing.T) {
+	t.Parallel()
+
+	d := NewTestRuntime(t)
+	certData := d.TestConfig.TestContext.AddTestFile("testdata/acme-root.pem", "/home/me/certs/acme-root.pem")
+	d.Data = &config.Data{
+		HTTPSProxy:     "http://proxy.example.com:3128",
+		NoProxy:        "localhost",
+		CACertificates: []string{"/home/me/certs/acme-root.pem"},
+	}
+
+	op := &driver.Operation{
+		Environment: map[string]string{"NO_PROXY": "localhost,.example.com"},
+		Files:       make(map[string]string),
+	}
+	err := d.AddProxyAndCACertificates()(op)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"HTTPS_PROXY": "http://proxy.example.com:3128",
+		"https_proxy": "http://proxy.example.com:3128",
+		"NO_PROXY":    "localhost,.example.com",
+		"no_proxy":    "localhost",
+	}, op.Environment, "the proxy settings should be added without overwriting values set by the bundle")
+	assert.Equal(t, map[string]string{
+		"/cnab/app/porter/ca-certificates/01-acme-root.crt": string(certData),
+	}, op.Files)
+
+	t.Run("invalid certificate", func(t *testing.T) {
+		d.TestConfig.TestContext.AddTestFileContents([]byte("not a certificate"), "/home/me/certs/acme-root.pem")
+
+		err := d.AddProxyAndCACertificates()(op)
+		require.EqualError(t, err, "CA certificate /home/me/certs/acme-root.pem is not PEM encoded")
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"get.porter.sh/porter/pkg/cnab/extensions"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-go/credentials"
 	"github.com/cnabio/cnab-go/valuesource"
@@ -32,7 +33,7 @@ func (r *Runtime) loadCredentials(b bundle.Bundle, args ActionArguments) (values
 			return nil, err
 		}
 
-		rc, err := r.credentials.ResolveAll(cset)
+		rc, err := r.credentials.ResolveAll(cset, args.Installation)
 		if err != nil {
 			return nil, err
 		}
@@ -41,7 +42,13 @@ func (r *Runtime) loadCredentials(b bundle.Bundle, args ActionArguments) (values
 			resolvedCredentials[k] = v
 		}
 	}
-	return resolvedCredentials, credentials.Validate(resolvedCredentials, b.Credentials, args.Action)
+
+	err := credentials.Validate(resolvedCredentials, b.Credentials, args.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolvedCredentials, extensions.ValidateCredentials(b, resolvedCredentials)
 }
 
 // isPathy checks to see if a name looks like a path.
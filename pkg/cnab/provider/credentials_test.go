@@ -3,6 +3,7 @@ package cnabprovider
 import (
 	"testing"
 
+	"get.porter.sh/porter/pkg/cnab/extensions"
 	"get.porter.sh/porter/pkg/secrets"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-go/credentials"
@@ -134,4 +135,32 @@ func TestRuntime_loadCredentials_WithApplyTo(t *testing.T) {
 		assert.Equal(t, valuesource.Set{"password": "mypassword"}, gotValues)
 	})
 
+	t.Run("credential does not match its type", func(t *testing.T) {
+		t.Parallel()
+		r := NewTestRuntime(t)
+		r.TestCredentials.TestSecrets.AddSecret("password", "mypassword")
+
+		cs1 := credentials.NewCredentialSet("mycreds",
+			valuesource.Strategy{
+				Name: "password",
+				Source: valuesource.Source{
+					Key:   secrets.SourceSecret,
+					Value: "password",
+				},
+			})
+
+		err := r.credentials.Save(cs1)
+		require.NoError(t, err, "Save credential set failed")
+
+		b := getBundle(true)
+		b.Custom = map[string]interface{}{
+			extensions.CredentialTypesExtensionKey: extensions.CredentialTypeDefinitions{
+				"password": {Type: extensions.CredentialTypeJSON},
+			},
+		}
+		args := ActionArguments{CredentialIdentifiers: []string{"mycreds"}, Action: "install"}
+		_, err = r.loadCredentials(b, args)
+		require.EqualError(t, err, "invalid credentials:\n  * credential password is not valid JSON, the error is at offset 1")
+		assert.NotContains(t, err.Error(), "mypassword", "the credential value should not be included in the error")
+	})
 }
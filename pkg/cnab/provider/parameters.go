@@ -93,6 +93,16 @@ func (r *Runtime) getUnconvertedValueFromRaw(b bundle.Bundle, def *definition.Sc
 	return rawValue, nil
 }
 
+// isSkippedDependency determines if an optional dependency is not executed by the action.
+func isSkippedDependency(args ActionArguments, dependency string) bool {
+	for _, skipped := range args.SkippedDependencies {
+		if skipped == dependency {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *Runtime) resolveParameterSources(bun bundle.Bundle, args ActionArguments) (valuesource.Set, error) {
 	if r.Debug {
 		fmt.Fprintln(r.Err, "Resolving parameter sources...")
@@ -122,6 +132,10 @@ func (r *Runtime) resolveParameterSources(bun bundle.Bundle, args ActionArgument
 				installation = args.Installation
 				outputName = source.OutputName
 			case extensions.DependencyOutputParameterSource:
+				if isSkippedDependency(args, source.Dependency) {
+					// Let the parameter be set another way, usually its default
+					continue
+				}
 				installation = extensions.BuildPrerequisiteInstallationName(args.Installation, source.Dependency)
 				outputName = source.OutputName
 			}
@@ -615,4 +615,9 @@ func TestRuntime_ResolveParameterSources(t *testing.T) {
 		"connstr": "connstr value",
 	}
 	assert.Equal(t, want, got, "resolved incorrect parameter values")
+
+	args.SkippedDependencies = []string{"mysql"}
+	got, err = r.resolveParameterSources(bun, args)
+	require.NoError(t, err, "resolveParameterSources failed")
+	assert.Equal(t, valuesource.Set{"bar": "bar value"}, got, "the outputs of a skipped dependency should not be used")
 }
@@ -39,6 +39,43 @@ func TestRuntime_Upgrade(t *testing.T) {
 		assert.Equal(t, args.Installation, c.Installation, "wrong installation name recorded")
 	})
 
+	t.Run("merges custom data", func(t *testing.T) {
+		t.Parallel()
+
+		r := NewTestRuntime(t)
+		r.TestConfig.TestContext.AddTestFile("testdata/bundle.json", "bundle.json")
+
+		existingClaim, err := claim.New("mybuns", claim.ActionInstall, bundle.Bundle{}, nil)
+		require.NoError(t, err, "New claim failed")
+		existingClaim.Custom = map[string]interface{}{
+			"io.example.team":    "web",
+			"sh.porter.approval": map[string]interface{}{"requestId": "abc123"},
+		}
+		err = r.claims.SaveClaim(existingClaim)
+		require.NoError(t, err, "SaveClaim failed")
+
+		args := ActionArguments{
+			Action:       claim.ActionUpgrade,
+			Installation: "mybuns",
+			BundlePath:   "bundle.json",
+			Custom: map[string]interface{}{
+				"io.example.settings": "debug",
+				"sh.porter.approval":  nil,
+			},
+		}
+		err = r.Execute(args)
+		require.NoError(t, err, "Upgrade failed")
+
+		c, err := r.claims.ReadLastClaim(args.Installation)
+		require.NoError(t, err, "ReadLastClaim failed")
+
+		wantCustom := map[string]interface{}{
+			"io.example.team":     "web",
+			"io.example.settings": "debug",
+		}
+		assert.Equal(t, wantCustom, c.Custom, "the custom data from the previous claim should be kept, except for keys set to nil")
+	})
+
 	t.Run("requires existing claim", func(t *testing.T) {
 		t.Parallel()
 
@@ -38,6 +38,11 @@ const (
 
 	// ClaimFilepath is the filepath to the claim.json inside of an invocation image
 	ClaimFilepath = "/cnab/claim.json"
+
+	// CACertificatesDir is the directory inside of an invocation image where the
+	// CA certificates from the porter config file are placed, before they are
+	// installed into the trust store by the porter runtime.
+	CACertificatesDir = "/cnab/app/porter/ca-certificates"
 )
 
 // These are functions that afero doesn't support, so this lets us stub them out for tests to set the
@@ -45,6 +50,14 @@ const (
 var getExecutable = os.Executable
 var evalSymlinks = filepath.EvalSymlinks
 
+// ErrReadOnly is returned when an operation would modify Porter's data while
+// Porter is running in read-only mode.
+var ErrReadOnly = errors.New("porter is running in read-only mode. Remove the --read-only flag, or the read-only setting from the porter config file, to make changes")
+
+// ErrEphemeral is returned when a command only makes sense when Porter's data
+// is persisted, and Porter is running in ephemeral mode.
+var ErrEphemeral = errors.New("porter is running in ephemeral mode, where data is discarded when the command exits. Remove the --ephemeral flag, or the ephemeral setting from the porter config file, to use this command")
+
 type DataStoreLoaderFunc func(*Config) error
 
 var _ DataStoreLoaderFunc = NoopDataLoader
@@ -59,6 +72,14 @@ type Config struct {
 	Data       *Data
 	DataLoader DataStoreLoaderFunc
 
+	// ReadOnly prevents Porter from modifying its data, such as installations,
+	// credentials and parameters.
+	ReadOnly bool
+
+	// Ephemeral keeps Porter's data, such as installations and secrets, in
+	// memory instead of in PORTER_HOME.
+	Ephemeral bool
+
 	// Cache the resolved Porter home directory
 	porterHome string
 
@@ -162,6 +183,15 @@ func (c *Config) GetBundlesCache() (string, error) {
 	return filepath.Join(home, "bundles"), nil
 }
 
+// GetMixinBuildCache locates the cache of mixin build output from the porter home directory.
+func (c *Config) GetMixinBuildCache() (string, error) {
+	home, err := c.GetHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "cache", "mixins"), nil
+}
+
 func (c *Config) GetPluginsDir() (string, error) {
 	home, err := c.GetHomeDir()
 	if err != nil {
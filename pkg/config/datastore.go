@@ -1,6 +1,8 @@
 package config
 
 import (
+	"strings"
+
 	"github.com/pkg/errors"
 )
 
@@ -27,6 +29,43 @@ type Data struct {
 
 	// SecretSources defined in the configuration file.
 	SecretSources []SecretSource `mapstructure:"secrets"`
+
+	// LintRulePacks are the paths to the lint rule packs evaluated by porter lint and porter build.
+	LintRulePacks []string `mapstructure:"lint-rule-packs"`
+
+	// HTTPProxy is the proxy for http requests made by the invocation image.
+	HTTPProxy string `mapstructure:"http-proxy"`
+
+	// HTTPSProxy is the proxy for https requests made by the invocation image.
+	HTTPSProxy string `mapstructure:"https-proxy"`
+
+	// NoProxy lists the hosts that the invocation image connects to without the proxy.
+	NoProxy string `mapstructure:"no-proxy"`
+
+	// CACertificates are the paths to PEM encoded CA certificates that are
+	// trusted by the invocation image.
+	CACertificates []string `mapstructure:"ca-certificates"`
+}
+
+// GetProxyEnv returns the environment variables that configure the proxy
+// for the invocation image. Both the upper and lower case variables are set
+// because tools disagree on which one to use.
+func (d *Data) GetProxyEnv() map[string]string {
+	env := map[string]string{}
+	if d == nil {
+		return env
+	}
+
+	add := func(name string, value string) {
+		if value != "" {
+			env[strings.ToUpper(name)] = value
+			env[strings.ToLower(name)] = value
+		}
+	}
+	add("HTTP_PROXY", d.HTTPProxy)
+	add("HTTPS_PROXY", d.HTTPSProxy)
+	add("NO_PROXY", d.NoProxy)
+	return env
 }
 
 // SecretSource is the plugin stanza for secrets.
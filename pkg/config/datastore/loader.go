@@ -2,6 +2,8 @@ package datastore
 
 import (
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"get.porter.sh/porter/pkg/config"
@@ -11,10 +13,59 @@ import (
 	"github.com/spf13/viper"
 )
 
+const (
+	// ProjectConfigDir is the directory that contains the project config file,
+	// which is discovered from the current directory upward.
+	ProjectConfigDir = ".porter"
+
+	// configName is the name of the config file, without its extension.
+	configName = "config"
+
+	// allowProjectStorageKey is the setting in the home config file that
+	// allows a project config file to change where porter stores its data and
+	// resolves secrets, and how invocation images reach the network.
+	allowProjectStorageKey = "allow-project-storage"
+
+	// lintRulePacksKey is the setting with the paths to the lint rule packs.
+	lintRulePacksKey = "lint-rule-packs"
+
+	// caCertificatesKey is the setting with the paths to the CA certificates
+	// that are trusted by invocation images.
+	caCertificatesKey = "ca-certificates"
+)
+
+// pathKeys are the settings with paths to other files, which are relative to
+// the config file that sets them.
+var pathKeys = []string{
+	lintRulePacksKey,
+	caCertificatesKey,
+}
+
+// projectStorageKeys are the settings that a project config file may only
+// set when the home config file allows it, because they redirect storage or
+// secrets, route traffic through a proxy, trust additional certificates, or
+// relax the safeguards that are set in the home config file.
+var projectStorageKeys = []string{
+	allowProjectStorageKey,
+	"default-storage",
+	"default-storage-plugin",
+	"storage",
+	"default-secrets",
+	"default-secrets-plugin",
+	"secrets",
+	"http-proxy",
+	"https-proxy",
+	"no-proxy",
+	caCertificatesKey,
+	"read-only",
+	"allow-docker-host-access",
+}
+
 // FromFlagsThenEnvVarsThenConfigFile loads data with the following precedence:
 // * Flags (highest)
 // * Environment variables where --flag is assumed to be PORTER_FLAG
-// * Config file (lowest)
+// * Project config file
+// * Home config file (lowest)
 func FromFlagsThenEnvVarsThenConfigFile(cmd *cobra.Command) config.DataStoreLoaderFunc {
 	return buildDataLoader(func(v *viper.Viper) {
 		v.SetEnvPrefix("PORTER")
@@ -25,19 +76,25 @@ func FromFlagsThenEnvVarsThenConfigFile(cmd *cobra.Command) config.DataStoreLoad
 			// Environment variables can't have dashes in them, so bind them to their equivalent
 			// keys with underscores, e.g. --debug-plugins binds to PORTER_DEBUG_PLUGINS
 			if strings.Contains(f.Name, "-") {
-				envVarSuffix := strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
-				v.BindEnv(f.Name, fmt.Sprintf("PORTER_%s", envVarSuffix))
+				v.BindEnv(f.Name, toEnvVar(f.Name))
 			}
 
 			if !f.Changed && v.IsSet(f.Name) {
 				val := v.Get(f.Name)
-				cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val))
+				if values, ok := val.([]interface{}); ok {
+					// Lists, such as parameter-set, are applied one value at a time
+					for _, value := range values {
+						cmd.Flags().Set(f.Name, fmt.Sprintf("%v", value))
+					}
+				} else {
+					cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val))
+				}
 			}
 		})
 	})
 }
 
-// FromConfigFile loads data from the config file only.
+// FromConfigFile loads data from the config files only.
 func FromConfigFile(cfg *config.Config) error {
 	dataloader := buildDataLoader(nil)
 	return dataloader(cfg)
@@ -45,28 +102,30 @@ func FromConfigFile(cfg *config.Config) error {
 
 func buildDataLoader(viperCfg func(v *viper.Viper)) config.DataStoreLoaderFunc {
 	return func(cfg *config.Config) error {
-		home, _ := cfg.GetHomeDir()
+		files, err := readConfigFiles(cfg)
+		if err != nil {
+			return err
+		}
 
 		v := viper.New()
 		v.SetFs(cfg.FileSystem)
-		v.AddConfigPath(home)
-		err := v.ReadInConfig()
+		for _, file := range files {
+			if err = v.MergeConfigMap(file.Settings); err != nil {
+				return errors.Wrapf(err, "error merging config file at %q", file.Path)
+			}
+		}
 
 		if viperCfg != nil {
 			viperCfg(v)
 		}
 
 		var data config.Data
-		if err != nil {
-			if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-				data = DefaultDataStore()
-			} else {
-				return errors.Wrapf(err, "error reading config file at %q", v.ConfigFileUsed())
-			}
+		if len(files) == 0 {
+			data = DefaultDataStore()
 		} else {
 			err = v.Unmarshal(&data)
 			if err != nil {
-				return errors.Wrapf(err, "error unmarshaling config at %q", v.ConfigFileUsed())
+				return errors.Wrap(err, "error unmarshaling config")
 			}
 		}
 
@@ -80,3 +139,242 @@ func buildDataLoader(viperCfg func(v *viper.Viper)) config.DataStoreLoaderFunc {
 func DefaultDataStore() config.Data {
 	return config.Data{}
 }
+
+// configFile is a config file that was found and the settings that were read from it.
+type configFile struct {
+	Path     string
+	Settings map[string]interface{}
+}
+
+// readConfigFiles reads the home and project config files, in order of
+// increasing precedence. Files that do not exist are skipped.
+func readConfigFiles(cfg *config.Config) ([]configFile, error) {
+	var files []configFile
+
+	home, _ := cfg.GetHomeDir()
+	homeFile, err := readConfigFile(cfg, home)
+	if err != nil {
+		return nil, err
+	}
+	if homeFile != nil {
+		files = append(files, *homeFile)
+	}
+
+	projectDir := findProjectConfigDir(cfg, home)
+	if projectDir == "" {
+		return files, nil
+	}
+	projectFile, err := readConfigFile(cfg, projectDir)
+	if err != nil || projectFile == nil {
+		return files, err
+	}
+
+	allowStorage := homeFile != nil && viperBool(homeFile.Settings[allowProjectStorageKey])
+	for _, key := range projectStorageKeys {
+		if _, ok := projectFile.Settings[key]; !ok {
+			continue
+		}
+		if key == allowProjectStorageKey || !allowStorage {
+			fmt.Fprintf(cfg.Err, "WARNING: ignoring %s in the project config file %s, set %s = true in the config file in PORTER_HOME to allow projects to change porter's storage, secrets, proxy and certificate settings\n",
+				key, projectFile.Path, allowProjectStorageKey)
+			delete(projectFile.Settings, key)
+		}
+	}
+
+	if homeFile != nil {
+		appendLintRulePacks(homeFile, projectFile)
+	}
+
+	return append(files, *projectFile), nil
+}
+
+// appendLintRulePacks adds the lint rule packs in the project config file to
+// the rule packs in the home config file, instead of replacing them, so that
+// a project cannot skip the rule packs that are required by its organization.
+func appendLintRulePacks(homeFile *configFile, projectFile *configFile) {
+	projectPacks, ok := projectFile.Settings[lintRulePacksKey]
+	if !ok {
+		return
+	}
+	homePacks, ok := homeFile.Settings[lintRulePacksKey]
+	if !ok {
+		return
+	}
+
+	var packs []interface{}
+	included := make(map[string]bool)
+	for _, pack := range append(toSettingList(homePacks), toSettingList(projectPacks)...) {
+		path := fmt.Sprintf("%v", pack)
+		if included[path] {
+			continue
+		}
+		included[path] = true
+		packs = append(packs, pack)
+	}
+	projectFile.Settings[lintRulePacksKey] = packs
+}
+
+// toSettingList returns the values of a setting that may be set to either a
+// list or a single value.
+func toSettingList(value interface{}) []interface{} {
+	switch value := value.(type) {
+	case []interface{}:
+		return value
+	case nil:
+		return nil
+	default:
+		return []interface{}{value}
+	}
+}
+
+// readConfigFile reads the config file in the specified directory, returning
+// nil when the directory does not have a config file.
+func readConfigFile(cfg *config.Config, dir string) (*configFile, error) {
+	v := viper.New()
+	v.SetFs(cfg.FileSystem)
+	v.SetConfigName(configName)
+	v.AddConfigPath(dir)
+	err := v.ReadInConfig()
+	if err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "error reading config file at %q", v.ConfigFileUsed())
+	}
+
+	file := &configFile{Path: v.ConfigFileUsed(), Settings: v.AllSettings()}
+	resolveRelativePaths(file)
+	return file, nil
+}
+
+// resolveRelativePaths makes the paths in settings that refer to other files,
+// such as lint rule packs, relative to the directory of the config file.
+func resolveRelativePaths(file *configFile) {
+	dir := filepath.Dir(file.Path)
+	resolve := func(value interface{}) interface{} {
+		path, ok := value.(string)
+		if !ok || path == "" || filepath.IsAbs(path) {
+			return value
+		}
+		return filepath.Join(dir, path)
+	}
+
+	for _, key := range pathKeys {
+		switch value := file.Settings[key].(type) {
+		case []interface{}:
+			for i := range value {
+				value[i] = resolve(value[i])
+			}
+		case string:
+			file.Settings[key] = resolve(value)
+		}
+	}
+}
+
+// findProjectConfigDir looks for a project config file in the current
+// directory and each of its parents, returning the directory that contains it.
+// PORTER_HOME is skipped, so that the home config file is not read twice.
+func findProjectConfigDir(cfg *config.Config, home string) string {
+	dir := cfg.Getwd()
+	for {
+		configDir := filepath.Join(dir, ProjectConfigDir)
+		if filepath.Clean(configDir) != filepath.Clean(home) {
+			for _, ext := range viper.SupportedExts {
+				if exists, _ := cfg.FileSystem.Exists(filepath.Join(configDir, configName+"."+ext)); exists {
+					return configDir
+				}
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Setting is a configuration value and where it was set.
+type Setting struct {
+	Key    string      `json:"key" yaml:"key"`
+	Value  interface{} `json:"value" yaml:"value"`
+	Origin string      `json:"origin" yaml:"origin"`
+}
+
+// ListSettings returns the settings from the config files and environment
+// variables, sorted by key. The origin of each setting is the config file or
+// environment variable with the highest precedence that defined it.
+func ListSettings(cfg *config.Config) ([]Setting, error) {
+	files, err := readConfigFiles(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]Setting)
+	for _, file := range files {
+		for key, value := range file.Settings {
+			settings[key] = Setting{Key: key, Value: value, Origin: file.Path}
+		}
+	}
+
+	// Environment variables override the config files
+	for _, env := range cfg.Environ() {
+		pair := strings.SplitN(env, "=", 2)
+		if len(pair) != 2 || !strings.HasPrefix(pair[0], "PORTER_") {
+			continue
+		}
+		key := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(pair[0], "PORTER_"), "_", "-"))
+		if _, ok := settings[key]; !ok && !isKnownSetting(key) {
+			continue
+		}
+		settings[key] = Setting{Key: key, Value: pair[1], Origin: "env:" + pair[0]}
+	}
+
+	results := make([]Setting, 0, len(settings))
+	for _, setting := range settings {
+		results = append(results, setting)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Key < results[j].Key
+	})
+	return results, nil
+}
+
+// knownSettings are common settings that are reported when they are only set
+// with an environment variable. Other environment variables prefixed with
+// PORTER_, such as PORTER_HOME, are not settings.
+var knownSettings = []string{
+	"allow-docker-host-access",
+	"debug",
+	"debug-plugins",
+	"driver",
+	"ephemeral",
+	"mirror",
+	"output",
+	"parameter-set",
+	"read-only",
+}
+
+func isKnownSetting(key string) bool {
+	for _, s := range knownSettings {
+		if s == key {
+			return true
+		}
+	}
+	return false
+}
+
+func toEnvVar(flag string) string {
+	return fmt.Sprintf("PORTER_%s", strings.ToUpper(strings.ReplaceAll(flag, "-", "_")))
+}
+
+func viperBool(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	default:
+		return false
+	}
+}
@@ -167,3 +167,189 @@ func TestData_Marshal(t *testing.T) {
 	assert.Equal(t, "azure.keyvault", teamSource.PluginSubKey, "SecretSources.PluginSubKey was not loaded properly")
 	assert.Equal(t, map[string]interface{}{"vault": "teamsekrets"}, teamSource.Config, "SecretSources.Config was not loaded properly")
 }
+
+func TestFromConfigFile_ProjectConfig(t *testing.T) {
+	newConfig := func(t *testing.T, homeConfig string) *config.TestConfig {
+		c := config.NewTestConfig(t)
+		c.SetHomeDir("/root/.porter")
+		if homeConfig != "" {
+			c.TestContext.AddTestFileContents([]byte(homeConfig), "/root/.porter/config.toml")
+		}
+		c.TestContext.AddTestFile("testdata/project-config.toml", "/src/app/.porter/config.toml")
+		c.FileSystem.MkdirAll("/src/app/charts", 0755)
+		c.Chdir("/src/app/charts")
+		c.DataLoader = FromConfigFile
+		return c
+	}
+
+	t.Run("project overrides home", func(t *testing.T) {
+		c := newConfig(t, "default-secrets = \"home\"\ndefault-storage = \"home\"\n")
+		err := c.LoadData()
+		require.NoError(t, err, "dataloader failed")
+
+		assert.Equal(t, "home", c.Data.DefaultSecrets, "settings only in the home config should be kept")
+		assert.Equal(t, "home", c.Data.DefaultStorage, "the project config should not change storage unless allowed")
+		assert.Empty(t, c.Data.CrudStores, "the project config should not define storage unless allowed")
+		assert.Contains(t, c.TestContext.GetError(), "WARNING: ignoring default-storage in the project config file /src/app/.porter/config.toml")
+	})
+
+	t.Run("project storage allowed", func(t *testing.T) {
+		c := newConfig(t, "allow-project-storage = true\ndefault-storage = \"home\"\n")
+		err := c.LoadData()
+		require.NoError(t, err, "dataloader failed")
+
+		assert.Equal(t, "project", c.Data.DefaultStorage)
+		require.Len(t, c.Data.CrudStores, 1)
+		assert.Equal(t, "project", c.Data.CrudStores[0].Name)
+		assert.Empty(t, c.TestContext.GetError())
+	})
+
+	t.Run("project cannot allow storage", func(t *testing.T) {
+		c := newConfig(t, "")
+		c.TestContext.AddTestFileContents([]byte("allow-project-storage = true\ndefault-storage = \"project\"\n"), "/src/app/.porter/config.toml")
+		err := c.LoadData()
+		require.NoError(t, err, "dataloader failed")
+
+		assert.Empty(t, c.Data.DefaultStorage)
+		assert.Contains(t, c.TestContext.GetError(), "WARNING: ignoring allow-project-storage")
+	})
+
+	t.Run("lint rule packs are relative to the config file", func(t *testing.T) {
+		c := newConfig(t, "")
+		c.TestContext.AddTestFileContents([]byte(`lint-rule-packs = ["lint/acme.yaml", "/etc/porter/platform.yaml"]`), "/src/app/.porter/config.toml")
+		err := c.LoadData()
+		require.NoError(t, err, "dataloader failed")
+
+		assert.Equal(t, []string{"/src/app/.porter/lint/acme.yaml", "/etc/porter/platform.yaml"}, c.Data.LintRulePacks)
+	})
+
+	t.Run("project lint rule packs are added to the home rule packs", func(t *testing.T) {
+		c := newConfig(t, `lint-rule-packs = ["acme.yaml", "/etc/porter/platform.yaml"]`)
+		c.TestContext.AddTestFileContents([]byte(`lint-rule-packs = ["lint/app.yaml", "/etc/porter/platform.yaml"]`), "/src/app/.porter/config.toml")
+		err := c.LoadData()
+		require.NoError(t, err, "dataloader failed")
+
+		assert.Equal(t, []string{"/root/.porter/acme.yaml", "/etc/porter/platform.yaml", "/src/app/.porter/lint/app.yaml"}, c.Data.LintRulePacks,
+			"a project should not be able to replace the rule packs in the home config")
+	})
+
+	t.Run("project secrets, proxy and ca certificates not allowed", func(t *testing.T) {
+		c := newConfig(t, "default-secrets = \"home\"\n")
+		c.TestContext.AddTestFileContents([]byte(`default-secrets = "project"
+https-proxy = "http://attacker.example.com:3128"
+ca-certificates = ["certs/attacker.pem"]
+`), "/src/app/.porter/config.toml")
+		err := c.LoadData()
+		require.NoError(t, err, "dataloader failed")
+
+		assert.Equal(t, "home", c.Data.DefaultSecrets, "the project config should not change secrets unless allowed")
+		assert.Empty(t, c.Data.GetProxyEnv(), "the project config should not set a proxy unless allowed")
+		assert.Empty(t, c.Data.CACertificates, "the project config should not trust certificates unless allowed")
+		stderr := c.TestContext.GetError()
+		assert.Contains(t, stderr, "WARNING: ignoring default-secrets in the project config file")
+		assert.Contains(t, stderr, "WARNING: ignoring https-proxy in the project config file")
+		assert.Contains(t, stderr, "WARNING: ignoring ca-certificates in the project config file")
+	})
+
+	t.Run("proxy and ca certificates", func(t *testing.T) {
+		c := newConfig(t, "allow-project-storage = true\n")
+		c.TestContext.AddTestFileContents([]byte(`http-proxy = "http://proxy.example.com:3128"
+no-proxy = "localhost,.example.com"
+ca-certificates = ["certs/acme-root.pem"]
+`), "/src/app/.porter/config.toml")
+		err := c.LoadData()
+		require.NoError(t, err, "dataloader failed")
+
+		assert.Equal(t, []string{"/src/app/.porter/certs/acme-root.pem"}, c.Data.CACertificates)
+		assert.Equal(t, map[string]string{
+			"HTTP_PROXY": "http://proxy.example.com:3128",
+			"http_proxy": "http://proxy.example.com:3128",
+			"NO_PROXY":   "localhost,.example.com",
+			"no_proxy":   "localhost,.example.com",
+		}, c.Data.GetProxyEnv())
+	})
+}
+
+func TestFromFlagsThenEnvVarsThenConfigFile_ProjectConfig(t *testing.T) {
+	c := config.NewTestConfig(t)
+	c.SetHomeDir("/root/.porter")
+	c.TestContext.AddTestFile("testdata/config.toml", "/root/.porter/config.toml")
+	c.TestContext.AddTestFile("testdata/project-config.toml", "/src/app/.porter/config.toml")
+	c.Chdir("/src/app")
+
+	var driver string
+	var paramSets []string
+	cmd := &cobra.Command{}
+	cmd.Flags().BoolVar(&c.Debug, "debug", false, "debug")
+	cmd.Flags().StringVar(&driver, "driver", "docker", "driver")
+	cmd.Flags().StringSliceVar(&paramSets, "parameter-set", nil, "parameter sets")
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		return c.LoadData()
+	}
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return nil
+	}
+	c.DataLoader = FromFlagsThenEnvVarsThenConfigFile(cmd)
+
+	err := cmd.Execute()
+	require.NoError(t, err, "dataloader failed")
+	assert.False(t, c.Debug, "the project config should override the home config")
+	assert.Equal(t, "kubernetes", driver, "the project config should set the driver")
+	assert.Equal(t, []string{"dev-params", "team-params"}, paramSets, "each parameter set should be applied")
+}
+
+func TestFromFlagsThenEnvVarsThenConfigFile_ProjectConfigReadOnly(t *testing.T) {
+	c := config.NewTestConfig(t)
+	c.SetHomeDir("/root/.porter")
+	c.TestContext.AddTestFileContents([]byte("read-only = true\n"), "/root/.porter/config.toml")
+	c.TestContext.AddTestFileContents([]byte("read-only = false\nallow-docker-host-access = true\nno-proxy = \"*\"\n"), "/src/app/.porter/config.toml")
+	c.Chdir("/src/app")
+
+	var allowDockerHostAccess bool
+	cmd := &cobra.Command{}
+	cmd.Flags().BoolVar(&c.ReadOnly, "read-only", false, "read-only")
+	cmd.Flags().BoolVar(&allowDockerHostAccess, "allow-docker-host-access", false, "allow-docker-host-access")
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		return c.LoadData()
+	}
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return nil
+	}
+	c.DataLoader = FromFlagsThenEnvVarsThenConfigFile(cmd)
+
+	err := cmd.Execute()
+	require.NoError(t, err, "dataloader failed")
+	assert.True(t, c.ReadOnly, "the project config should not turn off read-only mode unless allowed")
+	assert.False(t, allowDockerHostAccess, "the project config should not allow access to the docker host unless allowed")
+	assert.Empty(t, c.Data.GetProxyEnv(), "the project config should not bypass the proxy unless allowed")
+	stderr := c.TestContext.GetError()
+	assert.Contains(t, stderr, "WARNING: ignoring read-only in the project config file")
+	assert.Contains(t, stderr, "WARNING: ignoring allow-docker-host-access in the project config file")
+	assert.Contains(t, stderr, "WARNING: ignoring no-proxy in the project config file")
+}
+
+func TestListSettings(t *testing.T) {
+	c := config.NewTestConfig(t)
+	c.SetHomeDir("/root/.porter")
+	c.TestContext.AddTestFile("testdata/config.toml", "/root/.porter/config.toml")
+	c.TestContext.AddTestFile("testdata/project-config.toml", "/src/app/.porter/config.toml")
+	c.Chdir("/src/app")
+	c.Setenv("PORTER_DRIVER", "azure")
+	c.Setenv("PORTER_OUTPUT", "json")
+	c.Setenv("PORTER_HOME", "/root/.porter")
+
+	settings, err := ListSettings(c.Config)
+	require.NoError(t, err)
+
+	origins := make(map[string]string, len(settings))
+	for _, s := range settings {
+		origins[s.Key] = s.Origin
+	}
+
+	assert.Equal(t, "/src/app/.porter/config.toml", origins["debug"])
+	assert.Equal(t, "/root/.porter/config.toml", origins["default-secrets"])
+	assert.Equal(t, "/root/.porter/config.toml", origins["default-storage"], "storage settings from the project config should be ignored")
+	assert.Equal(t, "env:PORTER_DRIVER", origins["driver"])
+	assert.Equal(t, "env:PORTER_OUTPUT", origins["output"])
+	assert.NotContains(t, origins, "home", "PORTER_HOME is not a setting")
+}
@@ -192,6 +192,23 @@ func (cw *CensoredWriter) SetSensitiveValues(vals []string) {
 	cw.sensitiveValues = vals
 }
 
+// AddSensitiveValues adds values needing masking for an CensoredWriter,
+// keeping the values that are already masked
+func (cw *CensoredWriter) AddSensitiveValues(vals []string) {
+	for _, val := range vals {
+		masked := false
+		for _, existing := range cw.sensitiveValues {
+			if existing == val {
+				masked = true
+				break
+			}
+		}
+		if !masked {
+			cw.sensitiveValues = append(cw.sensitiveValues, val)
+		}
+	}
+}
+
 // Write implements io.Writer's Write method, performing necessary auditing while doing so
 func (cw *CensoredWriter) Write(b []byte) (int, error) {
 	auditedBytes := b
@@ -275,3 +292,22 @@ func (c *Context) SetSensitiveValues(vals []string) {
 		c.Err = err
 	}
 }
+
+// AddSensitiveValues adds sensitive values needing masking on output/err
+// streams, keeping the values that are already masked. The streams are only
+// wrapped the first time that values are added.
+func (c *Context) AddSensitiveValues(vals []string) {
+	if len(vals) > 0 {
+		c.Out = addSensitiveValues(c.Out, vals)
+		c.Err = addSensitiveValues(c.Err, vals)
+	}
+}
+
+func addSensitiveValues(w io.Writer, vals []string) io.Writer {
+	cw, ok := w.(*CensoredWriter)
+	if !ok {
+		cw = NewCensoredWriter(w)
+	}
+	cw.AddSensitiveValues(vals)
+	return cw
+}
@@ -1,6 +1,7 @@
 package context
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,3 +26,15 @@ func TestContext_EnvironMap(t *testing.T) {
 	got["c"] = "3"
 	assert.Empty(t, c.Getenv("c"), "Expected to get a copy of the context's environment variables")
 }
+
+func TestContext_AddSensitiveValues(t *testing.T) {
+	c := NewTestContext(t)
+
+	c.AddSensitiveValues([]string{"topsecret"})
+	out := c.Out
+	c.AddSensitiveValues([]string{"topsecret", "password1"})
+	assert.Same(t, out, c.Out, "the output should only be wrapped once")
+
+	fmt.Fprintln(c.Out, "topsecret password1 public")
+	assert.Equal(t, "******* ******* public\n", c.GetOutput())
+}
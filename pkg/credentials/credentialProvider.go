@@ -8,7 +8,7 @@ import (
 // CredentialProvider interface for managing sets of credentials.
 type CredentialProvider interface {
 	CredentialStore
-	ResolveAll(creds credentials.CredentialSet) (valuesource.Set, error)
+	ResolveAll(creds credentials.CredentialSet, installation string) (valuesource.Set, error)
 	Validate(credentials.CredentialSet) error
 }
 
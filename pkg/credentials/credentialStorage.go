@@ -13,7 +13,6 @@ import (
 	"github.com/cnabio/cnab-go/secrets/host"
 	"github.com/cnabio/cnab-go/valuesource"
 	"github.com/hashicorp/go-multierror"
-	"github.com/pkg/errors"
 )
 
 type CredentialsStore = credentials.Store
@@ -38,20 +37,14 @@ func NewCredentialStorage(storage *storage.Manager) *CredentialStorage {
 	}
 }
 
-func (s CredentialStorage) ResolveAll(creds credentials.CredentialSet) (valuesource.Set, error) {
-	resolvedCreds := make(valuesource.Set)
-	var resolveErrors error
-
-	for _, cred := range creds.Credentials {
-		value, err := s.Resolve(cred.Source.Key, cred.Source.Value)
-		if err != nil {
-			resolveErrors = multierror.Append(resolveErrors, errors.Wrapf(err, "unable to resolve credential %s.%s from %s %s", creds.Name, cred.Name, cred.Source.Key, cred.Source.Value))
-		}
-
-		resolvedCreds[cred.Name] = value
+// ResolveAll resolves the value of each credential in the set for the specified
+// installation, expanding any templates in the credential sources.
+func (s CredentialStorage) ResolveAll(creds credentials.CredentialSet, installation string) (valuesource.Set, error) {
+	tc := secrets.TemplateContext{Installation: installation}
+	if s.Config != nil {
+		tc.Context = s.Context
 	}
-
-	return resolvedCreds, resolveErrors
+	return secrets.ResolveStrategies(s.SecretsStore, tc, "credential", creds.Name, creds.Credentials)
 }
 
 func (s CredentialStorage) Validate(creds credentials.CredentialSet) error {
@@ -30,6 +30,7 @@ func NewTestCredentialProvider(t *testing.T, tc *config.TestConfig) TestCredenti
 		TestConfig:  tc,
 		TestSecrets: backingSecrets,
 		CredentialStorage: &CredentialStorage{
+			Config:           tc.Config,
 			CredentialsStore: credStore,
 			SecretsStore:     secrets.NewSecretStore(backingSecrets),
 		},
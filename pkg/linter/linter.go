@@ -1,10 +1,13 @@
 package linter
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"strings"
 
+	"get.porter.sh/porter/pkg/build"
 	"get.porter.sh/porter/pkg/context"
 	"get.porter.sh/porter/pkg/manifest"
 	"get.porter.sh/porter/pkg/mixin/query"
@@ -43,6 +46,24 @@ const (
 	LevelWarning Level = 2
 )
 
+const (
+	// CodeSensitiveBuildArg is the code for a Dockerfile template that declares a
+	// build argument that looks like it is used to pass a secret.
+	CodeSensitiveBuildArg Code = "porter-100"
+
+	// CodeRootUser is the code for a Dockerfile template that switches to the
+	// root user when the bundle is configured to run as a non-root user.
+	CodeRootUser Code = "porter-101"
+
+	// CodeParameterAlias is the code for a template in the manifest that uses
+	// the previous name of a renamed parameter.
+	CodeParameterAlias Code = "porter-102"
+
+	// CodeUnknownLicense is the code for a license expression in the manifest
+	// that uses an identifier that is not on the SPDX license list.
+	CodeUnknownLicense Code = "porter-103"
+)
+
 // Result is a single item identified by the linter.
 type Result struct {
 	// Level of severity
@@ -81,7 +102,8 @@ func (r Result) String() string {
 	return buffer.String()
 }
 
-// Location identifies the offending mixin step within a manifest.
+// Location identifies the offending mixin step within a manifest, or the
+// offending line in another file used by the bundle, such as the Dockerfile template.
 type Location struct {
 	// Action containing the step, e.g. Install.
 	Action string
@@ -107,9 +129,24 @@ type Location struct {
 	//      description: THIS IS THE STEP DESCRIPTION
 	//      command: ./helper.sh
 	StepDescription string
+
+	// File containing the problem, when it is not in a mixin step.
+	File string `json:",omitempty"`
+
+	// Line number of the problem in the File, starting from 1.
+	Line int `json:",omitempty"`
+
+	// Path to the field in the manifest that has the problem, e.g. images.web.repository.
+	Path string `json:",omitempty"`
 }
 
 func (l Location) String() string {
+	if l.Path != "" {
+		return fmt.Sprintf("%s: %s", l.File, l.Path)
+	}
+	if l.File != "" {
+		return fmt.Sprintf("%s:%d", l.File, l.Line)
+	}
 	return fmt.Sprintf("%s: %s step in the %s mixin (%s)",
 		l.Action, humanize.Ordinal(l.StepNumber), l.Mixin, l.StepDescription)
 }
@@ -142,6 +179,9 @@ func (r Results) HasError() bool {
 type Linter struct {
 	*context.Context
 	Mixins pkgmgmt.PackageManager
+
+	// RulePacks are the organization rules evaluated against the manifest.
+	RulePacks []RulePack
 }
 
 func New(cxt *context.Context, mixins pkgmgmt.PackageManager) *Linter {
@@ -154,6 +194,19 @@ func New(cxt *context.Context, mixins pkgmgmt.PackageManager) *Linter {
 func (l *Linter) Lint(m *manifest.Manifest) (Results, error) {
 	// TODO: perform any porter level linting
 	// e.g. metadata, credentials, properties, outputs, dependencies, etc
+	results, err := l.lintDockerfile(m)
+	if err != nil {
+		return nil, err
+	}
+
+	results = append(results, l.lintParameterAliases(m)...)
+	results = append(results, l.lintLicense(m)...)
+
+	ruleResults, err := l.lintRulePacks(m)
+	if err != nil {
+		return nil, err
+	}
+	results = append(results, ruleResults...)
 
 	if l.Debug {
 		fmt.Fprintln(l.Err, "Running linters for each mixin used in the manifest...")
@@ -165,7 +218,6 @@ func (l *Linter) Lint(m *manifest.Manifest) (Results, error) {
 		return nil, err
 	}
 
-	var results Results
 	for mixin, response := range responses {
 		var r Results
 		err = json.Unmarshal([]byte(response), &r)
@@ -178,3 +230,129 @@ func (l *Linter) Lint(m *manifest.Manifest) (Results, error) {
 
 	return results, nil
 }
+
+// lintParameterAliases checks the templates in the manifest for parameters
+// that are referenced by an alias instead of their current name.
+func (l *Linter) lintParameterAliases(m *manifest.Manifest) Results {
+	aliases := make(map[string]string)
+	for _, param := range m.Parameters {
+		for _, alias := range param.Aliases {
+			aliases[alias] = param.Name
+		}
+	}
+	if len(aliases) == 0 {
+		return nil
+	}
+
+	var results Results
+	for _, tmplVar := range m.TemplateVariables {
+		if !strings.HasPrefix(tmplVar, "bundle.parameters.") {
+			continue
+		}
+		alias := strings.TrimPrefix(tmplVar, "bundle.parameters.")
+		name, ok := aliases[alias]
+		if !ok {
+			continue
+		}
+
+		results = append(results, Result{
+			Level:    LevelError,
+			Code:     CodeParameterAlias,
+			Location: Location{File: m.ManifestPath, Path: tmplVar},
+			Title:    fmt.Sprintf("Template uses the previous name of parameter %s", name),
+			Message: fmt.Sprintf(`The parameter %s was renamed to %s, and templates are only resolved with the current name of a parameter.
+Replace {{ bundle.parameters.%s }} with {{ bundle.parameters.%s }}.`, alias, name, alias, name),
+			URL: "https://porter.sh/author-bundles/#parameters",
+		})
+	}
+
+	return results
+}
+
+// lintLicense checks that the license expression in the manifest only uses
+// identifiers from the SPDX license list.
+func (l *Linter) lintLicense(m *manifest.Manifest) Results {
+	if m.License == "" {
+		return nil
+	}
+
+	var results Results
+	for _, id := range manifest.UnknownLicenses(m.License) {
+		results = append(results, Result{
+			Level:    LevelWarning,
+			Code:     CodeUnknownLicense,
+			Location: Location{File: m.ManifestPath, Path: "license"},
+			Title:    fmt.Sprintf("Unknown SPDX license identifier %s", id),
+			Message: fmt.Sprintf(`The license %s is not on the SPDX license list that porter knows. Check the spelling against
+https://spdx.org/licenses/, or use LicenseRef-NAME for a license that isn't on the SPDX list.`, id),
+			URL: "https://porter.sh/author-bundles/#bundle-metadata",
+		})
+	}
+	return results
+}
+
+// lintDockerfile checks the Dockerfile template for build arguments that look
+// like they are used to pass secrets, which are persisted in the image history,
+// and for instructions that switch to the root user when the bundle should run
+// as a non-root user.
+func (l *Linter) lintDockerfile(m *manifest.Manifest) (Results, error) {
+	if m.Dockerfile == "" {
+		return nil, nil
+	}
+
+	contents, err := l.FileSystem.ReadFile(m.Dockerfile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the Dockerfile template %s", m.Dockerfile)
+	}
+
+	var results Results
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		location := Location{
+			File: m.Dockerfile,
+			Line: lineNumber,
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "ARG":
+			name := strings.SplitN(fields[1], "=", 2)[0]
+			if !build.IsSensitiveBuildArg(name) {
+				continue
+			}
+
+			results = append(results, Result{
+				Level:    LevelWarning,
+				Code:     CodeSensitiveBuildArg,
+				Location: location,
+				Title:    fmt.Sprintf("Best Practice: Use a build secret instead of ARG %s", name),
+				Message: fmt.Sprintf(`Build arguments are persisted in the image history, so they should not be used to pass secrets.
+Pass the value with porter build --secret id=%s,src=PATH instead, and mount it in the
+Dockerfile template with RUN --mount=type=secret,id=%s.`, strings.ToLower(name), strings.ToLower(name)),
+				URL: "https://porter.sh/custom-dockerfile/#build-secrets",
+			})
+		case "USER":
+			user := strings.SplitN(fields[1], ":", 2)[0]
+			if !m.NonRoot || (user != "root" && user != "0") {
+				continue
+			}
+
+			results = append(results, Result{
+				Level:    LevelWarning,
+				Code:     CodeRootUser,
+				Location: location,
+				Title:    "Dockerfile template switches to the root user",
+				Message: fmt.Sprintf(`The bundle is configured to run as a non-root user, but the Dockerfile template switches to the root user.
+Files created after this instruction are owned by root and may not be writable by the user that runs the bundle.
+Switch back to the non-root user with USER %d, or remove the instruction.`, build.NonRootUID),
+				URL: "https://porter.sh/custom-dockerfile/#non-root-invocation-images",
+			})
+		}
+	}
+
+	return results, nil
+}
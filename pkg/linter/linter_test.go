@@ -70,4 +70,80 @@ func TestLinter_Lint(t *testing.T) {
 		require.Len(t, results, 0, "linter should ignore mixins that doesn't support the lint command")
 	})
 
+	t.Run("sensitive build arg in Dockerfile template", func(t *testing.T) {
+		cxt := context.NewTestContext(t)
+		mixins := mixin.NewTestMixinProvider()
+		l := New(cxt.Context, mixins)
+		cxt.AddTestFileContents([]byte("FROM debian:stretch-slim\n\nARG BUNDLE_DIR\nARG NPM_TOKEN=\n"), "Dockerfile.tmpl")
+		m := &manifest.Manifest{
+			Dockerfile: "Dockerfile.tmpl",
+		}
+
+		results, err := l.Lint(m)
+		require.NoError(t, err, "Lint failed")
+		require.Len(t, results, 1, "linter should have returned 1 result")
+		require.Equal(t, CodeSensitiveBuildArg, results[0].Code)
+		require.Equal(t, LevelWarning, results[0].Level)
+		require.Equal(t, "Dockerfile.tmpl:4", results[0].Location.String())
+	})
+
+	t.Run("root user in Dockerfile template", func(t *testing.T) {
+		cxt := context.NewTestContext(t)
+		mixins := mixin.NewTestMixinProvider()
+		l := New(cxt.Context, mixins)
+		cxt.AddTestFileContents([]byte("FROM debian:stretch-slim\n\nARG BUNDLE_DIR\nUSER app\nUSER root:root\n"), "Dockerfile.tmpl")
+		m := &manifest.Manifest{
+			Dockerfile: "Dockerfile.tmpl",
+		}
+
+		results, err := l.Lint(m)
+		require.NoError(t, err, "Lint failed")
+		require.Len(t, results, 0, "linter should ignore the root user when the bundle runs as root")
+
+		m.NonRoot = true
+		results, err = l.Lint(m)
+		require.NoError(t, err, "Lint failed")
+		require.Len(t, results, 1, "linter should have returned 1 result")
+		require.Equal(t, CodeRootUser, results[0].Code)
+		require.Equal(t, LevelWarning, results[0].Level)
+		require.Equal(t, "Dockerfile.tmpl:5", results[0].Location.String())
+	})
+
+	t.Run("parameter alias in template", func(t *testing.T) {
+		cxt := context.NewTestContext(t)
+		mixins := mixin.NewTestMixinProvider()
+		l := New(cxt.Context, mixins)
+		m := &manifest.Manifest{
+			ManifestPath: "porter.yaml",
+			Parameters: manifest.ParameterDefinitions{
+				"region": {Name: "region", Aliases: []string{"location"}},
+			},
+			TemplateVariables: []string{"bundle.parameters.region", "bundle.parameters.location"},
+		}
+
+		results, err := l.Lint(m)
+		require.NoError(t, err, "Lint failed")
+		require.Len(t, results, 1, "linter should have returned 1 result")
+		require.Equal(t, CodeParameterAlias, results[0].Code)
+		require.Equal(t, LevelError, results[0].Level)
+		require.Equal(t, "porter.yaml: bundle.parameters.location", results[0].Location.String())
+	})
+
+	t.Run("unknown license", func(t *testing.T) {
+		cxt := context.NewTestContext(t)
+		mixins := mixin.NewTestMixinProvider()
+		l := New(cxt.Context, mixins)
+		m := &manifest.Manifest{
+			ManifestPath: "porter.yaml",
+			License:      "MIT OR Apache2",
+		}
+
+		results, err := l.Lint(m)
+		require.NoError(t, err, "Lint failed")
+		require.Len(t, results, 1, "linter should have returned 1 result")
+		require.Equal(t, CodeUnknownLicense, results[0].Code)
+		require.Equal(t, LevelWarning, results[0].Level)
+		require.Equal(t, "Unknown SPDX license identifier Apache2", results[0].Title)
+		require.Equal(t, "porter.yaml: license", results[0].Location.String())
+	})
 }
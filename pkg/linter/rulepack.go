@@ -0,0 +1,407 @@
+package linter
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/context"
+	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/yaml"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/pkg/errors"
+)
+
+// RuleType is the kind of check that a rule performs.
+type RuleType string
+
+const (
+	// RuleRequired checks that the field is set and is not empty.
+	RuleRequired RuleType = "required"
+
+	// RulePattern checks that the value of the field matches a regular expression.
+	RulePattern RuleType = "pattern"
+
+	// RuleAllowlist checks that the value of the field is one of a set of allowed values.
+	RuleAllowlist RuleType = "allowlist"
+
+	// RuleSchema checks that the value of the field is valid against a json schema.
+	RuleSchema RuleType = "schema"
+)
+
+// RulePack is a set of rules, defined by an organization, that are evaluated
+// against the manifest when a bundle is linted.
+type RulePack struct {
+	// Name of the rule pack.
+	Name string `yaml:"name"`
+
+	// Rules in the pack.
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is a single check in a rule pack.
+type Rule struct {
+	// Code reported when the rule fails, e.g. acme-100.
+	Code Code `yaml:"code"`
+
+	// Type of check performed by the rule.
+	Type RuleType `yaml:"type"`
+
+	// Path to the fields checked by the rule, e.g. images.*.repository.
+	// Use * to match each item of a list or each value of a map.
+	Path string `yaml:"path"`
+
+	// Level of the result, error or warning. Defaults to error.
+	Level string `yaml:"level,omitempty"`
+
+	// Title to display when the rule fails.
+	Title string `yaml:"title,omitempty"`
+
+	// Message explaining how to fix the problem. Defaults to a description of the failed check.
+	Message string `yaml:"message,omitempty"`
+
+	// URL that provides additional assistance with the problem.
+	URL string `yaml:"url,omitempty"`
+
+	// Pattern that the value must match, for pattern rules.
+	Pattern string `yaml:"pattern,omitempty"`
+
+	// Values that are allowed, for allowlist rules.
+	Values []string `yaml:"values,omitempty"`
+
+	// Schema is a json schema that the value must be valid against, for schema rules.
+	Schema map[string]interface{} `yaml:"schema,omitempty"`
+
+	// pattern is the compiled Pattern.
+	pattern *regexp.Regexp
+}
+
+// LoadRulePacks reads the rule pack files and validates the rules.
+func LoadRulePacks(cxt *context.Context, paths []string) ([]RulePack, error) {
+	packs := make([]RulePack, 0, len(paths))
+	for _, path := range paths {
+		data, err := cxt.FileSystem.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read the lint rule pack %s", path)
+		}
+
+		var pack RulePack
+		if err = yaml.Unmarshal(data, &pack); err != nil {
+			return nil, errors.Wrapf(err, "could not parse the lint rule pack %s", path)
+		}
+		if pack.Name == "" {
+			pack.Name = path
+		}
+
+		for i := range pack.Rules {
+			if err = pack.Rules[i].validate(); err != nil {
+				return nil, errors.Wrapf(err, "invalid rule %d in the lint rule pack %s", i+1, path)
+			}
+		}
+		packs = append(packs, pack)
+	}
+	return packs, nil
+}
+
+func (r *Rule) validate() error {
+	if r.Code == "" {
+		return errors.New("code is required")
+	}
+	if r.Path == "" {
+		return errors.Errorf("path is required for rule %s", r.Code)
+	}
+	if _, err := r.getLevel(); err != nil {
+		return err
+	}
+
+	switch r.Type {
+	case RuleRequired:
+	case RulePattern:
+		var err error
+		if r.pattern, err = regexp.Compile(r.Pattern); err != nil {
+			return errors.Wrapf(err, "invalid pattern for rule %s", r.Code)
+		}
+	case RuleAllowlist:
+		if len(r.Values) == 0 {
+			return errors.Errorf("values are required for allowlist rule %s", r.Code)
+		}
+	case RuleSchema:
+		if r.Schema == nil {
+			return errors.Errorf("schema is required for schema rule %s", r.Code)
+		}
+		if _, err := r.validateSchema(nil); err != nil {
+			return errors.Wrapf(err, "invalid schema for rule %s", r.Code)
+		}
+	default:
+		return errors.Errorf("invalid type %q for rule %s, allowed values are: %s, %s, %s, %s",
+			r.Type, r.Code, RuleRequired, RulePattern, RuleAllowlist, RuleSchema)
+	}
+	return nil
+}
+
+func (r *Rule) getLevel() (Level, error) {
+	switch r.Level {
+	case "", "error":
+		return LevelError, nil
+	case "warning":
+		return LevelWarning, nil
+	}
+	return LevelError, errors.Errorf("invalid level %q for rule %s, allowed values are: error, warning", r.Level, r.Code)
+}
+
+// lintRulePacks evaluates the rules in each rule pack against the manifest.
+func (l *Linter) lintRulePacks(m *manifest.Manifest) (Results, error) {
+	if len(l.RulePacks) == 0 {
+		return nil, nil
+	}
+
+	doc, err := toRuleDocument(m)
+	if err != nil {
+		return nil, err
+	}
+
+	file := m.ManifestPath
+	if file == "" {
+		file = config.Name
+	}
+
+	var results Results
+	for _, pack := range l.RulePacks {
+		for _, rule := range pack.Rules {
+			for _, match := range matchPath(doc, rule.Path) {
+				message, err := rule.evaluate(match)
+				if err != nil {
+					return nil, errors.Wrapf(err, "could not evaluate rule %s from the lint rule pack %s", rule.Code, pack.Name)
+				}
+				if message == "" {
+					continue
+				}
+
+				level, _ := rule.getLevel()
+				title := rule.Title
+				if title == "" {
+					title = fmt.Sprintf("%s: %s rule for %s failed", pack.Name, rule.Type, rule.Path)
+				}
+				if rule.Message != "" {
+					message = rule.Message
+				}
+				results = append(results, Result{
+					Level:    level,
+					Code:     rule.Code,
+					Location: Location{File: file, Path: match.Path},
+					Title:    title,
+					Message:  message,
+					URL:      rule.URL,
+				})
+			}
+		}
+	}
+	return results, nil
+}
+
+// evaluate the rule against a matched field, returning a message describing
+// the problem when the rule fails.
+func (r *Rule) evaluate(match pathMatch) (string, error) {
+	if r.Type == RuleRequired {
+		if !match.Found || isEmpty(match.Value) {
+			return fmt.Sprintf("%s is required", match.Path), nil
+		}
+		return "", nil
+	}
+
+	// The remaining rules only check fields that are set
+	if !match.Found || match.Value == nil {
+		return "", nil
+	}
+
+	switch r.Type {
+	case RulePattern:
+		pattern := r.pattern
+		if pattern == nil {
+			var err error
+			if pattern, err = regexp.Compile(r.Pattern); err != nil {
+				return "", err
+			}
+		}
+		value := fmt.Sprintf("%v", match.Value)
+		if !pattern.MatchString(value) {
+			return fmt.Sprintf("%s is %q, which does not match %s", match.Path, value, r.Pattern), nil
+		}
+	case RuleAllowlist:
+		value := fmt.Sprintf("%v", match.Value)
+		for _, allowed := range r.Values {
+			if value == allowed {
+				return "", nil
+			}
+		}
+		return fmt.Sprintf("%s is %q, which is not one of the allowed values: %s", match.Path, value, strings.Join(r.Values, ", ")), nil
+	case RuleSchema:
+		valErrs, err := r.validateSchema(match.Value)
+		if err != nil {
+			return "", err
+		}
+		if len(valErrs) > 0 {
+			return fmt.Sprintf("%s is invalid: %s", match.Path, strings.Join(valErrs, ", ")), nil
+		}
+	}
+	return "", nil
+}
+
+// validateSchema validates the value against the rule's json schema,
+// returning the validation errors.
+func (r *Rule) validateSchema(value interface{}) ([]string, error) {
+	schemaB, err := json.Marshal(r.Schema)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal the schema")
+	}
+	schema := definition.NewRootSchema()
+	if err = json.Unmarshal(schemaB, schema); err != nil {
+		return nil, errors.Wrap(err, "could not load the schema")
+	}
+
+	valueB, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal the value")
+	}
+	keyErrs, err := schema.ValidateBytes(gocontext.Background(), valueB)
+	if err != nil {
+		return nil, err
+	}
+
+	valErrs := make([]string, len(keyErrs))
+	for i, keyErr := range keyErrs {
+		valErrs[i] = keyErr.Message
+	}
+	return valErrs, nil
+}
+
+// toRuleDocument converts the manifest into the document that rules are
+// evaluated against. The document has the same structure as porter.yaml,
+// except that each mixin is represented as a name and config, so that rules
+// can check the mixin names with mixins.*.name.
+func toRuleDocument(m *manifest.Manifest) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal the manifest")
+	}
+
+	var doc map[string]interface{}
+	if err = yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal the manifest")
+	}
+
+	mixins := make([]interface{}, len(m.Mixins))
+	for i, mixin := range m.Mixins {
+		decl := map[string]interface{}{"name": mixin.Name}
+		if mixin.Config != nil {
+			decl["config"] = mixin.Config
+		}
+		mixins[i] = decl
+	}
+	doc["mixins"] = mixins
+
+	return doc, nil
+}
+
+// pathMatch is a field in the document that matched a rule path.
+type pathMatch struct {
+	// Path to the field, with any wildcards replaced.
+	Path string
+
+	// Value of the field.
+	Value interface{}
+
+	// Found indicates that the field is set.
+	Found bool
+}
+
+// matchPath finds the fields in the document that match the path. Fields
+// that are missing are returned with Found set to false, so that required
+// rules can report them.
+func matchPath(doc interface{}, path string) []pathMatch {
+	matches := []pathMatch{{Value: doc, Found: true}}
+	for _, segment := range strings.Split(path, ".") {
+		var next []pathMatch
+		for _, match := range matches {
+			if !match.Found {
+				next = append(next, pathMatch{Path: joinPath(match.Path, segment)})
+				continue
+			}
+
+			switch value := match.Value.(type) {
+			case map[string]interface{}:
+				if segment == "*" {
+					keys := make([]string, 0, len(value))
+					for key := range value {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						next = append(next, pathMatch{Path: joinPath(match.Path, key), Value: value[key], Found: true})
+					}
+					continue
+				}
+
+				item, ok := value[segment]
+				next = append(next, pathMatch{Path: joinPath(match.Path, segment), Value: item, Found: ok})
+			case []interface{}:
+				if segment == "*" {
+					for i, item := range value {
+						next = append(next, pathMatch{Path: indexPath(match.Path, i, item), Value: item, Found: true})
+					}
+					continue
+				}
+
+				i, err := strconv.Atoi(segment)
+				if err != nil || i < 0 || i >= len(value) {
+					next = append(next, pathMatch{Path: joinPath(match.Path, segment)})
+					continue
+				}
+				next = append(next, pathMatch{Path: indexPath(match.Path, i, value[i]), Value: value[i], Found: true})
+			default:
+				// Wildcards do not match anything in a scalar value
+				if segment != "*" {
+					next = append(next, pathMatch{Path: joinPath(match.Path, segment)})
+				}
+			}
+		}
+		matches = next
+	}
+	return matches
+}
+
+func joinPath(parent string, segment string) string {
+	if parent == "" {
+		return segment
+	}
+	return parent + "." + segment
+}
+
+// indexPath identifies an item in a list by its name, when it has one, or
+// otherwise by its position.
+func indexPath(parent string, i int, item interface{}) string {
+	if m, ok := item.(map[string]interface{}); ok {
+		if name, ok := m["name"].(string); ok && name != "" {
+			return fmt.Sprintf("%s[%s]", parent, name)
+		}
+	}
+	return fmt.Sprintf("%s[%d]", parent, i)
+}
+
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package linter
+
+import (
+	"testing"
+
+	"get.porter.sh/porter/pkg/context"
+	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/mixin"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinter_Lint_RulePacks(t *testing.T) {
+	cxt := context.NewTestContext(t)
+	cxt.AddTestFile("testdata/acme-rules.yaml", "/etc/porter/acme-rules.yaml")
+	packs, err := LoadRulePacks(cxt.Context, []string{"/etc/porter/acme-rules.yaml"})
+	require.NoError(t, err, "LoadRulePacks failed")
+
+	mixins := mixin.NewTestMixinProvider()
+	mixins.LintResults = nil
+	l := New(cxt.Context, mixins)
+	l.RulePacks = packs
+
+	m := &manifest.Manifest{
+		ManifestPath: "porter.yaml",
+		Name:         "mybuns",
+		Mixins:       []manifest.MixinDeclaration{{Name: "exec"}, {Name: "terraform"}},
+		Parameters: manifest.ParameterDefinitions{
+			"db-password":    {Name: "db-password", Schema: definition.Schema{Type: "string"}},
+			"admin-password": {Name: "admin-password", Sensitive: true, Schema: definition.Schema{Type: "string"}},
+			"region":         {Name: "region", Schema: definition.Schema{Type: "string"}},
+		},
+		ImageMap: map[string]manifest.MappedImage{
+			"approved": {
+				Repository: "registry.example.com/web",
+				Digest:     "sha256:8f1133d81f1b078c865cdb11d17d1ff15f55c449d3eecca50190eed0f5e5e26f",
+			},
+			"unapproved": {Repository: "docker.io/library/nginx", Tag: "1.19"},
+		},
+	}
+
+	results, err := l.Lint(m)
+	require.NoError(t, err, "Lint failed")
+
+	got := make(map[string]Result, len(results))
+	for _, r := range results {
+		got[string(r.Code)+" "+r.Location.Path] = r
+	}
+	wantKeys := []string{
+		"acme-100 description",
+		"acme-101 maintainers",
+		"acme-102 images.unapproved.repository",
+		"acme-103 mixins[terraform].name",
+		"acme-104 parameters[db-password]",
+		"acme-105 images.unapproved.digest",
+	}
+	for _, key := range wantKeys {
+		assert.Contains(t, got, key)
+	}
+	assert.Len(t, results, len(wantKeys), "unexpected lint results: %v", results)
+
+	r := got["acme-100 description"]
+	assert.Equal(t, LevelError, r.Level)
+	assert.Equal(t, "Bundles must have a description", r.Title)
+	assert.Equal(t, "description is required", r.Message)
+	assert.Equal(t, "https://example.com/bundle-standards#description", r.URL)
+	assert.Equal(t, "porter.yaml: description", r.Location.String())
+
+	assert.Equal(t, "Copy the image to registry.example.com before referencing it.", got["acme-102 images.unapproved.repository"].Message,
+		"the message from the rule pack should be used")
+	assert.Equal(t, `mixins[terraform].name is "terraform", which is not one of the allowed values: exec, helm3`, got["acme-103 mixins[terraform].name"].Message)
+	assert.Equal(t, LevelWarning, got["acme-105 images.unapproved.digest"].Level)
+	assert.True(t, results.HasError())
+}
+
+func TestLoadRulePacks_Invalid(t *testing.T) {
+	testcases := []struct {
+		name    string
+		pack    string
+		wantErr string
+	}{
+		{"missing code", "rules:\n- type: required\n  path: description\n", "code is required"},
+		{"missing path", "rules:\n- code: acme-100\n  type: required\n", "path is required for rule acme-100"},
+		{"invalid type", "rules:\n- code: acme-100\n  type: forbidden\n  path: description\n", `invalid type "forbidden" for rule acme-100`},
+		{"invalid level", "rules:\n- code: acme-100\n  type: required\n  path: description\n  level: info\n", `invalid level "info" for rule acme-100`},
+		{"invalid pattern", "rules:\n- code: acme-100\n  type: pattern\n  path: description\n  pattern: '('\n", "invalid pattern for rule acme-100"},
+		{"missing values", "rules:\n- code: acme-100\n  type: allowlist\n  path: mixins.*.name\n", "values are required for allowlist rule acme-100"},
+		{"missing schema", "rules:\n- code: acme-100\n  type: schema\n  path: parameters.*\n", "schema is required for schema rule acme-100"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cxt := context.NewTestContext(t)
+			cxt.AddTestFileContents([]byte(tc.pack), "rules.yaml")
+
+			_, err := LoadRulePacks(cxt.Context, []string{"rules.yaml"})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid rule 1 in the lint rule pack rules.yaml")
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	doc := map[string]interface{}{
+		"name": "mybuns",
+		"parameters": []interface{}{
+			map[string]interface{}{"name": "color"},
+			map[string]interface{}{"type": "string"},
+		},
+	}
+
+	matches := matchPath(doc, "parameters.*.description")
+	require.Len(t, matches, 2)
+	assert.Equal(t, pathMatch{Path: "parameters[color].description"}, matches[0], "missing fields should be matched as not found")
+	assert.Equal(t, pathMatch{Path: "parameters[1].description"}, matches[1], "items without a name should be identified by position")
+
+	matches = matchPath(doc, "parameters.0.name")
+	assert.Equal(t, []pathMatch{{Path: "parameters[color].name", Value: "color", Found: true}}, matches)
+
+	matches = matchPath(doc, "name.*")
+	assert.Empty(t, matches, "wildcards should not match scalar values")
+}
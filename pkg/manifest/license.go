@@ -0,0 +1,205 @@
+package manifest
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// spdxLicenses is the set of SPDX license identifiers that porter knows,
+// indexed by their lowercase form since identifiers are matched
+// case-insensitively. Identifiers that are not in the set, such as licenses
+// added to the SPDX list after this one, are reported by porter lint as a
+// warning instead of failing validation.
+// See https://spdx.org/licenses/
+var spdxLicenses = toLowerSet(
+	"0BSD", "AFL-1.1", "AFL-1.2", "AFL-2.0", "AFL-2.1", "AFL-3.0",
+	"AGPL-1.0-only", "AGPL-1.0-or-later", "AGPL-3.0-only", "AGPL-3.0-or-later",
+	"Apache-1.0", "Apache-1.1", "Apache-2.0", "APSL-1.0", "APSL-1.1", "APSL-1.2", "APSL-2.0",
+	"Artistic-1.0", "Artistic-1.0-Perl", "Artistic-2.0",
+	"BlueOak-1.0.0", "BSD-1-Clause", "BSD-2-Clause", "BSD-2-Clause-Patent", "BSD-3-Clause",
+	"BSD-3-Clause-Clear", "BSD-3-Clause-LBNL", "BSD-4-Clause", "BSL-1.0", "BUSL-1.1",
+	"CAL-1.0", "CC-BY-1.0", "CC-BY-2.0", "CC-BY-2.5", "CC-BY-3.0", "CC-BY-4.0",
+	"CC-BY-NC-4.0", "CC-BY-NC-ND-4.0", "CC-BY-NC-SA-4.0", "CC-BY-ND-4.0", "CC-BY-SA-3.0",
+	"CC-BY-SA-4.0", "CC0-1.0", "CDDL-1.0", "CDDL-1.1", "CECILL-2.1", "CPAL-1.0", "CPL-1.0",
+	"ECL-1.0", "ECL-2.0", "EFL-1.0", "EFL-2.0", "Elastic-2.0", "EPL-1.0", "EPL-2.0",
+	"EUPL-1.0", "EUPL-1.1", "EUPL-1.2", "GFDL-1.3-only", "GFDL-1.3-or-later",
+	"GPL-1.0-only", "GPL-1.0-or-later", "GPL-2.0-only", "GPL-2.0-or-later",
+	"GPL-3.0-only", "GPL-3.0-or-later", "HPND", "ICU", "IPA", "IPL-1.0", "ISC",
+	"LGPL-2.0-only", "LGPL-2.0-or-later", "LGPL-2.1-only", "LGPL-2.1-or-later",
+	"LGPL-3.0-only", "LGPL-3.0-or-later", "LPL-1.02", "LPPL-1.3c", "MIT", "MIT-0",
+	"MPL-1.0", "MPL-1.1", "MPL-2.0", "MPL-2.0-no-copyleft-exception", "MS-PL", "MS-RL",
+	"MulanPSL-2.0", "NCSA", "ODbL-1.0", "OFL-1.1", "OpenSSL", "OSL-1.0", "OSL-2.0",
+	"OSL-2.1", "OSL-3.0", "PHP-3.0", "PHP-3.01", "PostgreSQL", "Python-2.0", "QPL-1.0",
+	"RPL-1.5", "RPSL-1.0", "Ruby", "SISSL", "SSPL-1.0", "Sleepycat", "Unicode-DFS-2016",
+	"Unlicense", "UPL-1.0", "Vim", "W3C", "WTFPL", "X11", "Zlib", "ZPL-2.0", "ZPL-2.1",
+	// Deprecated identifiers that are still commonly used
+	"AGPL-3.0", "GPL-2.0", "GPL-2.0+", "GPL-3.0", "GPL-3.0+", "LGPL-2.1", "LGPL-2.1+",
+	"LGPL-3.0", "LGPL-3.0+",
+)
+
+// spdxExceptions is the set of SPDX license exceptions that may follow WITH
+// in a license expression.
+// See https://spdx.org/licenses/exceptions-index.html
+var spdxExceptions = toLowerSet(
+	"389-exception", "Autoconf-exception-2.0", "Autoconf-exception-3.0", "Bison-exception-2.2",
+	"Classpath-exception-2.0", "GCC-exception-2.0", "GCC-exception-3.1", "LLVM-exception",
+	"Linux-syscall-note", "OCaml-LGPL-linking-exception", "OpenJDK-assembly-exception-1.0",
+	"Qt-LGPL-exception-1.1", "Universal-FOSS-exception-1.0", "WxWindows-exception-3.1",
+)
+
+// customLicenseRegex matches user defined license references, which SPDX
+// allows for licenses that are not on the list.
+var customLicenseRegex = regexp.MustCompile(`^(DocumentRef-[A-Za-z0-9.-]+:)?LicenseRef-[A-Za-z0-9.-]+$`)
+
+func toLowerSet(values ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[strings.ToLower(v)] = struct{}{}
+	}
+	return set
+}
+
+// ValidateLicense checks that the specified value is a valid SPDX license
+// expression, such as "MIT" or "(Apache-2.0 OR MIT)". The license identifiers
+// are not checked against the SPDX list, see UnknownLicenses.
+func ValidateLicense(expression string) error {
+	p := &licenseParser{tokens: tokenizeLicense(expression)}
+	if len(p.tokens) == 0 {
+		return errors.New("empty license expression")
+	}
+
+	if err := p.parseOr(); err != nil {
+		return err
+	}
+
+	if tok, ok := p.peek(); ok {
+		return errors.Errorf("unexpected %q", tok)
+	}
+	return nil
+}
+
+// UnknownLicenses returns the license identifiers and exceptions in a license
+// expression that porter does not recognize, which are either typos or
+// identifiers that were added to the SPDX list recently.
+func UnknownLicenses(expression string) []string {
+	p := &licenseParser{tokens: tokenizeLicense(expression)}
+	p.parseOr()
+	return p.unknown
+}
+
+func tokenizeLicense(expression string) []string {
+	expression = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression)
+	return strings.Fields(expression)
+}
+
+// licenseParser is a recursive descent parser for SPDX license expressions.
+// See https://spdx.github.io/spdx-spec/appendix-IV-SPDX-license-expressions/
+type licenseParser struct {
+	tokens []string
+	pos    int
+
+	// unknown are the identifiers and exceptions that are not on the SPDX list.
+	unknown []string
+}
+
+func (p *licenseParser) peek() (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *licenseParser) next() (string, bool) {
+	tok, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return tok, ok
+}
+
+func (p *licenseParser) parseOr() error {
+	if err := p.parseAnd(); err != nil {
+		return err
+	}
+	for {
+		if tok, _ := p.peek(); tok != "OR" {
+			return nil
+		}
+		p.pos++
+		if err := p.parseAnd(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *licenseParser) parseAnd() error {
+	if err := p.parseWith(); err != nil {
+		return err
+	}
+	for {
+		if tok, _ := p.peek(); tok != "AND" {
+			return nil
+		}
+		p.pos++
+		if err := p.parseWith(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *licenseParser) parseWith() error {
+	tok, ok := p.next()
+	if !ok {
+		return errors.New("unexpected end of expression")
+	}
+
+	if tok == "(" {
+		if err := p.parseOr(); err != nil {
+			return err
+		}
+		if tok, _ := p.next(); tok != ")" {
+			return errors.New("missing closing parenthesis")
+		}
+		return nil
+	}
+
+	switch tok {
+	case "AND", "OR", "WITH", ")":
+		return errors.Errorf("unexpected %q", tok)
+	}
+	if !isKnownLicense(tok) {
+		p.unknown = append(p.unknown, tok)
+	}
+
+	if tok, _ := p.peek(); tok == "WITH" {
+		p.pos++
+		exception, ok := p.next()
+		if !ok {
+			return errors.New("missing license exception after WITH")
+		}
+		if _, ok := spdxExceptions[strings.ToLower(exception)]; !ok {
+			p.unknown = append(p.unknown, exception)
+		}
+	}
+	return nil
+}
+
+func isKnownLicense(id string) bool {
+	if customLicenseRegex.MatchString(id) {
+		return true
+	}
+
+	if _, ok := spdxLicenses[strings.ToLower(id)]; ok {
+		return true
+	}
+
+	// The + suffix means "this version or later"
+	if strings.HasSuffix(id, "+") {
+		if _, ok := spdxLicenses[strings.ToLower(strings.TrimSuffix(id, "+"))]; ok {
+			return true
+		}
+	}
+	return false
+}
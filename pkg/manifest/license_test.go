@@ -0,0 +1,62 @@
+package manifest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLicense(t *testing.T) {
+	testcases := []struct {
+		license string
+		wantErr string
+	}{
+		{license: "MIT"},
+		{license: "apache-2.0"},
+		{license: "GPL-2.0+"},
+		{license: "LGPL-2.1-or-later"},
+		{license: "LicenseRef-Contoso-Proprietary"},
+		{license: "Apache-2.0 OR MIT"},
+		{license: "(MIT AND BSD-3-Clause) OR Apache-2.0"},
+		{license: "GPL-2.0-only WITH Classpath-exception-2.0"},
+		{license: "", wantErr: "empty license expression"},
+		{license: "MyLicense"},
+		{license: "MIT OR", wantErr: "unexpected end of expression"},
+		{license: "MIT Apache-2.0", wantErr: `unexpected "Apache-2.0"`},
+		{license: "(MIT OR Apache-2.0", wantErr: "missing closing parenthesis"},
+		{license: "GPL-2.0-only WITH", wantErr: "missing license exception after WITH"},
+		{license: "GPL-2.0-only WITH oops"},
+		{license: "AND MIT", wantErr: `unexpected "AND"`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.license, func(t *testing.T) {
+			err := ValidateLicense(tc.license)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestUnknownLicenses(t *testing.T) {
+	testcases := []struct {
+		license string
+		want    []string
+	}{
+		{license: "Apache-2.0 OR MIT"},
+		{license: "LicenseRef-Contoso-Proprietary"},
+		{license: "Apache2", want: []string{"Apache2"}},
+		{license: "(MIT AND Foo-1.0) OR GPL-2.0-only WITH oops", want: []string{"Foo-1.0", "oops"}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.license, func(t *testing.T) {
+			assert.Equal(t, tc.want, UnknownLicenses(tc.license))
+		})
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"io/ioutil"
 	"net/http"
 	"path"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"sort"
 	"strings"
 
+	"get.porter.sh/porter/pkg/cnab/extensions"
 	"get.porter.sh/porter/pkg/context"
+	"get.porter.sh/porter/pkg/vcs"
 	"get.porter.sh/porter/pkg/yaml"
 	"github.com/Masterminds/semver/v3"
 	"github.com/cbroglie/mustache"
@@ -22,7 +25,13 @@ import (
 	"github.com/pkg/errors"
 )
 
-const invalidStepErrorFormat = "validation of action \"%s\" failed"
+const (
+	invalidStepErrorFormat = "validation of action \"%s\" failed"
+
+	// VersionAuto is the special version value that derives the bundle version
+	// from the git tags of the repository containing the manifest.
+	VersionAuto = "auto"
+)
 
 type Manifest struct {
 	// ManifestPath is location to the original, user-supplied manifest, such as the path on the filesystem or a url
@@ -35,6 +44,18 @@ type Manifest struct {
 	Description string `yaml:"description,omitempty"`
 	Version     string `yaml:"version,omitempty"`
 
+	// Maintainers is the list of people responsible for the bundle
+	Maintainers []MaintainerDefinition `yaml:"maintainers,omitempty"`
+
+	// License is the SPDX license expression for the bundle, e.g. Apache-2.0
+	License string `yaml:"license,omitempty"`
+
+	// Keywords are used to categorize the bundle in search results and catalogs
+	Keywords []string `yaml:"keywords,omitempty"`
+
+	// Links are urls to additional information about the bundle
+	Links *LinksDefinition `yaml:"links,omitempty"`
+
 	// Registry is the OCI registry and org/subdomain for the bundle
 	Registry string `yaml:"registry,omitempty"`
 
@@ -59,6 +80,9 @@ type Manifest struct {
 	// Dockerfile is the relative path to the Dockerfile template for the invocation image
 	Dockerfile string `yaml:"dockerfile,omitempty"`
 
+	// NonRoot runs the invocation image as an unprivileged user instead of root
+	NonRoot bool `yaml:"nonRoot,omitempty"`
+
 	Mixins []MixinDeclaration `yaml:"mixins,omitempty"`
 
 	Install   Steps `yaml:"install"`
@@ -79,6 +103,9 @@ type Manifest struct {
 	ImageMap map[string]MappedImage `yaml:"images,omitempty"`
 
 	Required []RequiredExtension `yaml:"required,omitempty"`
+
+	// Publish configures where the bundle is published
+	Publish *PublishDefinition `yaml:"publish,omitempty"`
 }
 
 func (m *Manifest) Validate(cxt *context.Context) error {
@@ -132,6 +159,11 @@ func (m *Manifest) Validate(cxt *context.Context) error {
 		}
 	}
 
+	err = m.validateOptionalDependencies()
+	if err != nil {
+		result = multierror.Append(result, err)
+	}
+
 	for _, output := range m.Outputs {
 		err = output.Validate()
 		if err != nil {
@@ -139,6 +171,13 @@ func (m *Manifest) Validate(cxt *context.Context) error {
 		}
 	}
 
+	for _, cred := range m.Credentials {
+		err = cred.Validate()
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
 	for _, parameter := range m.Parameters {
 		err = parameter.Validate()
 		if err != nil {
@@ -146,6 +185,11 @@ func (m *Manifest) Validate(cxt *context.Context) error {
 		}
 	}
 
+	err = m.validateParameterAliases()
+	if err != nil {
+		result = multierror.Append(result, err)
+	}
+
 	for _, image := range m.ImageMap {
 		err = image.Validate()
 		if err != nil {
@@ -153,6 +197,13 @@ func (m *Manifest) Validate(cxt *context.Context) error {
 		}
 	}
 
+	if m.Publish != nil {
+		err = m.Publish.Validate()
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
 	return result
 }
 
@@ -183,6 +234,14 @@ func (m *Manifest) validateMetadata(cxt *context.Context) error {
 		}
 	}
 
+	if m.Version == VersionAuto {
+		v, err := m.DeriveVersion(cxt)
+		if err != nil {
+			return err
+		}
+		m.Version = v
+	}
+
 	// Allow for the user to have specified the version with a leading v prefix but save it as
 	// proper semver
 	if m.Version != "" {
@@ -192,9 +251,115 @@ func (m *Manifest) validateMetadata(cxt *context.Context) error {
 		}
 		m.Version = v.String()
 	}
+
+	for i, maintainer := range m.Maintainers {
+		if maintainer.Name == "" {
+			return errors.Errorf("maintainer %d must have a name", i)
+		}
+	}
+
+	if m.License != "" {
+		if err := ValidateLicense(m.License); err != nil {
+			return errors.Wrapf(err, "license %q is not a valid SPDX license expression", m.License)
+		}
+	}
+
 	return nil
 }
 
+// DeriveVersion returns a semantic version based on the git tags of the
+// repository containing the manifest.
+func (m *Manifest) DeriveVersion(cxt *context.Context) (string, error) {
+	if strings.HasPrefix(m.ManifestPath, "http://") || strings.HasPrefix(m.ManifestPath, "https://") {
+		return "", errors.Errorf("version: %s is only supported for manifests in a local git repository", VersionAuto)
+	}
+
+	dir := filepath.Dir(cxt.FileSystem.Abs(m.ManifestPath))
+	info, err := vcs.Inspect(cxt, dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not derive the bundle version from git for version: %s", VersionAuto)
+	}
+
+	return info.Version()
+}
+
+// MaintainerDefinition is a person responsible for the bundle.
+type MaintainerDefinition struct {
+	Name  string `yaml:"name"`
+	Email string `yaml:"email,omitempty"`
+	URL   string `yaml:"url,omitempty"`
+}
+
+// LinksDefinition contains urls to additional information about the bundle.
+type LinksDefinition struct {
+	// Source is the url of the bundle's source code
+	Source string `yaml:"source,omitempty"`
+
+	// Documentation is the url of the bundle's documentation
+	Documentation string `yaml:"documentation,omitempty"`
+}
+
+// PublishDefinition configures where the bundle is published.
+type PublishDefinition struct {
+	// References are the bundle references, in the format REGISTRY/NAME:TAG,
+	// where the bundle is published. They may be templated with the
+	// bundle's name and version, e.g. example.com/mybun:v{{ bundle.version }}.
+	References []string `yaml:"references,omitempty"`
+}
+
+// publishTemplateVariables are the variables that can be used in the
+// templating of a publish reference.
+var publishTemplateVariables = map[string]struct{}{
+	"bundle.name":    {},
+	"bundle.version": {},
+}
+
+// Validate checks that the publish references only use supported template variables.
+func (pd *PublishDefinition) Validate() error {
+	var result *multierror.Error
+	for _, ref := range pd.References {
+		const disableHtmlEscaping = true
+		tmpl, err := mustache.ParseStringRaw(ref, disableHtmlEscaping)
+		if err != nil {
+			result = multierror.Append(result, errors.Wrapf(err, "invalid templating in publish reference %s", ref))
+			continue
+		}
+
+		for _, tag := range tmpl.Tags() {
+			if _, ok := publishTemplateVariables[tag.Name()]; !ok {
+				result = multierror.Append(result, errors.Errorf("invalid publish reference %s: unsupported template variable %s, only bundle.name and bundle.version may be used", ref, tag.Name()))
+			}
+		}
+	}
+	return result.ErrorOrNil()
+}
+
+// GetPublishReferences returns the references where the bundle is published,
+// with the templating resolved. Build metadata in the version, which is not
+// allowed in a Docker tag, is separated with an underscore instead of a plus.
+func (m *Manifest) GetPublishReferences() ([]string, error) {
+	if m.Publish == nil {
+		return nil, nil
+	}
+
+	data := map[string]interface{}{
+		"bundle": map[string]interface{}{
+			"name":    m.Name,
+			"version": strings.ReplaceAll(m.Version, "+", "_"),
+		},
+	}
+
+	refs := make([]string, 0, len(m.Publish.References))
+	for _, ref := range m.Publish.References {
+		rendered, err := mustache.RenderRaw(ref, true, data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not render the publish reference %s", ref)
+		}
+		refs = append(refs, rendered)
+	}
+	return refs, nil
+}
+
 var templatedOutputRegex = regexp.MustCompile(`^bundle\.outputs\.(.+)$`)
 
 // getTemplateOutputName returns the output name from the template variable.
@@ -318,6 +483,13 @@ type ParameterDefinition struct {
 	Sensitive bool            `yaml:"sensitive"`
 	Source    ParameterSource `yaml:"source,omitempty"`
 
+	// Deprecated is a message explaining why the parameter is deprecated and what to use instead.
+	Deprecated string `yaml:"deprecated,omitempty"`
+
+	// Aliases are previous names of the parameter, which are mapped to the parameter
+	// so that existing parameter sets and flags keep working after it is renamed.
+	Aliases []string `yaml:"aliases,omitempty"`
+
 	// These fields represent a subset of bundle.Parameter as defined in cnabio/cnab-go,
 	// minus the 'Description' field (definition.Schema's will be used) and `Definition` field
 	ApplyTo     []string `yaml:"applyTo,omitempty"`
@@ -362,12 +534,58 @@ func (pd *ParameterDefinition) Validate() error {
 	return result.ErrorOrNil()
 }
 
+// GetDeprecation returns the message explaining why the parameter is
+// deprecated, or an empty string when it is not deprecated. The parameter may
+// be deprecated without a message, with deprecated: true.
+func (pd *ParameterDefinition) GetDeprecation() string {
+	switch strings.ToLower(pd.Deprecated) {
+	case "", "false":
+		return ""
+	case "true":
+		return "it will be removed in a future version of the bundle"
+	default:
+		return pd.Deprecated
+	}
+}
+
+// validateParameterAliases checks that each alias identifies a single parameter.
+func (m *Manifest) validateParameterAliases() error {
+	var result *multierror.Error
+
+	names := make([]string, 0, len(m.Parameters))
+	owners := make(map[string]string, len(m.Parameters))
+	for name := range m.Parameters {
+		names = append(names, name)
+		owners[name] = name
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		param := m.Parameters[name]
+		for _, alias := range param.Aliases {
+			if owner, ok := owners[alias]; ok {
+				if owner == alias {
+					result = multierror.Append(result, errors.Errorf("alias %s of parameter %s is already the name of a parameter", alias, param.Name))
+				} else {
+					result = multierror.Append(result, errors.Errorf("alias %s of parameter %s is already an alias of parameter %s", alias, param.Name, owner))
+				}
+				continue
+			}
+			owners[alias] = param.Name
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
 // DeepCopy copies a ParameterDefinition and returns the copy
 func (pd *ParameterDefinition) DeepCopy() *ParameterDefinition {
 	var p2 ParameterDefinition
 	p2 = *pd
 	p2.ApplyTo = make([]string, len(pd.ApplyTo))
 	copy(p2.ApplyTo, pd.ApplyTo)
+	p2.Aliases = make([]string, len(pd.Aliases))
+	copy(p2.Aliases, pd.Aliases)
 	return &p2
 }
 
@@ -452,9 +670,29 @@ type CredentialDefinition struct {
 	// ApplyTo lists the actions to which the credential applies. When unset, defaults to all actions.
 	ApplyTo []string `yaml:"applyTo,omitempty"`
 
+	// Type is a well-known type of credential, such as json or kubeconfig, that is validated before the bundle is run.
+	Type string `yaml:"type,omitempty"`
+
+	// Schema is a json schema that the credential must be valid against before the bundle is run.
+	Schema *definition.Schema `yaml:"schema,omitempty"`
+
 	Location `yaml:",inline"`
 }
 
+// Validate the credential definition.
+func (cd *CredentialDefinition) Validate() error {
+	if cd.Type != "" && !extensions.IsCredentialType(cd.Type) {
+		return errors.Errorf("invalid type %q for credential %s, allowed values are: %s",
+			cd.Type, cd.Name, strings.Join(extensions.CredentialTypes, ", "))
+	}
+
+	if cd.Type == extensions.CredentialTypeFile && cd.Path == "" {
+		return errors.Errorf("no path supplied for credential %s of type file", cd.Name)
+	}
+
+	return nil
+}
+
 func (cd *CredentialDefinition) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	type rawCreds CredentialDefinition
 	rawCred := rawCreds{
@@ -557,9 +795,56 @@ type MappedImage struct {
 	MediaType   string            `yaml:"mediaType,omitempty"`
 	Labels      map[string]string `yaml:"labels,omitempty"`
 	Tag         string            `yaml:"tag,omitempty"`
+
+	// Build declares that porter builds the image from the bundle directory.
+	// The repository and tag of a built image are set by porter, so that the
+	// image is published next to the invocation image.
+	Build *ImageBuild `yaml:"build,omitempty"`
+}
+
+// ImageBuild defines how to build an application image.
+type ImageBuild struct {
+	// Context is the path to the build context directory, relative to the bundle directory.
+	Context string `yaml:"context"`
+
+	// Dockerfile is the path to the Dockerfile, relative to the build context
+	// directory. Defaults to Dockerfile.
+	Dockerfile string `yaml:"dockerfile,omitempty"`
+}
+
+// GetDockerfile returns the path to the Dockerfile, relative to the build context directory.
+func (b *ImageBuild) GetDockerfile() string {
+	if b.Dockerfile == "" {
+		return "Dockerfile"
+	}
+	return b.Dockerfile
+}
+
+func (b *ImageBuild) Validate() error {
+	if b.Context == "" {
+		return errors.New("context is required to build an image")
+	}
+
+	for _, p := range []string{b.Context, b.Dockerfile} {
+		cleanPath := filepath.ToSlash(filepath.Clean(p))
+		if filepath.IsAbs(p) || cleanPath == ".." || strings.HasPrefix(cleanPath, "../") {
+			return errors.Errorf("invalid path %s, the path must be relative and within the bundle directory", p)
+		}
+	}
+
+	return nil
 }
 
 func (mi *MappedImage) Validate() error {
+	if mi.Build != nil {
+		if err := mi.Build.Validate(); err != nil {
+			return err
+		}
+		if mi.Digest != "" {
+			return errors.New("the digest of a built image cannot be set, it is set when the bundle is published")
+		}
+	}
+
 	if mi.Digest != "" {
 		anchoredDigestRegex := regexp.MustCompile(`^` + reference.DigestRegexp.String() + `$`)
 		if !anchoredDigestRegex.MatchString(mi.Digest) {
@@ -585,9 +870,21 @@ type Dependency struct {
 	// This should be removed prior to v1.0.0
 	Tag string `yaml:"tag"`
 
+	// Path to a local bundle directory, relative to this manifest, that is used
+	// instead of a published bundle while developing the bundle and its dependency.
+	Path string `yaml:"path,omitempty"`
+
 	Versions         []string          `yaml:"versions"`
 	AllowPrereleases bool              `yaml:"prereleases"`
 	Parameters       map[string]string `yaml:"parameters,omitempty"`
+
+	// Optional dependencies are only executed when their Condition is true.
+	Optional bool `yaml:"optional,omitempty"`
+
+	// Condition is an expression evaluated against the parameters of the bundle,
+	// such as bundle.parameters.mysql-enabled, which determines if an optional
+	// dependency is executed.
+	Condition string `yaml:"condition,omitempty"`
 }
 
 func (d *Dependency) Validate(cxt *context.Context) error {
@@ -607,17 +904,78 @@ func (d *Dependency) Validate(cxt *context.Context) error {
 		}
 	}
 
-	if d.Reference == "" {
-		return fmt.Errorf("reference is required for dependency %q", d.Name)
+	if d.Path != "" {
+		if d.Reference != "" {
+			return fmt.Errorf("only one of reference or path may be specified for dependency %q", d.Name)
+		}
+		if len(d.Versions) > 0 || d.AllowPrereleases {
+			return fmt.Errorf("versions and prereleases cannot be used with the path of dependency %q", d.Name)
+		}
+	} else if d.Reference == "" {
+		return fmt.Errorf("reference or path is required for dependency %q", d.Name)
 	}
 
 	if strings.Contains(d.Reference, ":") && len(d.Versions) > 0 {
 		return fmt.Errorf("reference for dependency %q can only specify REGISTRY/NAME when version ranges are specified", d.Name)
 	}
 
+	if d.Optional && d.Condition == "" {
+		return fmt.Errorf("optional dependency %q must have a condition", d.Name)
+	}
+
+	if d.Condition != "" {
+		if !d.Optional {
+			return fmt.Errorf("condition for dependency %q requires optional: true", d.Name)
+		}
+
+		if _, err := extensions.ParseDependencyCondition(d.Condition); err != nil {
+			return errors.Wrapf(err, "invalid dependency %q", d.Name)
+		}
+	}
+
 	return nil
 }
 
+// validateOptionalDependencies checks that the bundle can run when its optional
+// dependencies are skipped.
+func (m *Manifest) validateOptionalDependencies() error {
+	var result *multierror.Error
+
+	optional := make(map[string]bool, len(m.Dependencies))
+	for _, dep := range m.Dependencies {
+		if !dep.Optional {
+			continue
+		}
+		optional[dep.Name] = true
+
+		condition, err := extensions.ParseDependencyCondition(dep.Condition)
+		if err != nil {
+			continue // Already reported by Dependency.Validate
+		}
+		for _, param := range condition.Parameters() {
+			if _, ok := m.Parameters[param]; !ok {
+				result = multierror.Append(result, errors.Errorf("the condition for dependency %q references an undefined parameter %q", dep.Name, param))
+			}
+		}
+	}
+
+	// Parameters set from the output of an optional dependency must have a
+	// default, which is used when the dependency is skipped
+	for _, param := range m.Parameters {
+		if optional[param.Source.Dependency] && param.Default == nil {
+			result = multierror.Append(result, errors.Errorf("parameter %q must have a default because it is set from an output of the optional dependency %q", param.Name, param.Source.Dependency))
+		}
+	}
+
+	for _, ref := range m.GetTemplatedDependencyOutputs() {
+		if optional[ref.Dependency] {
+			result = multierror.Append(result, errors.Errorf("the output %s of the optional dependency %q cannot be used in a template because the dependency may be skipped; use a parameter with a default that is set from the output instead", ref.Output, ref.Dependency))
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
 type CustomActionDefinition struct {
 	Description       string `yaml:"description,omitempty"`
 	ModifiesResources bool   `yaml:"modifies,omitempty"`
@@ -898,6 +1256,16 @@ func (m *Manifest) SetInvocationImageAndReference(ref string) error {
 		m.Reference = reference.FamiliarString(bundleRef)
 	}
 
+	// Images that porter builds are published next to the invocation image
+	for alias, img := range m.ImageMap {
+		if img.Build == nil {
+			continue
+		}
+		img.Repository = reference.FamiliarName(bundleRef) + "-" + alias
+		img.Tag = dockerTag
+		m.ImageMap[alias] = img
+	}
+
 	imageName, err := reference.ParseNormalizedNamed(bundleRef.Name() + "-installer")
 	if err != err {
 		return errors.Wrapf(err, "could not set invocation image to %q", bundleRef.Name()+"-installer")
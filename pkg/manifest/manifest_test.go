@@ -2,6 +2,10 @@ package manifest
 
 import (
 	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"get.porter.sh/porter/pkg/config"
@@ -108,6 +112,118 @@ func TestLoadManifestWithDependenciesInOrder(t *testing.T) {
 
 }
 
+func TestLoadManifestWithOptionalDependencies(t *testing.T) {
+	cxt := context.NewTestContext(t)
+	cxt.AddTestFile("testdata/porter-with-optional-deps.yaml", config.Name)
+
+	m, err := LoadManifestFrom(cxt.Context, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	mysqlDep := m.Dependencies[0]
+	assert.True(t, mysqlDep.Optional)
+	assert.Equal(t, "!bundle.parameters.use-existing-db", mysqlDep.Condition)
+}
+
+func TestManifest_Validate_OptionalDependencies(t *testing.T) {
+	testcases := []struct {
+		name    string
+		modify  func(m *Manifest)
+		wantErr string
+	}{
+		{
+			name:    "optional without condition",
+			modify:  func(m *Manifest) { m.Dependencies[0].Condition = "" },
+			wantErr: `optional dependency "mysql" must have a condition`,
+		},
+		{
+			name:    "condition without optional",
+			modify:  func(m *Manifest) { m.Dependencies[0].Optional = false },
+			wantErr: `condition for dependency "mysql" requires optional: true`,
+		},
+		{
+			name:    "invalid condition",
+			modify:  func(m *Manifest) { m.Dependencies[0].Condition = "bundle.parameters.use-existing-db ==" },
+			wantErr: "unexpected end of condition",
+		},
+		{
+			name:    "undefined parameter",
+			modify:  func(m *Manifest) { m.Dependencies[0].Condition = "bundle.parameters.oops" },
+			wantErr: `the condition for dependency "mysql" references an undefined parameter "oops"`,
+		},
+		{
+			name: "parameter source without default",
+			modify: func(m *Manifest) {
+				param := m.Parameters["mysql-host"]
+				param.Default = nil
+				m.Parameters["mysql-host"] = param
+			},
+			wantErr: `parameter "mysql-host" must have a default because it is set from an output of the optional dependency "mysql"`,
+		},
+		{
+			name: "templated output",
+			modify: func(m *Manifest) {
+				m.TemplateVariables = append(m.TemplateVariables, "bundle.dependencies.mysql.outputs.host")
+			},
+			wantErr: `the output host of the optional dependency "mysql" cannot be used in a template`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cxt := context.NewTestContext(t)
+			cxt.AddTestFile("testdata/porter-with-optional-deps.yaml", config.Name)
+
+			m, err := LoadManifestFrom(cxt.Context, config.Name)
+			require.NoError(t, err, "could not load manifest")
+
+			tc.modify(m)
+			err = m.Validate(cxt.Context)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestDependency_Validate_Path(t *testing.T) {
+	testcases := []struct {
+		name    string
+		dep     Dependency
+		wantErr string
+	}{
+		{
+			name: "path",
+			dep:  Dependency{Name: "mysql", Path: "../mysql"},
+		},
+		{
+			name:    "path and reference",
+			dep:     Dependency{Name: "mysql", Path: "../mysql", Reference: "getporter/azure-mysql:5.7"},
+			wantErr: `only one of reference or path may be specified for dependency "mysql"`,
+		},
+		{
+			name:    "path and versions",
+			dep:     Dependency{Name: "mysql", Path: "../mysql", Versions: []string{"5.7.x"}},
+			wantErr: `versions and prereleases cannot be used with the path of dependency "mysql"`,
+		},
+		{
+			name:    "neither path nor reference",
+			dep:     Dependency{Name: "mysql"},
+			wantErr: `reference or path is required for dependency "mysql"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cxt := context.NewTestContext(t)
+			err := tc.dep.Validate(cxt.Context)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
 func TestAction_Validate_RequireMixinDeclaration(t *testing.T) {
 	cxt := context.NewTestContext(t)
 
@@ -171,6 +287,78 @@ func TestManifest_Validate_Name(t *testing.T) {
 	assert.EqualError(t, err, "bundle name must be set")
 }
 
+func TestManifest_Validate_Metadata(t *testing.T) {
+	cxt := context.NewTestContext(t)
+
+	cxt.AddTestFile("testdata/simple.porter.yaml", config.Name)
+
+	m, err := LoadManifestFrom(cxt.Context, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	t.Run("valid license", func(t *testing.T) {
+		m.License = "Apache-2.0 OR MIT"
+		m.Maintainers = []MaintainerDefinition{{Name: "Jane Doe"}}
+		assert.NoError(t, m.Validate(cxt.Context))
+	})
+
+	t.Run("unknown license", func(t *testing.T) {
+		m.License = "Apache2"
+		m.Maintainers = nil
+		assert.NoError(t, m.Validate(cxt.Context), "unknown license identifiers are reported by lint instead")
+	})
+
+	t.Run("invalid license", func(t *testing.T) {
+		m.License = "Apache-2.0 OR"
+		m.Maintainers = nil
+		err := m.Validate(cxt.Context)
+		assert.EqualError(t, err, `license "Apache-2.0 OR" is not a valid SPDX license expression: unexpected end of expression`)
+	})
+
+	t.Run("maintainer without name", func(t *testing.T) {
+		m.License = ""
+		m.Maintainers = []MaintainerDefinition{{Email: "jane@example.com"}}
+		err := m.Validate(cxt.Context)
+		assert.EqualError(t, err, "maintainer 0 must have a name")
+	})
+}
+
+func TestManifest_VersionAuto(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	cxt := context.NewTestContext(t)
+	cxt.UseFilesystem()
+	defer cxt.Cleanup()
+
+	dir, err := ioutil.TempDir("", "porter-manifest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	data, err := ioutil.ReadFile("testdata/simple.porter.yaml")
+	require.NoError(t, err)
+	data = []byte(strings.Replace(string(data), "version: v0.1.0", "version: auto", 1))
+	manifestPath := filepath.Join(dir, config.Name)
+	require.NoError(t, ioutil.WriteFile(manifestPath, data, 0644))
+
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"commit", "-q", "-m", "initial"},
+		{"tag", "v0.2.0"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	m, err := LoadManifestFrom(cxt.Context, manifestPath)
+	require.NoError(t, err, "could not load manifest")
+
+	assert.Equal(t, "0.2.0", m.Version)
+	assert.Equal(t, "getporter/hello:v0.2.0", m.Reference)
+}
+
 func TestManifest_Validate_Dockerfile(t *testing.T) {
 	cxt := context.NewTestContext(t)
 
@@ -239,6 +427,25 @@ func TestSetDefaults(t *testing.T) {
 		assert.Equal(t, "getporter/mybun-installer:v1.2.3", m.Image)
 	})
 
+	t.Run("built images are next to the invocation image", func(t *testing.T) {
+		m := Manifest{
+			Name:      "mybun",
+			Version:   "1.2.3",
+			Reference: "getporter/mybun:v1.2.3",
+			ImageMap: map[string]MappedImage{
+				"web":   {Build: &ImageBuild{Context: "web"}},
+				"mysql": {Repository: "mysql", Tag: "5.7"},
+			},
+		}
+
+		err := m.SetDefaults()
+		require.NoError(t, err)
+		assert.Equal(t, "getporter/mybun-web", m.ImageMap["web"].Repository)
+		assert.Equal(t, "v1.2.3", m.ImageMap["web"].Tag)
+		assert.Equal(t, "mysql", m.ImageMap["mysql"].Repository, "images that are not built should not be changed")
+		assert.Equal(t, "5.7", m.ImageMap["mysql"].Tag, "images that are not built should not be changed")
+	})
+
 	t.Run("bundle docker tag not set on reference", func(t *testing.T) {
 		cxt := context.NewTestContext(t)
 		m := Manifest{
@@ -415,6 +622,25 @@ func TestCredentialsDefinition_UnmarshalYAML(t *testing.T) {
 	})
 }
 
+func TestValidateCredentialDefinition(t *testing.T) {
+	t.Run("valid type", func(t *testing.T) {
+		cd := CredentialDefinition{Name: "kubeconfig", Type: "kubeconfig", Location: Location{Path: "/root/.kube/config"}}
+		require.NoError(t, cd.Validate())
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		cd := CredentialDefinition{Name: "kubeconfig", Type: "yaml"}
+		err := cd.Validate()
+		assert.EqualError(t, err, `invalid type "yaml" for credential kubeconfig, allowed values are: json, pem, kubeconfig, file`)
+	})
+
+	t.Run("file type without a path", func(t *testing.T) {
+		cd := CredentialDefinition{Name: "cert", Type: "file", Location: Location{EnvironmentVariable: "CERT"}}
+		err := cd.Validate()
+		assert.EqualError(t, err, "no path supplied for credential cert of type file")
+	})
+}
+
 func TestMixinDeclaration_MarshalYAML(t *testing.T) {
 	m := struct {
 		Mixins []MixinDeclaration
@@ -472,6 +698,71 @@ func TestValidateParameterDefinition_defaultFailsValidation(t *testing.T) {
 `)
 }
 
+func TestParameterDefinition_GetDeprecation(t *testing.T) {
+	testcases := []struct {
+		deprecated string
+		want       string
+	}{
+		{deprecated: "", want: ""},
+		{deprecated: "false", want: ""},
+		{deprecated: "true", want: "it will be removed in a future version of the bundle"},
+		{deprecated: "use region instead", want: "use region instead"},
+	}
+
+	for _, tc := range testcases {
+		pd := ParameterDefinition{Name: "location", Deprecated: tc.deprecated}
+		assert.Equal(t, tc.want, pd.GetDeprecation(), "unexpected deprecation for deprecated: %q", tc.deprecated)
+	}
+}
+
+func TestManifest_ValidateParameterAliases(t *testing.T) {
+	m := &Manifest{
+		Parameters: ParameterDefinitions{
+			"region":  {Name: "region", Aliases: []string{"location"}},
+			"size":    {Name: "size", Aliases: []string{"region"}},
+			"tshirt":  {Name: "tshirt", Aliases: []string{"location"}},
+			"version": {Name: "version", Aliases: []string{"tag"}},
+		},
+	}
+
+	err := m.validateParameterAliases()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "alias region of parameter size is already the name of a parameter")
+	assert.Contains(t, err.Error(), "alias location of parameter tshirt is already an alias of parameter region")
+	assert.NotContains(t, err.Error(), "version")
+
+	delete(m.Parameters, "size")
+	delete(m.Parameters, "tshirt")
+	require.NoError(t, m.validateParameterAliases())
+}
+
+func TestManifest_GetPublishReferences(t *testing.T) {
+	cxt := context.NewTestContext(t)
+	cxt.AddTestFile("testdata/porter-with-publish.yaml", config.Name)
+
+	m, err := LoadManifestFrom(cxt.Context, config.Name)
+	require.NoError(t, err, "could not load manifest")
+
+	refs, err := m.GetPublishReferences()
+	require.NoError(t, err, "GetPublishReferences failed")
+	assert.Equal(t, []string{
+		"example.com/myorg/hello:v1.2.3_abc123",
+		"example.com/myorg/hello:latest",
+		"mirror.example.com/hello:v1.2.3_abc123",
+	}, refs)
+}
+
+func TestPublishDefinition_Validate(t *testing.T) {
+	pd := PublishDefinition{References: []string{
+		"example.com/hello:v{{ bundle.version }}",
+		"example.com/hello:{{ bundle.parameters.tag }}",
+	}}
+
+	err := pd.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported template variable bundle.parameters.tag")
+}
+
 func TestValidateOutputDefinition_missingPath(t *testing.T) {
 	od := OutputDefinition{
 		Name: "myoutput",
@@ -549,6 +840,47 @@ func TestValidateImageMap(t *testing.T) {
 		err := mi.Validate()
 		assert.Error(t, err)
 	})
+
+	t.Run("with a valid build", func(t *testing.T) {
+		mi := MappedImage{
+			Repository: "getporter/mybun-web",
+			Build:      &ImageBuild{Context: "web", Dockerfile: "build/Dockerfile"},
+		}
+
+		err := mi.Validate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("with a build missing the context", func(t *testing.T) {
+		mi := MappedImage{
+			Repository: "getporter/mybun-web",
+			Build:      &ImageBuild{},
+		}
+
+		err := mi.Validate()
+		assert.EqualError(t, err, "context is required to build an image")
+	})
+
+	t.Run("with a build outside of the bundle directory", func(t *testing.T) {
+		mi := MappedImage{
+			Repository: "getporter/mybun-web",
+			Build:      &ImageBuild{Context: "web/../../other"},
+		}
+
+		err := mi.Validate()
+		assert.EqualError(t, err, "invalid path web/../../other, the path must be relative and within the bundle directory")
+	})
+
+	t.Run("with a build and a digest", func(t *testing.T) {
+		mi := MappedImage{
+			Repository: "getporter/mybun-web",
+			Digest:     "sha256:8f1133d81f1b078c865cdb11d17d1ff15f55c449d3eecca50190eed0f5e5e26f",
+			Build:      &ImageBuild{Context: "web"},
+		}
+
+		err := mi.Validate()
+		assert.EqualError(t, err, "the digest of a built image cannot be set, it is set when the bundle is published")
+	})
 }
 
 func TestLoadManifestWithCustomData(t *testing.T) {
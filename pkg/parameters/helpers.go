@@ -31,6 +31,7 @@ func NewTestParameterProvider(t *testing.T, tc *config.TestConfig) TestParameter
 		TestConfig:  tc,
 		TestSecrets: backingSecrets,
 		ParameterStorage: &ParameterStorage{
+			Config:          tc.Config,
 			ParametersStore: paramStore,
 			SecretsStore:    secrets.NewSecretStore(backingSecrets),
 		},
@@ -5,7 +5,7 @@ import "github.com/cnabio/cnab-go/valuesource"
 // ParameterProvider interface for managing sets of parameters.
 type ParameterProvider interface {
 	ParameterStore
-	ResolveAll(creds ParameterSet) (valuesource.Set, error)
+	ResolveAll(params ParameterSet, installation string) (valuesource.Set, error)
 	Validate(ParameterSet) error
 }
 
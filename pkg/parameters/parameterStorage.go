@@ -12,7 +12,6 @@ import (
 	"github.com/cnabio/cnab-go/secrets/host"
 	"github.com/cnabio/cnab-go/valuesource"
 	"github.com/hashicorp/go-multierror"
-	"github.com/pkg/errors"
 )
 
 type ParametersStore = Store
@@ -37,20 +36,14 @@ func NewParameterStorage(storage *storage.Manager) *ParameterStorage {
 	}
 }
 
-func (s ParameterStorage) ResolveAll(params ParameterSet) (valuesource.Set, error) {
-	resolvedParams := make(valuesource.Set)
-	var resolveErrors error
-
-	for _, param := range params.Parameters {
-		value, err := s.Resolve(param.Source.Key, param.Source.Value)
-		if err != nil {
-			resolveErrors = multierror.Append(resolveErrors, errors.Wrapf(err, "unable to resolve parameter %s.%s from %s %s", params.Name, param.Name, param.Source.Key, param.Source.Value))
-		}
-
-		resolvedParams[param.Name] = value
+// ResolveAll resolves the value of each parameter in the set for the specified
+// installation, expanding any templates in the parameter sources.
+func (s ParameterStorage) ResolveAll(params ParameterSet, installation string) (valuesource.Set, error) {
+	tc := secrets.TemplateContext{Installation: installation}
+	if s.Config != nil {
+		tc.Context = s.Context
 	}
-
-	return resolvedParams, resolveErrors
+	return secrets.ResolveStrategies(s.SecretsStore, tc, "parameter", params.Name, params.Parameters)
 }
 
 func (s ParameterStorage) Validate(params ParameterSet) error {
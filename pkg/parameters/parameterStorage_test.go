@@ -56,7 +56,7 @@ func TestParameterStorage_ResolveAll(t *testing.T) {
 			"param2": "param2_value",
 		}
 
-		resolved, err := parameterStorage.ResolveAll(testParameterSet)
+		resolved, err := parameterStorage.ResolveAll(testParameterSet, "")
 		require.NoError(t, err)
 		require.Equal(t, expected, resolved)
 	})
@@ -82,7 +82,7 @@ func TestParameterStorage_ResolveAll(t *testing.T) {
 			"param2": "",
 		}
 
-		resolved, err := parameterStorage.ResolveAll(testParameterSet)
+		resolved, err := parameterStorage.ResolveAll(testParameterSet, "")
 		require.EqualError(t, err, "1 error occurred:\n\t* unable to resolve parameter myparamset.param2 from secret param2: secret not found\n\n")
 		require.Equal(t, expected, resolved)
 	})
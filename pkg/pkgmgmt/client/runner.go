@@ -58,6 +58,10 @@ func (r *Runner) Run(commandOpts pkgmgmt.CommandOptions) error {
 	cmdArgs := strings.Split(commandOpts.Command, " ")
 	command := cmdArgs[0]
 	cmd := r.NewCommand(pkgPath, cmdArgs...)
+	if cmd.Env == nil {
+		// Pass along environment variables set by porter, such as SSL_CERT_FILE
+		cmd.Env = r.Environ()
+	}
 
 	// Pipe the output to porter
 	cmd.Stdout = r.Context.Out
@@ -9,6 +9,10 @@ import (
 // ExecuteAction runs the specified action. Supported actions are: install, upgrade, invoke.
 // The uninstall action works in reverse so it's implemented separately.
 func (p *Porter) ExecuteAction(action BundleAction) error {
+	if err := p.ensureWritable(action.GetAction()); err != nil {
+		return err
+	}
+
 	actionOpts := action.GetOptions()
 
 	err := p.prepullBundleByReference(actionOpts)
@@ -0,0 +1,548 @@
+package porter
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/approvals"
+	"get.porter.sh/porter/pkg/cnab/extensions"
+	cnabprovider "get.porter.sh/porter/pkg/cnab/provider"
+	"get.porter.sh/porter/pkg/printer"
+	dtprinter "github.com/carolynvs/datetime-printer"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/claim"
+	"github.com/pkg/errors"
+)
+
+var (
+	ApprovalAllowedFormats = []printer.Format{printer.FormatTable, printer.FormatYaml, printer.FormatJson}
+	ApprovalDefaultFormat  = printer.FormatTable
+)
+
+// ApprovalListOptions represent options for listing action requests.
+type ApprovalListOptions struct {
+	printer.PrintOptions
+
+	// Installation limits the results to requests for the installation.
+	Installation string
+
+	// Status limits the results to requests with the status, e.g. pending.
+	Status string
+}
+
+// Validate the options for listing action requests.
+func (o *ApprovalListOptions) Validate() error {
+	switch o.Status {
+	case "", approvals.StatusPending, approvals.StatusRunning, approvals.StatusApproved, approvals.StatusFailed, approvals.StatusRejected:
+	default:
+		return errors.Errorf("invalid --status %s, allowed values are: %s, %s, %s, %s, %s",
+			o.Status, approvals.StatusPending, approvals.StatusRunning, approvals.StatusApproved, approvals.StatusFailed, approvals.StatusRejected)
+	}
+
+	return o.PrintOptions.Validate(ApprovalDefaultFormat, ApprovalAllowedFormats)
+}
+
+// ApprovalShowOptions represent options for showing an action request.
+type ApprovalShowOptions struct {
+	printer.PrintOptions
+
+	// ID of the action request.
+	ID string
+}
+
+// Validate the options for showing an action request.
+func (o *ApprovalShowOptions) Validate(args []string) error {
+	id, err := validateApprovalID(args)
+	if err != nil {
+		return err
+	}
+	o.ID = id
+
+	return o.PrintOptions.Validate(ApprovalDefaultFormat, ApprovalAllowedFormats)
+}
+
+// ApprovalDecisionOptions represent options for approving or rejecting an action request.
+type ApprovalDecisionOptions struct {
+	// ID of the action request.
+	ID string
+
+	// Reason for the decision.
+	Reason string
+}
+
+// Validate the options for approving or rejecting an action request.
+func (o *ApprovalDecisionOptions) Validate(args []string) error {
+	id, err := validateApprovalID(args)
+	o.ID = id
+	return err
+}
+
+func validateApprovalID(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.Errorf("expected a single argument, the request ID, but got %d", len(args))
+	}
+	return args[0], nil
+}
+
+// requestApproval records a request to execute an action, instead of
+// executing it. The action is executed once another user approves the request.
+func (p *Porter) requestApproval(action BundleAction) error {
+	if err := p.ensureWritable("request approval"); err != nil {
+		return err
+	}
+	if err := p.ensurePersistent("request approval"); err != nil {
+		return err
+	}
+
+	requestedBy, err := p.Users.CurrentUser()
+	if err != nil {
+		return err
+	}
+
+	opts := action.GetOptions()
+	err = p.prepullBundleByReference(opts)
+	if err != nil {
+		return errors.Wrapf(err, "unable to pull bundle before requesting approval")
+	}
+
+	err = p.ensureLocalBundleIsUpToDate(opts.bundleFileOptions)
+	if err != nil {
+		return err
+	}
+
+	c, err := p.Claims.ReadLastClaim(opts.Name)
+	if err != nil {
+		return errors.Wrapf(err, "approval can only be requested for an existing installation, could not load installation %s", opts.Name)
+	}
+
+	// Only record the values from the command line, parameter and credential
+	// sets are resolved again when the request is approved so that secrets are not persisted.
+	args := cnabprovider.ActionArguments{
+		Action:                action.GetAction(),
+		Installation:          opts.Name,
+		Params:                make(map[string]string, len(opts.parsedParams)),
+		CredentialIdentifiers: make([]string, len(opts.CredentialIdentifiers)),
+		Driver:                opts.Driver,
+		AllowDockerHostAccess: opts.AllowAccessToDockerHost,
+	}
+	for k, v := range opts.parsedParams {
+		args.Params[k] = v
+	}
+	copy(args.CredentialIdentifiers, opts.CredentialIdentifiers)
+	if opts.driverDefaulted {
+		// Use the driver recorded with the installation when the request is approved
+		args.Driver = ""
+	}
+
+	r, err := approvals.NewActionRequest(requestedBy, args)
+	if err != nil {
+		return err
+	}
+	r.AllowDockerHostAccessSet = opts.AllowAccessToDockerHostSet
+	r.ParameterSets = opts.ParameterSets
+
+	r.Plan = approvals.Plan{
+		Revision:        c.Revision,
+		CurrentBundle:   formatBundleVersion(c.Bundle),
+		Bundle:          formatBundleVersion(c.Bundle),
+		BundleReference: opts.Reference,
+		Parameters:      make([]string, 0, len(args.Params)),
+		ParameterSets:   opts.ParameterSets,
+		CredentialSets:  opts.CredentialIdentifiers,
+	}
+	for name := range args.Params {
+		r.Plan.Parameters = append(r.Plan.Parameters, name)
+	}
+	sort.Strings(r.Plan.Parameters)
+
+	if opts.CNABFile != "" {
+		b, err := p.CNAB.LoadBundle(opts.CNABFile)
+		if err != nil {
+			return err
+		}
+		r.Bundle = &b
+		r.Plan.Bundle = formatBundleVersion(b)
+	}
+
+	// Requests are stored in plaintext, so sensitive values must come from a
+	// parameter set, which is resolved from the secret store on approval
+	bun := c.Bundle
+	if r.Bundle != nil {
+		bun = *r.Bundle
+	}
+	if sensitive := getSensitiveParameters(bun, args.Params); len(sensitive) > 0 {
+		return errors.Errorf("sensitive parameters cannot be set with --param when requesting approval, because the request is not encrypted: %s. "+
+			"Set them in a parameter set instead, which is resolved when the request is approved", strings.Join(sensitive, ", "))
+	}
+
+	if opts.RelocationMapping != "" {
+		data, err := p.FileSystem.ReadFile(opts.RelocationMapping)
+		if err != nil {
+			return errors.Wrapf(err, "could not read relocation mapping %s", opts.RelocationMapping)
+		}
+		r.RelocationMapping = string(data)
+	}
+
+	if uninstall, ok := action.(UninstallOptions); ok {
+		r.Delete = uninstall.Delete
+		r.ForceDelete = uninstall.ForceDelete
+	}
+
+	if err = p.Approvals.Save(r); err != nil {
+		return errors.Wrap(err, "could not save the action request")
+	}
+
+	fmt.Fprintf(p.Out, "Requested approval to %s installation %s: %s\n", args.Action, args.Installation, r.ID)
+	fmt.Fprintf(p.Out, "Another user must approve the request with: porter approvals approve %s\n", r.ID)
+	return nil
+}
+
+// getSensitiveParameters returns the names of the parameters that are
+// sensitive in the bundle, resolving any aliases of renamed parameters.
+func getSensitiveParameters(b bundle.Bundle, params map[string]string) []string {
+	porterParams, _ := extensions.ReadPorterParameters(b)
+
+	var sensitive []string
+	for name := range params {
+		resolved, _ := porterParams.ResolveName(name)
+		param, ok := b.Parameters[resolved]
+		if !ok {
+			continue
+		}
+		def, ok := b.Definitions[param.Definition]
+		if ok && def.WriteOnly != nil && *def.WriteOnly {
+			sensitive = append(sensitive, name)
+		}
+	}
+	sort.Strings(sensitive)
+	return sensitive
+}
+
+func formatBundleVersion(b bundle.Bundle) string {
+	return fmt.Sprintf("%s v%s", b.Name, b.Version)
+}
+
+// ListApprovals lists the action requests, newest first.
+func (p *Porter) ListApprovals(opts ApprovalListOptions) ([]approvals.ActionRequest, error) {
+	if err := p.ensurePersistent("list action requests"); err != nil {
+		return nil, err
+	}
+
+	requests, err := p.Approvals.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list action requests")
+	}
+
+	results := make([]approvals.ActionRequest, 0, len(requests))
+	for _, r := range requests {
+		if opts.Installation != "" && r.Arguments.Installation != opts.Installation {
+			continue
+		}
+		if opts.Status != "" && r.Status != opts.Status {
+			continue
+		}
+		results = append(results, r)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Created.After(results[j].Created)
+	})
+	return results, nil
+}
+
+// PrintApprovals prints the action requests.
+func (p *Porter) PrintApprovals(opts ApprovalListOptions) error {
+	requests, err := p.ListApprovals(opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, requests)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, requests)
+	case printer.FormatTable:
+		// have every row use the same "now" starting ... NOW!
+		now := time.Now()
+		tp := dtprinter.DateTimePrinter{
+			Now: func() time.Time { return now },
+		}
+
+		row :=
+			func(v interface{}) []interface{} {
+				r, ok := v.(approvals.ActionRequest)
+				if !ok {
+					return nil
+				}
+				return []interface{}{r.ID, r.Arguments.Installation, r.Arguments.Action, r.RequestedBy, tp.Format(r.Created), r.Status}
+			}
+		return printer.PrintTable(p.Out, requests, row,
+			"ID", "INSTALLATION", "ACTION", "REQUESTED BY", "CREATED", "STATUS")
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+}
+
+// ShowApproval prints an action request and the plan for the action.
+func (p *Porter) ShowApproval(opts ApprovalShowOptions) error {
+	if err := p.ensurePersistent("show the action request"); err != nil {
+		return err
+	}
+
+	r, err := p.readActionRequest(opts.ID)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, r)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, r)
+	case printer.FormatTable:
+		// Set up human friendly time formatter
+		now := time.Now()
+		tp := dtprinter.DateTimePrinter{
+			Now: func() time.Time { return now },
+		}
+
+		fmt.Fprintf(p.Out, "ID: %s\n", r.ID)
+		fmt.Fprintf(p.Out, "Installation: %s\n", r.Arguments.Installation)
+		fmt.Fprintf(p.Out, "Action: %s\n", r.Arguments.Action)
+		fmt.Fprintf(p.Out, "Status: %s\n", r.Status)
+		if r.Error != "" {
+			fmt.Fprintf(p.Out, "Error: %s\n", r.Error)
+		}
+		fmt.Fprintf(p.Out, "Requested By: %s\n", r.RequestedBy)
+		fmt.Fprintf(p.Out, "Created: %s\n", tp.Format(r.Created))
+
+		fmt.Fprintln(p.Out)
+		fmt.Fprintln(p.Out, "Plan:")
+		fmt.Fprintf(p.Out, "  Current Bundle: %s\n", r.Plan.CurrentBundle)
+		fmt.Fprintf(p.Out, "  Bundle: %s\n", r.Plan.Bundle)
+		if r.Plan.BundleReference != "" {
+			fmt.Fprintf(p.Out, "  Bundle Reference: %s\n", r.Plan.BundleReference)
+		}
+		if r.Delete || r.ForceDelete {
+			fmt.Fprintln(p.Out, "  Delete Installation: true")
+		}
+		fmt.Fprintf(p.Out, "  Parameters: %s\n", strings.Join(r.Plan.Parameters, ", "))
+		fmt.Fprintf(p.Out, "  Parameter Sets: %s\n", strings.Join(r.Plan.ParameterSets, ", "))
+		fmt.Fprintf(p.Out, "  Credential Sets: %s\n", strings.Join(r.Plan.CredentialSets, ", "))
+
+		if r.Decision != nil {
+			fmt.Fprintln(p.Out)
+			fmt.Fprintln(p.Out, "Decision:")
+			fmt.Fprintf(p.Out, "  By: %s\n", r.Decision.By)
+			fmt.Fprintf(p.Out, "  Timestamp: %s\n", tp.Format(r.Decision.Timestamp))
+			if r.Decision.Reason != "" {
+				fmt.Fprintf(p.Out, "  Reason: %s\n", r.Decision.Reason)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+}
+
+// ApproveRequest approves an action request and then executes the action.
+// Credential and parameter sets are resolved again when the action is executed,
+// and the approval is recorded on the claim for the action.
+func (p *Porter) ApproveRequest(opts ApprovalDecisionOptions) error {
+	if err := p.ensureWritable("approve the request"); err != nil {
+		return err
+	}
+	if err := p.ensurePersistent("approve the request"); err != nil {
+		return err
+	}
+
+	approvedBy, err := p.Users.CurrentUser()
+	if err != nil {
+		return err
+	}
+
+	r, err := p.readActionRequest(opts.ID)
+	if err != nil {
+		return err
+	}
+	if r.Status != approvals.StatusPending {
+		return errors.Errorf("request %s is already %s", r.ID, r.Status)
+	}
+
+	// The approver reviewed the plan for the installation as it was when
+	// the action was requested, so don't apply it to an installation that has since changed.
+	c, err := p.Claims.ReadLastClaim(r.Arguments.Installation)
+	if err != nil {
+		return errors.Wrapf(err, "could not load installation %s", r.Arguments.Installation)
+	}
+	if c.Revision != r.Plan.Revision {
+		return errors.Errorf("installation %s has changed since request %s was made, reject the request and request approval again", r.Arguments.Installation, r.ID)
+	}
+
+	actionOpts, cleanup, err := p.prepareApprovedAction(r)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+
+	approval, err := r.Approve(approvedBy)
+	if err != nil {
+		return err
+	}
+	r.Decision.Reason = opts.Reason
+	actionOpts.approval = &approval
+
+	// Save the request as running before executing the action, so that
+	// another approver, or a retry after porter was interrupted, cannot
+	// execute the action again
+	if err = p.claimActionRequest(r); err != nil {
+		return err
+	}
+	fmt.Fprintf(p.Out, "Approved request %s\n", r.ID)
+
+	actionErr := p.executeApprovedAction(r, actionOpts)
+	r.RecordResult(actionErr)
+	if err = p.Approvals.Save(r); err != nil {
+		if actionErr != nil {
+			return errors.Wrapf(actionErr, "could not save request %s: %s", r.ID, err)
+		}
+		return errors.Wrapf(err, "could not save request %s", r.ID)
+	}
+	return actionErr
+}
+
+// claimActionRequest saves an approved request as running, after checking
+// that the request was not decided by someone else in the meantime.
+func (p *Porter) claimActionRequest(r approvals.ActionRequest) error {
+	current, err := p.readActionRequest(r.ID)
+	if err != nil {
+		return err
+	}
+	if current.Status != approvals.StatusPending {
+		return errors.Errorf("request %s is already %s", r.ID, current.Status)
+	}
+
+	return errors.Wrapf(p.Approvals.Save(r), "could not save request %s", r.ID)
+}
+
+func (p *Porter) executeApprovedAction(r approvals.ActionRequest, actionOpts *BundleActionOptions) error {
+	switch r.Arguments.Action {
+	case claim.ActionUpgrade:
+		return p.UpgradeBundle(UpgradeOptions{actionOpts})
+	case claim.ActionUninstall:
+		return p.UninstallBundle(UninstallOptions{
+			BundleActionOptions: actionOpts,
+			UninstallDeleteOptions: UninstallDeleteOptions{
+				Delete:      r.Delete,
+				ForceDelete: r.ForceDelete,
+			},
+		})
+	default:
+		return p.InvokeBundle(InvokeOptions{Action: r.Arguments.Action, BundleActionOptions: actionOpts})
+	}
+}
+
+// prepareApprovedAction converts an action request back into the options
+// for the action. The returned cleanup function removes any temporary files
+// and must always be called.
+func (p *Porter) prepareApprovedAction(r approvals.ActionRequest) (*BundleActionOptions, func(), error) {
+	cleanup := func() {}
+
+	opts := &BundleActionOptions{}
+	opts.Name = r.Arguments.Installation
+	opts.ParameterSets = r.ParameterSets
+	opts.CredentialIdentifiers = r.Arguments.CredentialIdentifiers
+	opts.Driver = r.Arguments.Driver
+	opts.AllowAccessToDockerHost = r.Arguments.AllowDockerHostAccess
+	opts.AllowAccessToDockerHostSet = r.AllowDockerHostAccessSet
+	opts.driverDefaulted = opts.Driver == ""
+
+	opts.Params = make([]string, 0, len(r.Arguments.Params))
+	for k, v := range r.Arguments.Params {
+		opts.Params = append(opts.Params, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(opts.Params)
+	if err := opts.parseParams(); err != nil {
+		return nil, cleanup, err
+	}
+
+	opts.defaultDriver()
+	if err := opts.validateDriver(p.Context); err != nil {
+		return nil, cleanup, err
+	}
+
+	if r.Bundle == nil && r.RelocationMapping == "" {
+		// Use the bundle that is already installed
+		return opts, cleanup, nil
+	}
+
+	tmpDir, err := p.FileSystem.TempDir("", "porter")
+	if err != nil {
+		return nil, cleanup, errors.Wrap(err, "could not create a temporary directory for the bundle")
+	}
+	cleanup = func() {
+		p.FileSystem.RemoveAll(tmpDir)
+	}
+
+	// Use the bundle from the request instead of pulling the reference again,
+	// so that the action uses the bundle that was approved
+	if r.Bundle != nil {
+		data, err := json.Marshal(r.Bundle)
+		if err != nil {
+			return nil, cleanup, errors.Wrap(err, "could not marshal the bundle")
+		}
+		opts.CNABFile = filepath.Join(tmpDir, "bundle.json")
+		if err = p.FileSystem.WriteFile(opts.CNABFile, data, 0644); err != nil {
+			return nil, cleanup, errors.Wrap(err, "could not write the bundle")
+		}
+	}
+
+	if r.RelocationMapping != "" {
+		opts.RelocationMapping = filepath.Join(tmpDir, "relocation-mapping.json")
+		if err = p.FileSystem.WriteFile(opts.RelocationMapping, []byte(r.RelocationMapping), 0644); err != nil {
+			return nil, cleanup, errors.Wrap(err, "could not write the relocation mapping")
+		}
+	}
+
+	return opts, cleanup, nil
+}
+
+// RejectRequest rejects an action request, so that it is never executed.
+func (p *Porter) RejectRequest(opts ApprovalDecisionOptions) error {
+	if err := p.ensureWritable("reject the request"); err != nil {
+		return err
+	}
+	if err := p.ensurePersistent("reject the request"); err != nil {
+		return err
+	}
+
+	rejectedBy, err := p.Users.CurrentUser()
+	if err != nil {
+		return err
+	}
+
+	r, err := p.readActionRequest(opts.ID)
+	if err != nil {
+		return err
+	}
+
+	if err = r.Reject(rejectedBy, opts.Reason); err != nil {
+		return err
+	}
+	if err = p.Approvals.Save(r); err != nil {
+		return errors.Wrapf(err, "could not save request %s", r.ID)
+	}
+
+	fmt.Fprintf(p.Out, "Rejected request %s\n", r.ID)
+	return nil
+}
+
+func (p *Porter) readActionRequest(id string) (approvals.ActionRequest, error) {
+	r, err := p.Approvals.Read(id)
+	return r, errors.Wrapf(err, "could not read request %s", id)
+}
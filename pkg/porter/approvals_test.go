@@ -0,0 +1,285 @@
+package porter
+
+import (
+	"testing"
+
+	"get.porter.sh/porter/pkg/approvals"
+	"get.porter.sh/porter/pkg/printer"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/cnabio/cnab-go/claim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newApprovalTestPorter(t *testing.T) *TestPorter {
+	p := NewTestPorter(t)
+	writeOnly := true
+	b := bundle.Bundle{
+		SchemaVersion: "v1.0.0",
+		Name:          "mybuns",
+		Version:       "0.1.0",
+		InvocationImages: []bundle.InvocationImage{
+			{BaseImage: bundle.BaseImage{Image: "localhost:5000/mybuns:v0.1.0", ImageType: "docker"}},
+		},
+		Actions: map[string]bundle.Action{
+			"zombies": {Modifies: true},
+		},
+		Definitions: definition.Definitions{
+			"color":        &definition.Schema{Type: "string"},
+			"password":     &definition.Schema{Type: "string", WriteOnly: &writeOnly},
+			"porter-debug": &definition.Schema{Type: "boolean"},
+		},
+		Parameters: map[string]bundle.Parameter{
+			"color":        {Definition: "color", Destination: &bundle.Location{EnvironmentVariable: "COLOR"}},
+			"password":     {Definition: "password", Destination: &bundle.Location{EnvironmentVariable: "PASSWORD"}},
+			"porter-debug": {Definition: "porter-debug", Destination: &bundle.Location{EnvironmentVariable: "PORTER_DEBUG"}},
+		},
+	}
+	c := p.TestClaims.CreateClaim("wordpress", claim.ActionInstall, b, nil)
+	p.TestClaims.CreateResult(c, claim.StatusSucceeded)
+	return p
+}
+
+func TestPorter_ApproveRequest(t *testing.T) {
+	p := newApprovalTestPorter(t)
+	p.TestUsers.SetUser("alice")
+
+	opts := NewInvokeOptions()
+	opts.Action = "zombies"
+	opts.Name = "wordpress"
+	opts.Params = []string{"color=blue"}
+	opts.Driver = DebugDriver
+	opts.RequestApproval = true
+	require.NoError(t, opts.parseParams())
+
+	err := p.InvokeBundle(opts)
+	require.NoError(t, err, "InvokeBundle failed")
+
+	i, err := p.Claims.ReadInstallation("wordpress")
+	require.NoError(t, err, "ReadInstallation failed")
+	require.Len(t, i.Claims, 1, "the action should not be executed until it is approved")
+
+	requests, err := p.ListApprovals(ApprovalListOptions{Status: approvals.StatusPending})
+	require.NoError(t, err, "ListApprovals failed")
+	require.Len(t, requests, 1)
+	r := requests[0]
+	assert.Equal(t, "alice", r.RequestedBy)
+	assert.Equal(t, "zombies", r.Arguments.Action)
+	assert.Equal(t, map[string]string{"color": "blue"}, r.Arguments.Params)
+	assert.Equal(t, "mybuns v0.1.0", r.Plan.CurrentBundle)
+	assert.Equal(t, []string{"color"}, r.Plan.Parameters)
+
+	t.Run("requester cannot approve", func(t *testing.T) {
+		err := p.ApproveRequest(ApprovalDecisionOptions{ID: r.ID})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must be approved by a different user")
+	})
+
+	t.Run("approve", func(t *testing.T) {
+		p.TestUsers.SetUser("bob")
+		err := p.ApproveRequest(ApprovalDecisionOptions{ID: r.ID, Reason: "lgtm"})
+		require.NoError(t, err, "ApproveRequest failed")
+
+		r, err := p.Approvals.Read(r.ID)
+		require.NoError(t, err)
+		assert.Equal(t, approvals.StatusApproved, r.Status)
+		assert.Equal(t, "lgtm", r.Decision.Reason)
+
+		c, err := p.Claims.ReadLastClaim("wordpress")
+		require.NoError(t, err, "ReadLastClaim failed")
+		assert.Equal(t, "zombies", c.Action)
+		assert.Equal(t, "blue", c.Parameters["color"])
+		custom, ok := c.Custom.(map[string]interface{})
+		require.True(t, ok, "expected the approval to be recorded on the claim, got %T", c.Custom)
+		approval, ok := custom[approvals.ClaimExtensionKey].(map[string]interface{})
+		require.True(t, ok, "expected the approval to be recorded on the claim")
+		assert.Equal(t, r.ID, approval["requestId"])
+		assert.Equal(t, "alice", approval["requestedBy"])
+		assert.Equal(t, "bob", approval["approvedBy"])
+	})
+
+	t.Run("already approved", func(t *testing.T) {
+		err := p.RejectRequest(ApprovalDecisionOptions{ID: r.ID})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is already approved")
+
+		p.TestUsers.SetUser("carol")
+		err = p.ApproveRequest(ApprovalDecisionOptions{ID: r.ID})
+		require.Error(t, err, "the action should not be executed twice")
+		assert.Contains(t, err.Error(), "is already approved")
+	})
+}
+
+func TestPorter_ApproveRequest_Running(t *testing.T) {
+	p := newApprovalTestPorter(t)
+	p.TestUsers.SetUser("alice")
+
+	opts := NewInvokeOptions()
+	opts.Action = "zombies"
+	opts.Name = "wordpress"
+	opts.Driver = DebugDriver
+	opts.RequestApproval = true
+	err := p.InvokeBundle(opts)
+	require.NoError(t, err, "InvokeBundle failed")
+
+	requests, err := p.ListApprovals(ApprovalListOptions{})
+	require.NoError(t, err, "ListApprovals failed")
+	require.Len(t, requests, 1)
+
+	// Another approver is executing the action
+	r := requests[0]
+	_, err = r.Approve(approvals.User{Name: "bob", ID: "1001"})
+	require.NoError(t, err)
+	require.NoError(t, p.Approvals.Save(r))
+
+	p.TestUsers.SetUser("carol")
+	err = p.ApproveRequest(ApprovalDecisionOptions{ID: r.ID})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is already running")
+
+	i, err := p.Claims.ReadInstallation("wordpress")
+	require.NoError(t, err, "ReadInstallation failed")
+	assert.Len(t, i.Claims, 1, "the action should not be executed again")
+}
+
+func TestPorter_RequestApproval_SensitiveParameter(t *testing.T) {
+	p := newApprovalTestPorter(t)
+	p.TestUsers.SetUser("alice")
+
+	opts := NewUpgradeOptions()
+	opts.Name = "wordpress"
+	opts.Params = []string{"color=blue", "password=topsecret"}
+	opts.RequestApproval = true
+	require.NoError(t, opts.parseParams())
+
+	err := p.UpgradeBundle(opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sensitive parameters cannot be set with --param when requesting approval")
+	assert.Contains(t, err.Error(), "password")
+
+	requests, err := p.ListApprovals(ApprovalListOptions{})
+	require.NoError(t, err, "ListApprovals failed")
+	assert.Empty(t, requests, "the request should not be saved")
+}
+
+func TestPorter_ApproveRequest_ActionFailed(t *testing.T) {
+	p := newApprovalTestPorter(t)
+	p.TestUsers.SetUser("alice")
+
+	opts := NewInvokeOptions()
+	opts.Action = "ghosts"
+	opts.Name = "wordpress"
+	opts.Driver = DebugDriver
+	opts.RequestApproval = true
+	err := p.InvokeBundle(opts)
+	require.NoError(t, err, "InvokeBundle failed")
+
+	requests, err := p.ListApprovals(ApprovalListOptions{})
+	require.NoError(t, err, "ListApprovals failed")
+	require.Len(t, requests, 1)
+
+	p.TestUsers.SetUser("bob")
+	err = p.ApproveRequest(ApprovalDecisionOptions{ID: requests[0].ID})
+	require.Error(t, err, "the action should fail because the bundle does not define it")
+
+	r, err := p.Approvals.Read(requests[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, approvals.StatusFailed, r.Status, "the request should record that the action failed")
+	assert.NotEmpty(t, r.Error)
+	assert.Equal(t, "bob", r.Decision.By)
+}
+
+func TestPorter_ApproveRequest_InstallationChanged(t *testing.T) {
+	p := newApprovalTestPorter(t)
+	p.TestUsers.SetUser("alice")
+
+	opts := NewUpgradeOptions()
+	opts.Name = "wordpress"
+	opts.RequestApproval = true
+	err := p.UpgradeBundle(opts)
+	require.NoError(t, err, "UpgradeBundle failed")
+
+	requests, err := p.ListApprovals(ApprovalListOptions{})
+	require.NoError(t, err, "ListApprovals failed")
+	require.Len(t, requests, 1)
+
+	// Change the installation after the request was made
+	i, err := p.Claims.ReadInstallation("wordpress")
+	require.NoError(t, err)
+	c := p.TestClaims.CreateClaim("wordpress", claim.ActionUpgrade, i.Claims[0].Bundle, nil)
+	p.TestClaims.CreateResult(c, claim.StatusSucceeded)
+
+	p.TestUsers.SetUser("bob")
+	err = p.ApproveRequest(ApprovalDecisionOptions{ID: requests[0].ID})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "installation wordpress has changed since request")
+
+	r, err := p.Approvals.Read(requests[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, approvals.StatusPending, r.Status, "the request should still be pending")
+}
+
+func TestPorter_RejectRequest(t *testing.T) {
+	p := newApprovalTestPorter(t)
+	p.TestUsers.SetUser("alice")
+
+	opts := NewUninstallOptions()
+	opts.Name = "wordpress"
+	opts.Delete = true
+	opts.RequestApproval = true
+	err := p.UninstallBundle(opts)
+	require.NoError(t, err, "UninstallBundle failed")
+
+	requests, err := p.ListApprovals(ApprovalListOptions{Installation: "wordpress"})
+	require.NoError(t, err, "ListApprovals failed")
+	require.Len(t, requests, 1)
+	assert.True(t, requests[0].Delete, "the delete flag should be recorded on the request")
+
+	p.TestUsers.SetUser("bob")
+	err = p.RejectRequest(ApprovalDecisionOptions{ID: requests[0].ID, Reason: "not during the release"})
+	require.NoError(t, err, "RejectRequest failed")
+
+	showOpts := ApprovalShowOptions{}
+	require.NoError(t, showOpts.Validate([]string{requests[0].ID}))
+	showOpts.Format = printer.FormatTable
+	err = p.ShowApproval(showOpts)
+	require.NoError(t, err, "ShowApproval failed")
+
+	gotOutput := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, gotOutput, "Status: rejected")
+	assert.Contains(t, gotOutput, "Delete Installation: true")
+	assert.Contains(t, gotOutput, "Reason: not during the release")
+
+	_, err = p.Claims.ReadInstallation("wordpress")
+	require.NoError(t, err, "the installation should not have been uninstalled")
+}
+
+func TestPorter_Approvals_Ephemeral(t *testing.T) {
+	p := newApprovalTestPorter(t)
+	p.Ephemeral = true
+	p.TestUsers.SetUser("alice")
+
+	opts := NewUpgradeOptions()
+	opts.Name = "wordpress"
+	opts.RequestApproval = true
+	err := p.UpgradeBundle(opts)
+	require.Error(t, err, "requests would be discarded when porter exits")
+	assert.Contains(t, err.Error(), "cannot request approval")
+
+	_, err = p.ListApprovals(ApprovalListOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "porter is running in ephemeral mode")
+
+	err = p.ApproveRequest(ApprovalDecisionOptions{ID: "abc123"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot approve the request")
+
+	err = p.RejectRequest(ApprovalDecisionOptions{ID: "abc123"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot reject the request")
+
+	err = p.ShowApproval(ApprovalShowOptions{ID: "abc123"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot show the action request")
+}
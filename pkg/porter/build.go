@@ -3,6 +3,9 @@ package porter
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"time"
 
 	"get.porter.sh/porter/pkg/build"
 	configadapter "get.porter.sh/porter/pkg/cnab/config-adapter"
@@ -10,6 +13,8 @@ import (
 	"get.porter.sh/porter/pkg/manifest"
 	"get.porter.sh/porter/pkg/mixin"
 	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/secrets"
+	"get.porter.sh/porter/pkg/vcs"
 	"github.com/Masterminds/semver/v3"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/pkg/errors"
@@ -17,10 +22,17 @@ import (
 
 type BuildProvider interface {
 	// BuildInvocationImage using the bundle in the build context directory
-	BuildInvocationImage(manifest *manifest.Manifest) error
+	BuildInvocationImage(manifest *manifest.Manifest, opts build.ImageOptions) error
 
 	// TagInvocationImage using the origTag and newTag values supplied
 	TagInvocationImage(origTag, newTag string) error
+
+	// BuildApplicationImage builds an application image declared in the manifest
+	BuildApplicationImage(opts build.ApplicationImageOptions) error
+
+	// ResolveImageDigest returns the repository digest of the image that a
+	// build uses, or an empty string when the image has no repository digest.
+	ResolveImageDigest(img string) (string, error)
 }
 
 type BuildOptions struct {
@@ -28,6 +40,16 @@ type BuildOptions struct {
 	contextOptions
 	metadataOpts
 	NoLint bool
+
+	// NoCache disables reusing the cached build output of mixins.
+	NoCache bool
+
+	// Secrets to mount while building the invocation image, in the format
+	// id=ID,src=PATH or id=ID,secret=NAME.
+	Secrets []string
+
+	// parsedSecrets is the parsed representation of Secrets.
+	parsedSecrets []build.Secret
 }
 
 func (o *BuildOptions) Validate(cxt *context.Context) error {
@@ -39,9 +61,40 @@ func (o *BuildOptions) Validate(cxt *context.Context) error {
 		o.Version = v.String()
 	}
 
+	if err := o.validateSecrets(cxt); err != nil {
+		return err
+	}
+
 	return o.bundleFileOptions.Validate(cxt)
 }
 
+func (o *BuildOptions) validateSecrets(cxt *context.Context) error {
+	o.parsedSecrets = make([]build.Secret, 0, len(o.Secrets))
+	ids := make(map[string]bool, len(o.Secrets))
+	for _, value := range o.Secrets {
+		s, err := build.ParseSecret(value)
+		if err != nil {
+			return err
+		}
+
+		if ids[s.ID] {
+			return errors.Errorf("invalid --secret: secret %s is specified more than once", s.ID)
+		}
+		ids[s.ID] = true
+
+		if s.Source != "" {
+			// Resolve the path before switching to the build context directory
+			s.Source = cxt.FileSystem.Abs(s.Source)
+			if _, err := cxt.FileSystem.Stat(s.Source); err != nil {
+				return errors.Wrapf(err, "unable to access the source file for secret %s", s.ID)
+			}
+		}
+
+		o.parsedSecrets = append(o.parsedSecrets, s)
+	}
+	return nil
+}
+
 func (p *Porter) Build(opts BuildOptions) error {
 	opts.Apply(p.Context)
 
@@ -51,6 +104,11 @@ func (p *Porter) Build(opts BuildOptions) error {
 		return errors.Wrap(err, "could not cleanup generated .cnab directory before building")
 	}
 
+	record, err := p.newBuildRecord(&opts)
+	if err != nil {
+		return err
+	}
+
 	// Generate Porter's canonical version of the user-provided manifest
 	if err := p.generateInternalManifest(opts); err != nil {
 		return errors.Wrap(err, "unable to generate manifest")
@@ -71,18 +129,24 @@ func (p *Porter) Build(opts BuildOptions) error {
 		}
 	}
 
+	generator := build.NewDockerfileGenerator(p.Config, p.Manifest, p.Templates, p.Mixins)
+	generator.NoCache = opts.NoCache
+
+	// Remember the base image so that the bundle can be rebased later
+	if record.BaseImage, err = p.resolveBaseImage(generator); err != nil {
+		return err
+	}
+
 	// Build bundle so that resulting bundle.json is available for inclusion
 	// into the invocation image.
 	// Note: the content digest field on the invocation image section of the
 	// bundle.json will *not* be correct until the image is actually pushed
 	// to a registry.  The bundle.json will need to be updated after publishing
 	// and provided just-in-time during bundle execution.
-	if err := p.buildBundle(p.Manifest.Image, ""); err != nil {
+	if err := p.buildBundle(p.Manifest.Image, "", record); err != nil {
 		return errors.Wrap(err, "unable to build bundle")
 	}
 
-	generator := build.NewDockerfileGenerator(p.Config, p.Manifest, p.Templates, p.Mixins)
-
 	if err := generator.PrepareFilesystem(); err != nil {
 		return fmt.Errorf("unable to copy run script, runtimes or mixins: %s", err)
 	}
@@ -90,7 +154,141 @@ func (p *Porter) Build(opts BuildOptions) error {
 		return fmt.Errorf("unable to generate Dockerfile: %s", err)
 	}
 
-	return errors.Wrap(p.Builder.BuildInvocationImage(p.Manifest), "unable to build CNAB invocation image")
+	imageOpts, cleanup, err := p.prepareBuildSecrets(opts.parsedSecrets)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+
+	if err := p.Builder.BuildInvocationImage(p.Manifest, imageOpts); err != nil {
+		return errors.Wrap(err, "unable to build CNAB invocation image")
+	}
+
+	return p.buildApplicationImages(filepath.Dir(p.FileSystem.Abs(opts.File)))
+}
+
+// buildApplicationImages builds the images declared in the manifest that
+// have a build section. The build paths are relative to the bundle directory.
+func (p *Porter) buildApplicationImages(bundleDir string) error {
+	aliases := make([]string, 0, len(p.Manifest.ImageMap))
+	for alias, img := range p.Manifest.ImageMap {
+		if img.Build != nil {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+
+	for _, alias := range aliases {
+		img := p.Manifest.ImageMap[alias]
+		opts := build.ApplicationImageOptions{
+			Image:      fmt.Sprintf("%s:%s", img.Repository, img.Tag),
+			Context:    filepath.Join(bundleDir, img.Build.Context),
+			Dockerfile: img.Build.GetDockerfile(),
+		}
+		if err := p.Builder.BuildApplicationImage(opts); err != nil {
+			return errors.Wrapf(err, "unable to build image %s", alias)
+		}
+	}
+	return nil
+}
+
+// prepareBuildSecrets resolves the build secrets that are stored in the
+// secret store, and writes their values to temporary files so that they can
+// be mounted during the image build. The returned cleanup function removes
+// the temporary files and must always be called.
+func (p *Porter) prepareBuildSecrets(buildSecrets []build.Secret) (build.ImageOptions, func(), error) {
+	opts := build.ImageOptions{Secrets: make([]build.Secret, 0, len(buildSecrets))}
+	cleanup := func() {}
+
+	var tmpDir string
+	for _, s := range buildSecrets {
+		if s.Name != "" {
+			if tmpDir == "" {
+				var err error
+				tmpDir, err = p.FileSystem.TempDir("", "porter-build-secrets")
+				if err != nil {
+					return opts, cleanup, errors.Wrap(err, "could not create a temporary directory for the build secrets")
+				}
+				cleanup = func() {
+					p.FileSystem.RemoveAll(tmpDir)
+				}
+			}
+
+			value, err := p.Secrets.Resolve(secrets.SourceSecret, s.Name)
+			if err != nil {
+				return opts, cleanup, errors.Wrapf(err, "unable to resolve build secret %s from secret %s", s.ID, s.Name)
+			}
+
+			s.Source = filepath.Join(tmpDir, s.ID)
+			if err := p.FileSystem.WriteFile(s.Source, []byte(value), 0600); err != nil {
+				return opts, cleanup, errors.Wrapf(err, "could not write build secret %s", s.ID)
+			}
+		}
+		opts.Secrets = append(opts.Secrets, s)
+	}
+
+	return opts, cleanup, nil
+}
+
+// resolveBaseImage returns the base image of the invocation image, pinned to
+// the digest that the build uses so that a rebase replaces the exact layers
+// of the base image even after its tag moves. An empty string is returned
+// when the digest cannot be determined.
+func (p *Porter) resolveBaseImage(generator *build.DockerfileGenerator) (string, error) {
+	baseImage, err := generator.GetBaseImage()
+	if err != nil || baseImage == "" {
+		return "", err
+	}
+
+	digest, err := p.Builder.ResolveImageDigest(baseImage)
+	if err != nil {
+		fmt.Fprintf(p.Err, "WARNING: could not determine the digest of the base image %s, specify it with --old-base when rebasing the bundle: %s\n", baseImage, err)
+		return "", nil
+	}
+	if digest == "" {
+		fmt.Fprintf(p.Err, "WARNING: the base image %s was not pulled from a registry, specify it with --old-base when rebasing the bundle\n", baseImage)
+		return "", nil
+	}
+
+	return pinImageDigest(baseImage, digest)
+}
+
+// newBuildRecord captures when the bundle was built and the state of the git
+// repository containing the bundle, if any. When the manifest uses
+// version: auto and --version was not specified, the version derived from the
+// git tags is set on the build options.
+func (p *Porter) newBuildRecord(opts *BuildOptions) (*configadapter.BuildRecord, error) {
+	record := &configadapter.BuildRecord{Time: time.Now().UTC()}
+
+	info, err := vcs.Inspect(p.Context, filepath.Dir(p.FileSystem.Abs(opts.File)))
+	if err != nil {
+		// Bundles do not have to be in a git repository
+		if p.Debug {
+			fmt.Fprintln(p.Err, errors.Wrap(err, "not recording the git commit of the bundle"))
+		}
+	} else {
+		record.Commit = info.Commit
+		record.Dirty = info.Dirty
+	}
+
+	if opts.Version == "" {
+		m, err := manifest.ReadManifest(p.Context, opts.File)
+		if err != nil {
+			return nil, err
+		}
+
+		if m.Version == manifest.VersionAuto {
+			v, err := m.DeriveVersion(p.Context)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(p.Out, "Using version %s derived from git\n", v)
+			opts.Version = v
+		}
+	}
+	record.VersionOverride = opts.Version
+
+	return record, nil
 }
 
 func (p *Porter) preLint() error {
@@ -137,7 +335,7 @@ func (p *Porter) getUsedMixins() ([]mixin.Metadata, error) {
 	return usedMixins, nil
 }
 
-func (p *Porter) buildBundle(invocationImage string, digest string) error {
+func (p *Porter) buildBundle(invocationImage string, digest string, record *configadapter.BuildRecord) error {
 	imageDigests := map[string]string{invocationImage: digest}
 
 	mixins, err := p.getUsedMixins()
@@ -147,6 +345,7 @@ func (p *Porter) buildBundle(invocationImage string, digest string) error {
 	}
 
 	converter := configadapter.NewManifestConverter(p.Context, p.Manifest, imageDigests, mixins)
+	converter.BuildRecord = record
 	bun, err := converter.ToBundle()
 	if err != nil {
 		return err
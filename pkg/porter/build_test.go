@@ -2,6 +2,7 @@ package porter
 
 import (
 	"encoding/json"
+	"path/filepath"
 	"testing"
 
 	"get.porter.sh/porter/pkg/build"
@@ -73,6 +74,69 @@ func TestPorter_Build(t *testing.T) {
 	assert.Equal(t, false, debugDef.Default)
 }
 
+func TestPorter_BuildWithVersionOverride(t *testing.T) {
+	p := NewTestPorter(t)
+
+	err := p.Create()
+	require.NoError(t, err, "Create failed")
+
+	opts := BuildOptions{metadataOpts: metadataOpts{Version: "v1.2.3"}}
+	require.NoError(t, opts.Validate(p.Context), "Validate failed")
+
+	err = p.Build(opts)
+	require.NoError(t, err, "Build failed")
+
+	bun, err := p.CNAB.LoadBundle(build.LOCAL_BUNDLE)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", bun.Version)
+	require.Len(t, bun.InvocationImages, 1)
+	assert.Equal(t, "getporter/porter-hello-installer:v1.2.3", bun.InvocationImages[0].Image, "the version override should be used for the invocation image tag")
+
+	stamp, err := configadapter.LoadStamp(bun)
+	require.NoError(t, err)
+	require.NotNil(t, stamp.Build, "the build record was not stamped")
+	assert.Equal(t, "1.2.3", stamp.Build.VersionOverride)
+	assert.False(t, stamp.Build.Time.IsZero(), "the build time was not recorded")
+}
+
+func TestPorter_BuildRecordsBaseImageDigest(t *testing.T) {
+	t.Run("digest resolved", func(t *testing.T) {
+		p := NewTestPorter(t)
+		require.NoError(t, p.Create(), "Create failed")
+		testBuilder := p.Builder.(*TestBuildProvider)
+		testBuilder.ImageDigests = map[string]string{
+			"debian:stretch-slim": "sha256:7c2e21d2c6c4a8b5a8b2f2c1bd8d2fcb6b6d4d2e0d8e0c4e4f6e5b7d5a3c2b1a",
+		}
+
+		opts := BuildOptions{}
+		require.NoError(t, opts.Validate(p.Context), "Validate failed")
+		require.NoError(t, p.Build(opts), "Build failed")
+
+		bun, err := p.CNAB.LoadBundle(build.LOCAL_BUNDLE)
+		require.NoError(t, err)
+		stamp, err := configadapter.LoadStamp(bun)
+		require.NoError(t, err)
+		assert.Equal(t, "debian@sha256:7c2e21d2c6c4a8b5a8b2f2c1bd8d2fcb6b6d4d2e0d8e0c4e4f6e5b7d5a3c2b1a", stamp.Build.BaseImage,
+			"the base image should be pinned to the digest that was built from")
+	})
+
+	t.Run("digest unknown", func(t *testing.T) {
+		p := NewTestPorter(t)
+		require.NoError(t, p.Create(), "Create failed")
+
+		opts := BuildOptions{}
+		require.NoError(t, opts.Validate(p.Context), "Validate failed")
+		require.NoError(t, p.Build(opts), "Build failed")
+
+		bun, err := p.CNAB.LoadBundle(build.LOCAL_BUNDLE)
+		require.NoError(t, err)
+		stamp, err := configadapter.LoadStamp(bun)
+		require.NoError(t, err)
+		assert.Empty(t, stamp.Build.BaseImage, "a base image without a digest should not be recorded")
+		assert.Contains(t, p.TestConfig.TestContext.GetError(), "WARNING: the base image debian:stretch-slim was not pulled from a registry")
+	})
+}
+
 func TestPorter_LintDuringBuild(t *testing.T) {
 	lintResults := linter.Results{
 		{
@@ -123,7 +187,7 @@ func TestPorter_paramRequired(t *testing.T) {
 	err := p.LoadManifest()
 	require.NoError(t, err)
 
-	err = p.buildBundle("foo", "digest")
+	err = p.buildBundle("foo", "digest", nil)
 	require.NoError(t, err)
 
 	bundleBytes, err := p.FileSystem.ReadFile(build.LOCAL_BUNDLE)
@@ -177,3 +241,100 @@ func TestValidateBuildOpts(t *testing.T) {
 		})
 	}
 }
+
+func TestPorter_BuildWithSecrets(t *testing.T) {
+	p := NewTestPorter(t)
+	p.TestCredentials.TestSecrets.AddSecret("github-token", "topsecret")
+	p.TestConfig.TestContext.AddTestFileContents([]byte("//registry.npmjs.org/:_authToken=abc123"), "/home/me/.npmrc")
+
+	err := p.Create()
+	require.NoError(t, err, "Create failed")
+
+	opts := BuildOptions{Secrets: []string{"id=npmrc,src=/home/me/.npmrc", "id=token,secret=github-token"}}
+	err = opts.Validate(p.Context)
+	require.NoError(t, err, "Validate failed")
+
+	imageOpts, cleanup, err := p.prepareBuildSecrets(opts.parsedSecrets)
+	require.NoError(t, err, "prepareBuildSecrets failed")
+	require.Len(t, imageOpts.Secrets, 2)
+	assert.Equal(t, "/home/me/.npmrc", imageOpts.Secrets[0].Source, "file secrets should be passed through to the build")
+
+	tokenFile := imageOpts.Secrets[1].Source
+	gotToken, err := p.FileSystem.ReadFile(tokenFile)
+	require.NoError(t, err, "the resolved secret was not written to a file")
+	assert.Equal(t, "topsecret", string(gotToken))
+
+	cleanup()
+	exists, _ := p.FileSystem.Exists(tokenFile)
+	assert.False(t, exists, "the resolved secret should be removed after the build")
+
+	err = p.Build(opts)
+	require.NoError(t, err, "Build failed")
+	testBuilder := p.Builder.(*TestBuildProvider)
+	require.Len(t, testBuilder.ImageOptions.Secrets, 2, "the secrets were not passed to the builder")
+}
+
+func TestPorter_BuildApplicationImages(t *testing.T) {
+	p := NewTestPorter(t)
+
+	configTpl, err := p.Templates.GetManifest()
+	require.NoError(t, err)
+	images := `
+images:
+  web:
+    description: "The web frontend"
+    build:
+      context: web
+  mysql:
+    repository: mysql
+    tag: "5.7"
+`
+	p.TestConfig.TestContext.AddTestFileContents(append(configTpl, []byte(images)...), config.Name)
+
+	opts := BuildOptions{}
+	require.NoError(t, opts.Validate(p.Context), "Validate failed")
+	require.NoError(t, p.Build(opts), "Build failed")
+
+	testBuilder := p.Builder.(*TestBuildProvider)
+	require.Len(t, testBuilder.ApplicationImages, 1, "only the images with a build section should be built")
+	wd := p.Getwd()
+	assert.Equal(t, build.ApplicationImageOptions{
+		Image:      "getporter/porter-hello-web:v0.1.0",
+		Context:    filepath.Join(wd, "web"),
+		Dockerfile: "Dockerfile",
+	}, testBuilder.ApplicationImages[0])
+
+	bun, err := p.CNAB.LoadBundle(build.LOCAL_BUNDLE)
+	require.NoError(t, err)
+	assert.Equal(t, "getporter/porter-hello-web:v0.1.0", bun.Images["web"].Image, "the built image should be in the bundle")
+	assert.Equal(t, "mysql:5.7", bun.Images["mysql"].Image)
+}
+
+func TestValidateBuildOpts_Secrets(t *testing.T) {
+	p := NewTestPorter(t)
+	p.TestConfig.TestContext.AddTestFileContents([]byte("token"), "/home/me/.npmrc")
+
+	testcases := []struct {
+		name      string
+		secrets   []string
+		wantError string
+	}{
+		{name: "file", secrets: []string{"id=npmrc,src=/home/me/.npmrc"}},
+		{name: "secret store", secrets: []string{"id=npmrc,secret=npmrc"}},
+		{name: "missing file", secrets: []string{"id=npmrc,src=/missing"}, wantError: "unable to access the source file for secret npmrc"},
+		{name: "duplicate", secrets: []string{"id=npmrc,secret=a", "id=npmrc,secret=b"}, wantError: "secret npmrc is specified more than once"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := BuildOptions{Secrets: tc.secrets}
+			err := opts.Validate(p.Context)
+			if tc.wantError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
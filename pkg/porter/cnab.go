@@ -1,14 +1,18 @@
 package porter
 
 import (
+	"fmt"
 	"path/filepath"
+	"sort"
 
 	"get.porter.sh/porter/pkg/build"
 	"get.porter.sh/porter/pkg/cnab/drivers"
+	"get.porter.sh/porter/pkg/cnab/extensions"
 	cnabprovider "get.porter.sh/porter/pkg/cnab/provider"
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/context"
 	"get.porter.sh/porter/pkg/parameters"
+	"github.com/cnabio/cnab-go/bundle"
 	"github.com/pkg/errors"
 )
 
@@ -95,6 +99,9 @@ type sharedOptions struct {
 	// Driver is the CNAB-compliant driver used to run bundle actions.
 	Driver string
 
+	// driverDefaulted indicates that the driver was not specified and is the default driver.
+	driverDefaulted bool
+
 	// parsedParams is the parsed set of parameters from Params.
 	parsedParams map[string]string
 
@@ -103,6 +110,9 @@ type sharedOptions struct {
 
 	// combinedParameters is parsedParams merged on top of parsedParamSets.
 	combinedParameters map[string]string
+
+	// parameterMetadata are the deprecations and aliases of the bundle's parameters.
+	parameterMetadata extensions.PorterParameters
 }
 
 // Validate prepares for an action and validates the options.
@@ -131,6 +141,7 @@ func (o *sharedOptions) Validate(args []string, p *Porter) error {
 		return err
 	}
 
+	o.driverDefaulted = o.Driver == ""
 	o.defaultDriver()
 	err = o.validateDriver(p.Context)
 	if err != nil {
@@ -246,6 +257,11 @@ func (o *sharedOptions) LoadParameters(p *Porter) error {
 		return err
 	}
 
+	err = o.loadParameterMetadata(p)
+	if err != nil {
+		return err
+	}
+
 	o.combinedParameters = o.combineParameters(p.Context)
 
 	return nil
@@ -264,7 +280,7 @@ func (o *sharedOptions) parseParams() error {
 // parseParamSets parses the variable assignments in ParameterSets.
 func (o *sharedOptions) parseParamSets(p *Porter) error {
 	if len(o.ParameterSets) > 0 {
-		parsed, err := p.loadParameterSets(o.ParameterSets)
+		parsed, err := p.loadParameterSets(o.Name, o.ParameterSets)
 		if err != nil {
 			return errors.Wrapf(err, "unable to process provided parameter sets: %v", o.ParameterSets)
 		}
@@ -273,19 +289,54 @@ func (o *sharedOptions) parseParamSets(p *Porter) error {
 	return nil
 }
 
+// loadParameterMetadata reads the deprecations and aliases of the bundle's
+// parameters, from the bundle file or the bundle used by the installation.
+func (o *sharedOptions) loadParameterMetadata(p *Porter) error {
+	var bun bundle.Bundle
+	if o.CNABFile != "" {
+		b, err := p.CNAB.LoadBundle(o.CNABFile)
+		if err != nil {
+			// Let the action report that the bundle cannot be loaded
+			return nil
+		}
+		bun = b
+	} else if o.Name != "" {
+		c, err := p.Claims.ReadLastClaim(o.Name)
+		if err != nil {
+			// Let the action report that the installation does not exist
+			return nil
+		}
+		bun = c.Bundle
+	}
+
+	metadata, err := extensions.ReadPorterParameters(bun)
+	if err != nil {
+		return err
+	}
+	o.parameterMetadata = metadata
+	return nil
+}
+
 // Combine the parameters into a single map
 // The params set on the command line take precedence over the params set in
 // parameter set files
 // Anything set multiple times, is decided by "last one set wins"
+// Parameters set with an alias are renamed to the current name of the parameter.
 func (o *sharedOptions) combineParameters(c *context.Context) map[string]string {
 	final := make(map[string]string)
 
-	for k, v := range o.parsedParamSets {
-		final[k] = v
-	}
+	o.mergeParameters(c, final, o.parsedParamSets)
+	o.mergeParameters(c, final, o.parsedParams)
 
-	for k, v := range o.parsedParams {
-		final[k] = v
+	names := make([]string, 0, len(final))
+	for name := range final {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if msg, ok := o.parameterMetadata.GetDeprecation(name); ok {
+			fmt.Fprintf(c.Err, "WARNING: parameter %s is deprecated: %s\n", name, msg)
+		}
 	}
 
 	//
@@ -298,6 +349,30 @@ func (o *sharedOptions) combineParameters(c *context.Context) map[string]string
 	return final
 }
 
+// mergeParameters copies params into final, using the current name of any
+// parameter that was set with an alias. When a source sets both the alias and
+// the current name, the value for the current name is used.
+func (o *sharedOptions) mergeParameters(c *context.Context, final map[string]string, params map[string]string) {
+	var aliases []string
+	for k, v := range params {
+		if _, isAlias := o.parameterMetadata.ResolveName(k); isAlias {
+			aliases = append(aliases, k)
+			continue
+		}
+		final[k] = v
+	}
+
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		name, _ := o.parameterMetadata.ResolveName(alias)
+		fmt.Fprintf(c.Err, "WARNING: parameter %s has been renamed to %s\n", alias, name)
+		if _, ok := params[name]; ok {
+			continue
+		}
+		final[name] = params[alias]
+	}
+}
+
 // defaultDriver supplies the default driver if none is specified
 func (o *sharedOptions) defaultDriver() {
 	if o.Driver == "" {
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"get.porter.sh/porter/pkg/build"
+	"get.porter.sh/porter/pkg/cnab/extensions"
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/context"
 	"github.com/stretchr/testify/assert"
@@ -220,6 +221,31 @@ func TestSharedOptions_CombineParameters(t *testing.T) {
 			"expected param 'foo' to have override value, which has precedence over the parameter set value")
 	})
 
+	t.Run("alias renamed", func(t *testing.T) {
+		aliasContext := context.NewTestContext(t)
+		opts := sharedOptions{
+			parsedParams: map[string]string{
+				"location": "westus",
+			},
+			parsedParamSets: map[string]string{
+				"region":      "eastus",
+				"tshirt-size": "small",
+			},
+			parameterMetadata: extensions.PorterParameters{
+				"region":      {Aliases: []string{"location"}},
+				"tshirt-size": {Deprecated: "use node-count instead"},
+			},
+		}
+
+		params := opts.combineParameters(aliasContext.Context)
+		assert.Equal(t, "westus", params["region"], "expected the alias to set the renamed parameter")
+		assert.NotContains(t, params, "location", "expected the alias to be removed")
+
+		stderr := aliasContext.GetError()
+		assert.Contains(t, stderr, "WARNING: parameter location has been renamed to region")
+		assert.Contains(t, stderr, "WARNING: parameter tshirt-size is deprecated: use node-count instead")
+	})
+
 	t.Run("debug on", func(t *testing.T) {
 		var opts sharedOptions
 		debugContext := context.NewTestContext(t)
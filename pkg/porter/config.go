@@ -0,0 +1,95 @@
+package porter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"get.porter.sh/porter/pkg/config/datastore"
+	"get.porter.sh/porter/pkg/printer"
+	"github.com/pkg/errors"
+)
+
+var (
+	ConfigAllowedFormats = []printer.Format{printer.FormatPlaintext, printer.FormatYaml, printer.FormatJson}
+	ConfigDefaultFormat  = printer.FormatPlaintext
+)
+
+// ConfigShowOptions represent options for showing porter's configuration.
+type ConfigShowOptions struct {
+	printer.PrintOptions
+
+	// Origin includes where each setting was set, such as a config file or
+	// an environment variable.
+	Origin bool
+}
+
+// Validate the options for showing porter's configuration.
+func (o *ConfigShowOptions) Validate() error {
+	return o.PrintOptions.Validate(ConfigDefaultFormat, ConfigAllowedFormats)
+}
+
+// ShowConfig prints the settings from porter's config files and environment
+// variables, after they have been layered by precedence.
+func (p *Porter) ShowConfig(opts ConfigShowOptions) error {
+	settings, err := datastore.ListSettings(p.Config)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatPlaintext:
+		if len(settings) == 0 {
+			fmt.Fprintln(p.Out, "No configuration settings are defined")
+			return nil
+		}
+
+		if opts.Origin {
+			printSettingRow := func(v interface{}) []interface{} {
+				s, ok := v.(datastore.Setting)
+				if !ok {
+					return nil
+				}
+				return []interface{}{s.Key, formatSettingValue(s.Value), s.Origin}
+			}
+			return printer.PrintTable(p.Out, settings, printSettingRow, "KEY", "VALUE", "ORIGIN")
+		}
+
+		printSettingRow := func(v interface{}) []interface{} {
+			s, ok := v.(datastore.Setting)
+			if !ok {
+				return nil
+			}
+			return []interface{}{s.Key, formatSettingValue(s.Value)}
+		}
+		return printer.PrintTable(p.Out, settings, printSettingRow, "KEY", "VALUE")
+	case printer.FormatJson, printer.FormatYaml:
+		var output interface{} = settings
+		if !opts.Origin {
+			values := make(map[string]interface{}, len(settings))
+			for _, s := range settings {
+				values[s.Key] = s.Value
+			}
+			output = values
+		}
+
+		if opts.Format == printer.FormatJson {
+			return printer.PrintJson(p.Out, output)
+		}
+		return printer.PrintYaml(p.Out, output)
+	default:
+		return errors.Errorf("invalid format: %s", opts.Format)
+	}
+}
+
+// formatSettingValue prints lists and tables, such as storage, as compact json
+// so that each setting fits on a single line.
+func formatSettingValue(value interface{}) string {
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		b, err := json.Marshal(value)
+		if err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
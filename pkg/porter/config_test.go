@@ -0,0 +1,42 @@
+package porter
+
+import (
+	"testing"
+
+	"get.porter.sh/porter/pkg/printer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPorter_ShowConfig(t *testing.T) {
+	newPorter := func(t *testing.T) *TestPorter {
+		p := NewTestPorter(t)
+		home, _ := p.GetHomeDir()
+		p.TestConfig.TestContext.AddTestFileContents([]byte("debug = true\nread-only = true\n"), home+"/config.toml")
+		p.TestConfig.TestContext.AddTestFileContents([]byte("debug = false\n"), "/app/.porter/config.toml")
+		p.Chdir("/app")
+		return p
+	}
+
+	t.Run("origin", func(t *testing.T) {
+		p := newPorter(t)
+		opts := ConfigShowOptions{Origin: true}
+		require.NoError(t, opts.Validate())
+
+		require.NoError(t, p.ShowConfig(opts))
+		gotOutput := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, gotOutput, "ORIGIN")
+		assert.Regexp(t, `debug\s+false\s+/app/.porter/config.toml`, gotOutput)
+		assert.Regexp(t, `read-only\s+true\s+/root/.porter/config.toml`, gotOutput)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		p := newPorter(t)
+		opts := ConfigShowOptions{}
+		opts.RawFormat = string(printer.FormatJson)
+		require.NoError(t, opts.Validate())
+
+		require.NoError(t, p.ShowConfig(opts))
+		assert.JSONEq(t, `{"debug": false, "read-only": true}`, p.TestConfig.TestContext.GetOutput())
+	})
+}
@@ -93,6 +93,13 @@ func (g *CredentialOptions) validateCredName(args []string) error {
 // a silent build, based on the opts.Silent flag, or interactive using a survey. Returns an
 // error if unable to generate credentials
 func (p *Porter) GenerateCredentials(opts CredentialOptions) error {
+	if err := p.ensureWritable("generate credentials"); err != nil {
+		return err
+	}
+	if err := p.ensurePersistent("generate credentials"); err != nil {
+		return err
+	}
+
 	err := p.prepullBundleByReference(&opts.BundleActionOptions)
 	if err != nil {
 		return errors.Wrap(err, "unable to pull bundle before invoking credentials generate")
@@ -157,6 +164,13 @@ func (o *CredentialEditOptions) Validate(args []string) error {
 
 // EditCredential edits the credentials of the provided name.
 func (p *Porter) EditCredential(opts CredentialEditOptions) error {
+	if err := p.ensureWritable("edit credentials"); err != nil {
+		return err
+	}
+	if err := p.ensurePersistent("edit credentials"); err != nil {
+		return err
+	}
+
 	credSet, err := p.Credentials.Read(opts.Name)
 	if err != nil {
 		return err
@@ -256,6 +270,13 @@ type CredentialDeleteOptions struct {
 // DeleteCredential deletes the credential set corresponding to the provided
 // names.
 func (p *Porter) DeleteCredential(opts CredentialDeleteOptions) error {
+	if err := p.ensureWritable("delete credentials"); err != nil {
+		return err
+	}
+	if err := p.ensurePersistent("delete credentials"); err != nil {
+		return err
+	}
+
 	err := p.Credentials.Delete(opts.Name)
 	if err == crud.ErrRecordDoesNotExist {
 		if p.Debug {
@@ -37,6 +37,10 @@ func (o *DeleteOptions) Validate(args []string, cxt *context.Context) error {
 
 // DeleteInstallation handles deletion of an installation
 func (p *Porter) DeleteInstallation(opts DeleteOptions) error {
+	if err := p.ensureWritable("delete the installation"); err != nil {
+		return err
+	}
+
 	err := p.applyDefaultOptions(&opts.sharedOptions)
 	if err != nil {
 		return err
@@ -2,10 +2,12 @@ package porter
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"get.porter.sh/porter/pkg/cnab/extensions"
 	cnabprovider "get.porter.sh/porter/pkg/cnab/provider"
+	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/context"
 	"get.porter.sh/porter/pkg/manifest"
 	"get.porter.sh/porter/pkg/runtime"
@@ -30,6 +32,9 @@ type dependencyExecutioner struct {
 	parentOpts   *BundleActionOptions
 	parentArgs   cnabprovider.ActionArguments
 	deps         []*queuedDependency
+
+	// skipped are the optional dependencies whose condition is false
+	skipped []string
 }
 
 func newDependencyExecutioner(p *Porter, action string) *dependencyExecutioner {
@@ -117,6 +122,9 @@ func (e *dependencyExecutioner) PrepareRootActionArguments(args *cnabprovider.Ac
 		args.Files[target] = string(dep.cnabFileContents)
 	}
 
+	// Do not set parameters from the outputs of skipped dependencies
+	args.SkippedDependencies = e.skipped
+
 	// Remove parameters for dependencies
 	for key := range args.Params {
 		if strings.Contains(key, "#") {
@@ -153,41 +161,141 @@ func (e *dependencyExecutioner) identifyDependencies() error {
 		return errors.New("identifyDependencies failed to load the bundle because no bundle was specified. Please report this bug to https://github.com/getporter/porter/issues/new/choose")
 	}
 
-	solver := &extensions.DependencySolver{}
-	locks, err := solver.ResolveDependencies(bun)
+	e.deps = []*queuedDependency{}
+	e.skipped = nil
+	if !extensions.HasDependencies(bun) {
+		return nil
+	}
+
+	rawDeps, err := extensions.ReadDependencies(bun)
+	if err != nil {
+		return errors.Wrapf(err, "error executing dependencies for %s", bun.Name)
+	}
+
+	params, err := e.getConditionParameters(bun)
 	if err != nil {
 		return err
 	}
+	solver := &extensions.DependencySolver{}
+	for _, dep := range rawDeps.ListBySequence() {
+		execute, err := e.shouldExecuteDependency(dep, params)
+		if err != nil {
+			return err
+		}
+		if !execute {
+			e.skipped = append(e.skipped, dep.Name)
+			continue
+		}
 
-	e.deps = make([]*queuedDependency, len(locks))
-	for i, lock := range locks {
+		lock, err := solver.ResolveDependency(dep)
+		if err != nil {
+			return err
+		}
 		if e.Debug {
-			fmt.Fprintf(e.Out, "Resolved dependency %s to %s\n", lock.Alias, lock.Reference)
+			if lock.Path != "" {
+				fmt.Fprintf(e.Out, "Resolved dependency %s to the local bundle at %s\n", lock.Alias, lock.Path)
+			} else {
+				fmt.Fprintf(e.Out, "Resolved dependency %s to %s\n", lock.Alias, lock.Reference)
+			}
 		}
-		e.deps[i] = &queuedDependency{
+		e.deps = append(e.deps, &queuedDependency{
 			DependencyLock: lock,
-		}
+		})
 	}
 
 	return nil
 }
 
+// getConditionParameters returns the parameter values of the parent bundle
+// that are used to evaluate the conditions of optional dependencies. Parameters
+// that were not specified use the value from the last run of the installation,
+// unless it is being installed, and otherwise their default value.
+func (e *dependencyExecutioner) getConditionParameters(bun bundle.Bundle) (map[string]string, error) {
+	params := make(map[string]string, len(bun.Parameters))
+	for name, param := range bun.Parameters {
+		if def, ok := bun.Definitions[param.Definition]; ok && def.Default != nil {
+			params[name] = fmt.Sprintf("%v", def.Default)
+		}
+	}
+
+	if e.Action != claim.ActionInstall {
+		lastClaim, err := e.Claims.ReadLastClaim(e.parentArgs.Installation)
+		if err != nil {
+			if !strings.Contains(err.Error(), claim.ErrInstallationNotFound.Error()) {
+				return nil, errors.Wrapf(err, "could not read the last run of installation %s", e.parentArgs.Installation)
+			}
+		} else {
+			for name, value := range lastClaim.Parameters {
+				if _, ok := bun.Parameters[name]; ok && value != nil {
+					params[name] = fmt.Sprintf("%v", value)
+				}
+			}
+		}
+	}
+
+	for name, value := range e.parentArgs.Params {
+		params[name] = value
+	}
+	return params, nil
+}
+
+// shouldExecuteDependency determines if a dependency is executed, reporting
+// when an optional dependency is skipped.
+func (e *dependencyExecutioner) shouldExecuteDependency(dep extensions.Dependency, params map[string]string) (bool, error) {
+	execute, err := dep.ShouldExecute(params)
+	if err != nil || execute {
+		return execute, err
+	}
+
+	// Always clean up an optional dependency that was installed previously,
+	// even when the parameters used during uninstall no longer enable it
+	installation := extensions.BuildPrerequisiteInstallationName(e.parentArgs.Installation, dep.Name)
+	if _, err := e.Claims.ReadLastClaim(installation); err == nil {
+		if e.Action == claim.ActionUninstall {
+			fmt.Fprintf(e.Out, "Uninstalling optional dependency %s because it was installed previously, even though its condition %q is false\n", dep.Name, dep.Condition)
+			return true, nil
+		}
+
+		// Other actions leave it as-is, and the parent no longer uses its outputs
+		fmt.Fprintf(e.Err, "WARNING: optional dependency %s was installed previously, and is not executed because its condition %q is now false. Its outputs are not used to set parameters of installation %s, and the dependency is only removed when %s is uninstalled\n",
+			dep.Name, dep.Condition, e.parentArgs.Installation, e.parentArgs.Installation)
+	}
+
+	fmt.Fprintf(e.Out, "Skipping optional dependency %s because its condition %q is false\n", dep.Name, dep.Condition)
+	return false, nil
+}
+
 func (e *dependencyExecutioner) prepareDependency(dep *queuedDependency) error {
-	// Pull the dependency
+	var depBun bundle.Bundle
 	var err error
-	pullOpts := BundlePullOptions{
-		Reference:        dep.Reference,
-		InsecureRegistry: e.parentOpts.InsecureRegistry,
-		Force:            e.parentOpts.Force,
-	}
-	cachedDep, err := e.Resolver.Resolve(pullOpts)
-	if err != nil {
-		return errors.Wrapf(err, "error pulling dependency %s", dep.Alias)
+	if dep.Path != "" {
+		// Use the bundle built from the local directory
+		err = e.buildLocalDependency(dep)
+		if err != nil {
+			return err
+		}
+
+		depBun, err = e.CNAB.LoadBundle(dep.CNABFile)
+		if err != nil {
+			return errors.Wrapf(err, "error loading dependency %s", dep.Alias)
+		}
+	} else {
+		// Pull the dependency
+		pullOpts := BundlePullOptions{
+			Reference:        dep.Reference,
+			InsecureRegistry: e.parentOpts.InsecureRegistry,
+			Force:            e.parentOpts.Force,
+		}
+		cachedDep, err := e.Resolver.Resolve(pullOpts)
+		if err != nil {
+			return errors.Wrapf(err, "error pulling dependency %s", dep.Alias)
+		}
+		dep.CNABFile = cachedDep.BundlePath
+		dep.RelocationMapping = cachedDep.RelocationFilePath
+		depBun = cachedDep.Bundle
 	}
-	dep.CNABFile = cachedDep.BundlePath
-	dep.RelocationMapping = cachedDep.RelocationFilePath
 
-	err = cachedDep.Bundle.Validate()
+	err = depBun.Validate()
 	if err != nil {
 		return errors.Wrapf(err, "invalid bundle %s", dep.Alias)
 	}
@@ -200,7 +308,7 @@ func (e *dependencyExecutioner) prepareDependency(dep *queuedDependency) error {
 
 	// Make a lookup of which parameters are defined in the dependent bundle
 	depParams := map[string]struct{}{}
-	for paramName := range cachedDep.Bundle.Parameters {
+	for paramName := range depBun.Parameters {
 		depParams[paramName] = struct{}{}
 	}
 
@@ -248,6 +356,55 @@ func (e *dependencyExecutioner) prepareDependency(dep *queuedDependency) error {
 	return nil
 }
 
+// buildLocalDependency builds a path dependency from its local bundle
+// directory when it has changed since it was last built.
+func (e *dependencyExecutioner) buildLocalDependency(dep *queuedDependency) error {
+	dir := dep.Path
+	if !filepath.IsAbs(dir) {
+		if e.parentOpts.File == "" {
+			return errors.Errorf("dependency %s is a local path dependency, which can only be used when running the bundle from its porter.yaml", dep.Alias)
+		}
+		dir = filepath.Join(filepath.Dir(e.FileSystem.Abs(e.parentOpts.File)), dir)
+	}
+
+	// Building the dependency loads its manifest and enters its directory,
+	// so restore the parent bundle's manifest and directory afterwards
+	parentManifest := e.porter.Manifest
+	pwd := e.Getwd()
+	defer func() {
+		e.porter.Manifest = parentManifest
+		e.Chdir(pwd)
+	}()
+
+	opts := BuildOptions{}
+	opts.File = filepath.Join(dir, config.Name)
+	opts.Dir = dir
+	err := opts.Validate(e.Context)
+	if err != nil {
+		return errors.Wrapf(err, "invalid path for dependency %s", dep.Alias)
+	}
+
+	err = e.porter.LoadManifestFrom(opts.File)
+	if err != nil {
+		return errors.Wrapf(err, "invalid path for dependency %s", dep.Alias)
+	}
+
+	upToDate, err := e.porter.IsBundleUpToDate(opts.bundleFileOptions)
+	if err != nil {
+		fmt.Fprintf(e.Err, "WARNING: could not determine if dependency %s is up-to-date: %s\n", dep.Alias, err)
+	}
+	if !upToDate {
+		fmt.Fprintf(e.Out, "Building dependency %s from %s...\n", dep.Alias, dir)
+		err = e.porter.Build(opts)
+		if err != nil {
+			return errors.Wrapf(err, "error building dependency %s", dep.Alias)
+		}
+	}
+
+	dep.CNABFile = opts.CNABFile
+	return nil
+}
+
 func (e *dependencyExecutioner) executeDependency(dep *queuedDependency) error {
 	depArgs := cnabprovider.ActionArguments{
 		Action:            e.parentArgs.Action,
@@ -3,6 +3,9 @@ package porter
 import (
 	"testing"
 
+	"get.porter.sh/porter/pkg/cnab/extensions"
+	cnabprovider "get.porter.sh/porter/pkg/cnab/provider"
+	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-go/claim"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -32,3 +35,119 @@ func TestDependencyExecutioner_ExecuteBeforePrepare(t *testing.T) {
 	err = e.Execute()
 	require.NoError(t, err, "execute should not fail when we have called prepare")
 }
+
+func TestDependencyExecutioner_identifyDependencies_Optional(t *testing.T) {
+	testcases := []struct {
+		name        string
+		action      string
+		params      map[string]string
+		lastParams  map[string]interface{}
+		installed   bool
+		wantDeps    []string
+		wantSkipped []string
+		wantOutput  string
+		wantErr     string
+	}{
+		{name: "condition defaults to true", action: claim.ActionInstall, wantDeps: []string{"mysql", "redis"}},
+		{name: "condition is false", action: claim.ActionInstall, params: map[string]string{"use-existing-db": "true"},
+			wantDeps: []string{"redis"}, wantSkipped: []string{"mysql"}, wantOutput: `Skipping optional dependency mysql because its condition "!bundle.parameters.use-existing-db" is false`},
+		{name: "upgrade uses the last parameters", action: claim.ActionUpgrade, lastParams: map[string]interface{}{"use-existing-db": true},
+			wantDeps: []string{"redis"}, wantSkipped: []string{"mysql"}, wantOutput: "Skipping optional dependency mysql"},
+		{name: "upgrade when installed", action: claim.ActionUpgrade, lastParams: map[string]interface{}{"use-existing-db": true}, installed: true,
+			wantDeps: []string{"redis"}, wantSkipped: []string{"mysql"}, wantOutput: "Skipping optional dependency mysql",
+			wantErr: `WARNING: optional dependency mysql was installed previously, and is not executed because its condition "!bundle.parameters.use-existing-db" is now false. Its outputs are not used to set parameters of installation wordpress`},
+		{name: "upgrade overrides the last parameters", action: claim.ActionUpgrade, params: map[string]string{"use-existing-db": "false"},
+			lastParams: map[string]interface{}{"use-existing-db": true}, wantDeps: []string{"mysql", "redis"}},
+		{name: "install ignores the last parameters", action: claim.ActionInstall, lastParams: map[string]interface{}{"use-existing-db": true},
+			wantDeps: []string{"mysql", "redis"}},
+		{name: "uninstall when not installed", action: claim.ActionUninstall, params: map[string]string{"use-existing-db": "true"},
+			wantDeps: []string{"redis"}, wantSkipped: []string{"mysql"}, wantOutput: "Skipping optional dependency mysql"},
+		{name: "uninstall when installed", action: claim.ActionUninstall, params: map[string]string{"use-existing-db": "true"}, installed: true,
+			wantDeps: []string{"mysql", "redis"}, wantOutput: "Uninstalling optional dependency mysql because it was installed previously"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewTestPorter(t)
+			p.TestConfig.TestContext.AddTestFile("testdata/dependencies/optional-bundle.json", "/bundle.json")
+			if tc.lastParams != nil {
+				p.TestClaims.CreateClaim("wordpress", claim.ActionInstall, bundle.Bundle{}, tc.lastParams)
+			}
+			if tc.installed {
+				p.TestClaims.CreateClaim("wordpress-mysql", claim.ActionInstall, bundle.Bundle{}, nil)
+			}
+
+			e := newDependencyExecutioner(p.Porter, tc.action)
+			e.parentOpts = &BundleActionOptions{}
+			e.parentOpts.CNABFile = "/bundle.json"
+			e.parentArgs = cnabprovider.ActionArguments{Installation: "wordpress", Params: tc.params}
+
+			err := e.identifyDependencies()
+			require.NoError(t, err)
+
+			gotDeps := make([]string, len(e.deps))
+			for i, dep := range e.deps {
+				gotDeps[i] = dep.Alias
+			}
+			assert.Equal(t, tc.wantDeps, gotDeps)
+			assert.Contains(t, p.TestConfig.TestContext.GetOutput(), tc.wantOutput)
+			assert.Contains(t, p.TestConfig.TestContext.GetError(), tc.wantErr)
+
+			var args cnabprovider.ActionArguments
+			e.PrepareRootActionArguments(&args)
+			assert.Equal(t, tc.wantSkipped, args.SkippedDependencies, "the outputs of skipped dependencies should not set parameters")
+		})
+	}
+}
+
+func TestDependencyExecutioner_buildLocalDependency(t *testing.T) {
+	p := NewTestPorter(t)
+	require.NoError(t, p.FileSystem.MkdirAll("/mysql", 0755))
+	p.Chdir("/mysql")
+	require.NoError(t, p.Create(), "Create failed")
+	p.Chdir("/")
+
+	e := newDependencyExecutioner(p.Porter, claim.ActionInstall)
+	e.parentOpts = &BundleActionOptions{}
+	e.parentOpts.File = "/porter.yaml"
+
+	t.Run("stale", func(t *testing.T) {
+		dep := &queuedDependency{DependencyLock: extensions.DependencyLock{Alias: "mysql", Path: "mysql"}}
+		err := e.buildLocalDependency(dep)
+		require.NoError(t, err)
+
+		assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Building dependency mysql from /mysql")
+		assert.Equal(t, "/mysql/.cnab/bundle.json", dep.CNABFile)
+		assert.Equal(t, "/", p.Getwd(), "the working directory should be restored")
+	})
+
+	t.Run("up-to-date", func(t *testing.T) {
+		p.TestConfig.TestContext.ClearOutputs()
+
+		dep := &queuedDependency{DependencyLock: extensions.DependencyLock{Alias: "mysql", Path: "mysql"}}
+		err := e.buildLocalDependency(dep)
+		require.NoError(t, err)
+
+		assert.NotContains(t, p.TestConfig.TestContext.GetOutput(), "Building dependency mysql", "an up-to-date dependency should not be rebuilt")
+		assert.Equal(t, "/mysql/.cnab/bundle.json", dep.CNABFile)
+	})
+
+	t.Run("unknown if up-to-date", func(t *testing.T) {
+		p.TestConfig.TestContext.ClearOutputs()
+		require.NoError(t, p.FileSystem.WriteFile("/mysql/.cnab/bundle.json", []byte("{"), 0644))
+
+		dep := &queuedDependency{DependencyLock: extensions.DependencyLock{Alias: "mysql", Path: "mysql"}}
+		err := e.buildLocalDependency(dep)
+		require.NoError(t, err)
+
+		assert.Contains(t, p.TestConfig.TestContext.GetError(), "WARNING: could not determine if dependency mysql is up-to-date: ")
+		assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Building dependency mysql from /mysql")
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		dep := &queuedDependency{DependencyLock: extensions.DependencyLock{Alias: "redis", Path: "redis"}}
+		err := e.buildLocalDependency(dep)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid path for dependency redis")
+	})
+}
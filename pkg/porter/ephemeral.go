@@ -0,0 +1,307 @@
+package porter
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"get.porter.sh/porter/pkg/approvals"
+	"get.porter.sh/porter/pkg/claims"
+	cnabprovider "get.porter.sh/porter/pkg/cnab/provider"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/credentials"
+	"get.porter.sh/porter/pkg/parameters"
+	"get.porter.sh/porter/pkg/secrets"
+	inmemory "get.porter.sh/porter/pkg/secrets/in-memory"
+	"get.porter.sh/porter/pkg/storage"
+	"get.porter.sh/porter/pkg/yaml"
+	"github.com/cnabio/cnab-go/claim"
+	cnabsecrets "github.com/cnabio/cnab-go/secrets"
+	"github.com/cnabio/cnab-go/secrets/host"
+	"github.com/cnabio/cnab-go/utils/crud"
+	"github.com/pkg/errors"
+)
+
+// EphemeralSecretEnvPrefix is the prefix of environment variables that seed
+// the secrets available in ephemeral mode, for example
+// PORTER_EPHEMERAL_SECRET_password sets the secret named password.
+const EphemeralSecretEnvPrefix = "PORTER_EPHEMERAL_SECRET_"
+
+// EphemeralOptions are the global options for running porter in ephemeral mode.
+type EphemeralOptions struct {
+	// SecretsFile is a yaml or json file that maps secret names to their
+	// values, used to seed the in-memory secret store.
+	SecretsFile string
+
+	// StateFile is where the installations are loaded from, when it exists,
+	// and where the final state is saved when porter exits.
+	StateFile string
+
+	// IncludeSensitive saves the values of sensitive parameters and outputs
+	// in the state file, which are omitted by default.
+	IncludeSensitive bool
+}
+
+// EphemeralState is the state of the installations that were created or
+// modified in ephemeral mode.
+type EphemeralState struct {
+	Installations []EphemeralInstallation `json:"installations"`
+}
+
+// EphemeralInstallation is the record of an installation saved in the
+// ephemeral state file.
+type EphemeralInstallation struct {
+	Name    string            `json:"name"`
+	Claims  []claim.Claim     `json:"claims"`
+	Results []claim.Result    `json:"results"`
+	Outputs []EphemeralOutput `json:"outputs,omitempty"`
+}
+
+// EphemeralOutput is an output generated by one of the installation's results.
+type EphemeralOutput struct {
+	ResultID string `json:"resultId"`
+	Name     string `json:"name"`
+	Value    []byte `json:"value"`
+}
+
+// UseEphemeralStorage replaces Porter's storage and secrets with in-memory
+// implementations so that nothing is written to PORTER_HOME. Secrets are
+// seeded from the secrets file and environment, and installations from the
+// state file when it exists.
+func (p *Porter) UseEphemeralStorage(opts EphemeralOptions) error {
+	p.Ephemeral = true
+	p.ephemeralOpts = opts
+
+	secretStore := inmemory.NewStore()
+	if err := p.seedEphemeralSecrets(secretStore, opts.SecretsFile); err != nil {
+		return err
+	}
+	ephemeralSecrets := secrets.NewSecretStore(ephemeralSecretStore{secrets: secretStore, host: &host.SecretStore{}})
+
+	storageManager := storage.NewManager(p.Config, crud.NewMockStore())
+	claimStorage := claims.NewClaimStorage(storageManager)
+	credStorage := credentials.NewCredentialStorage(storageManager)
+	credStorage.SecretsStore = ephemeralSecrets
+	paramStorage := parameters.NewParameterStorage(storageManager)
+	paramStorage.SecretsStore = ephemeralSecrets
+
+	p.Storage = storageManager
+	p.Claims = claimStorage
+	p.Approvals = approvals.NewApprovalStore(storageManager)
+	p.Credentials = credStorage
+	p.Parameters = paramStorage
+	p.Secrets = ephemeralSecrets
+	p.CNAB = cnabprovider.NewRuntime(p.Config, claimStorage, credStorage, paramStorage)
+
+	return p.loadEphemeralState(opts.StateFile)
+}
+
+// ensurePersistent returns an error when Porter is running in ephemeral mode,
+// explaining which operation was refused.
+func (p *Porter) ensurePersistent(operation string) error {
+	if p.Ephemeral {
+		return errors.Wrapf(config.ErrEphemeral, "cannot %s", operation)
+	}
+	return nil
+}
+
+func (p *Porter) seedEphemeralSecrets(store *inmemory.Store, secretsFile string) error {
+	if secretsFile != "" {
+		data, err := p.FileSystem.ReadFile(secretsFile)
+		if err != nil {
+			return errors.Wrapf(err, "could not read the secrets file %s", secretsFile)
+		}
+
+		var values map[string]string
+		if err = yaml.Unmarshal(data, &values); err != nil {
+			return errors.Wrapf(err, "could not parse the secrets file %s, it should map secret names to their values", secretsFile)
+		}
+		for name, value := range values {
+			store.AddSecret(name, value)
+		}
+	}
+
+	// Environment variables take precedence over the secrets file
+	for key, value := range p.EnvironMap() {
+		if name := strings.TrimPrefix(key, EphemeralSecretEnvPrefix); name != key && name != "" {
+			store.AddSecret(name, value)
+		}
+	}
+
+	return nil
+}
+
+// loadEphemeralState saves the installations from a previous ephemeral
+// command into the in-memory storage.
+func (p *Porter) loadEphemeralState(stateFile string) error {
+	if stateFile == "" {
+		return nil
+	}
+
+	exists, err := p.FileSystem.Exists(stateFile)
+	if err != nil {
+		return errors.Wrapf(err, "could not check if the state file %s exists", stateFile)
+	}
+	if !exists {
+		return nil
+	}
+
+	data, err := p.FileSystem.ReadFile(stateFile)
+	if err != nil {
+		return errors.Wrapf(err, "could not read the state file %s", stateFile)
+	}
+
+	var state EphemeralState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return errors.Wrapf(err, "could not parse the state file %s", stateFile)
+	}
+
+	for _, i := range state.Installations {
+		records := importedRecords{claims: i.Claims, results: i.Results}
+		for _, o := range i.Outputs {
+			output, err := findEphemeralOutput(i, o)
+			if err != nil {
+				return errors.Wrapf(err, "invalid state for installation %s in %s", i.Name, stateFile)
+			}
+			records.outputs = append(records.outputs, output)
+		}
+		if err = p.saveImportedRecords(records); err != nil {
+			return errors.Wrapf(err, "could not load installation %s from the state file %s", i.Name, stateFile)
+		}
+	}
+
+	return nil
+}
+
+// findEphemeralOutput associates an output with the claim and result that generated it.
+func findEphemeralOutput(i EphemeralInstallation, o EphemeralOutput) (claim.Output, error) {
+	for _, r := range i.Results {
+		if r.ID != o.ResultID {
+			continue
+		}
+		for _, c := range i.Claims {
+			if c.ID == r.ClaimID {
+				return claim.NewOutput(c, r, o.Name, o.Value), nil
+			}
+		}
+	}
+	return claim.Output{}, errors.Errorf("output %s references result %s, which was not found", o.Name, o.ResultID)
+}
+
+// SaveEphemeralState writes the installations in the in-memory storage to
+// the state file. It does nothing unless Porter is running in ephemeral mode
+// with a state file.
+func (p *Porter) SaveEphemeralState() error {
+	stateFile := p.ephemeralOpts.StateFile
+	if !p.Ephemeral || stateFile == "" {
+		return nil
+	}
+
+	state, err := p.getEphemeralState()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not marshal the ephemeral state")
+	}
+
+	return errors.Wrapf(p.FileSystem.WriteFile(stateFile, data, 0600),
+		"could not write the state file %s", stateFile)
+}
+
+func (p *Porter) getEphemeralState() (EphemeralState, error) {
+	state := EphemeralState{Installations: []EphemeralInstallation{}}
+
+	names, err := p.Claims.ListInstallations()
+	if err != nil {
+		return state, errors.Wrap(err, "could not list installations")
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		installation, err := p.Claims.ReadInstallation(name)
+		if err != nil {
+			return state, errors.Wrapf(err, "could not read installation %s", name)
+		}
+
+		i := EphemeralInstallation{
+			Name:    name,
+			Claims:  []claim.Claim{},
+			Results: []claim.Result{},
+		}
+		for _, c := range installation.Claims {
+			if !p.ephemeralOpts.IncludeSensitive {
+				c = omitSensitiveParameters(c)
+			}
+			i.Claims = append(i.Claims, c)
+
+			results, err := p.Claims.ReadAllResults(c.ID)
+			if err != nil {
+				return state, errors.Wrapf(err, "could not read the results of claim %s", c.ID)
+			}
+			for _, r := range results {
+				i.Results = append(i.Results, r)
+
+				outputNames, err := p.Claims.ListOutputs(r.ID)
+				if err != nil {
+					return state, errors.Wrapf(err, "could not list the outputs of result %s", r.ID)
+				}
+				for _, outputName := range outputNames {
+					if sensitive, _ := c.Bundle.IsOutputSensitive(outputName); sensitive && !p.ephemeralOpts.IncludeSensitive {
+						continue
+					}
+
+					output, err := p.Claims.ReadOutput(c, r, outputName)
+					if err != nil {
+						return state, errors.Wrapf(err, "could not read output %s of result %s", outputName, r.ID)
+					}
+					i.Outputs = append(i.Outputs, EphemeralOutput{
+						ResultID: r.ID,
+						Name:     outputName,
+						Value:    output.Value,
+					})
+				}
+			}
+		}
+		state.Installations = append(state.Installations, i)
+	}
+
+	return state, nil
+}
+
+// omitSensitiveParameters returns a copy of the claim without the values of
+// its sensitive parameters.
+func omitSensitiveParameters(c claim.Claim) claim.Claim {
+	params := make(map[string]interface{}, len(c.Parameters))
+	for name, value := range c.Parameters {
+		if param, ok := c.Bundle.Parameters[name]; ok {
+			def, ok := c.Bundle.Definitions[param.Definition]
+			if ok && def.WriteOnly != nil && *def.WriteOnly {
+				continue
+			}
+		}
+		params[name] = value
+	}
+	c.Parameters = params
+	return c
+}
+
+var _ cnabsecrets.Store = ephemeralSecretStore{}
+
+// ephemeralSecretStore resolves secrets from the in-memory secret store, and
+// the other sources, such as env and path, from the host.
+type ephemeralSecretStore struct {
+	secrets *inmemory.Store
+	host    *host.SecretStore
+}
+
+func (s ephemeralSecretStore) Resolve(keyName string, keyValue string) (string, error) {
+	if keyName != secrets.SourceSecret {
+		return s.host.Resolve(keyName, keyValue)
+	}
+
+	value, err := s.secrets.Resolve(keyName, keyValue)
+	return value, errors.Wrapf(err, "secret %s was not set with %s%s or in the secrets file", keyValue, EphemeralSecretEnvPrefix, keyValue)
+}
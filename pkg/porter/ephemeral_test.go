@@ -0,0 +1,192 @@
+package porter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/cnabio/cnab-go/claim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPorter_UseEphemeralStorage_Secrets(t *testing.T) {
+	p := NewTestPorter(t)
+	p.TestConfig.TestContext.AddTestFileContents([]byte("password: from-file\ntoken: abc123\n"), "secrets.yaml")
+	p.Setenv(EphemeralSecretEnvPrefix+"password", "from-env")
+
+	require.NoError(t, p.UseEphemeralStorage(EphemeralOptions{SecretsFile: "secrets.yaml"}))
+
+	value, err := p.Secrets.Resolve("secret", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", value, "environment variables should override the secrets file")
+
+	value, err = p.Secrets.Resolve("secret", "token")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", value)
+
+	value, err = p.Secrets.Resolve("value", "plaintext")
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext", value, "other sources should be resolved from the host")
+
+	_, err = p.Secrets.Resolve("secret", "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secret missing was not set with PORTER_EPHEMERAL_SECRET_missing or in the secrets file")
+}
+
+func TestPorter_UseEphemeralStorage_InvalidSecretsFile(t *testing.T) {
+	p := NewTestPorter(t)
+	p.TestConfig.TestContext.AddTestFileContents([]byte("- not a map"), "secrets.yaml")
+
+	err := p.UseEphemeralStorage(EphemeralOptions{SecretsFile: "secrets.yaml"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not parse the secrets file secrets.yaml")
+}
+
+func TestPorter_EphemeralState(t *testing.T) {
+	p := NewTestPorter(t)
+	require.NoError(t, p.UseEphemeralStorage(EphemeralOptions{StateFile: "state.json"}))
+
+	bun := bundle.Bundle{
+		Name:    "mybuns",
+		Version: "0.1.0",
+		Outputs: map[string]bundle.Output{"connstr": {Definition: "connstr"}},
+		Definitions: map[string]*definition.Schema{
+			"connstr": {Type: "string"},
+		},
+	}
+	c, err := claim.New("mybuns", claim.ActionInstall, bun, nil)
+	require.NoError(t, err)
+	require.NoError(t, p.Claims.SaveClaim(c))
+	r, err := c.NewResult(claim.StatusSucceeded)
+	require.NoError(t, err)
+	require.NoError(t, p.Claims.SaveResult(r))
+	require.NoError(t, p.Claims.SaveOutput(claim.NewOutput(c, r, "connstr", []byte("localhost:5432"))))
+
+	require.NoError(t, p.SaveEphemeralState())
+	state, err := p.FileSystem.ReadFile("state.json")
+	require.NoError(t, err)
+	assert.Contains(t, string(state), `"name": "mybuns"`)
+
+	t.Run("load state", func(t *testing.T) {
+		p2 := NewTestPorter(t)
+		p2.TestConfig.TestContext.AddTestFileContents(state, "state.json")
+		require.NoError(t, p2.UseEphemeralStorage(EphemeralOptions{StateFile: "state.json"}))
+
+		installation, err := p2.Claims.ReadInstallation("mybuns")
+		require.NoError(t, err)
+		require.Len(t, installation.Claims, 1)
+		assert.Equal(t, c.ID, installation.Claims[0].ID)
+		assert.Equal(t, claim.StatusSucceeded, installation.GetLastStatus())
+
+		output, err := p2.Claims.ReadLastOutput("mybuns", "connstr")
+		require.NoError(t, err)
+		assert.Equal(t, "localhost:5432", string(output.Value))
+	})
+
+	t.Run("missing state file", func(t *testing.T) {
+		p3 := NewTestPorter(t)
+		require.NoError(t, p3.UseEphemeralStorage(EphemeralOptions{StateFile: "state.json"}))
+
+		names, err := p3.Claims.ListInstallations()
+		require.NoError(t, err)
+		assert.Empty(t, names)
+	})
+}
+
+func TestPorter_EphemeralState_Sensitive(t *testing.T) {
+	writeOnly := true
+	bun := bundle.Bundle{
+		Name:    "mybuns",
+		Version: "0.1.0",
+		Parameters: map[string]bundle.Parameter{
+			"password": {Definition: "secret"},
+			"region":   {Definition: "string"},
+		},
+		Outputs: map[string]bundle.Output{
+			"token":   {Definition: "secret"},
+			"connstr": {Definition: "string"},
+		},
+		Definitions: map[string]*definition.Schema{
+			"secret": {Type: "string", WriteOnly: &writeOnly},
+			"string": {Type: "string"},
+		},
+	}
+
+	saveState := func(t *testing.T, opts EphemeralOptions) EphemeralState {
+		p := NewTestPorter(t)
+		require.NoError(t, p.UseEphemeralStorage(opts))
+
+		c, err := claim.New("mybuns", claim.ActionInstall, bun, map[string]interface{}{"password": "topsecret", "region": "eastus"})
+		require.NoError(t, err)
+		require.NoError(t, p.Claims.SaveClaim(c))
+		r, err := c.NewResult(claim.StatusSucceeded)
+		require.NoError(t, err)
+		require.NoError(t, p.Claims.SaveResult(r))
+		require.NoError(t, p.Claims.SaveOutput(claim.NewOutput(c, r, "token", []byte("abc123"))))
+		require.NoError(t, p.Claims.SaveOutput(claim.NewOutput(c, r, "connstr", []byte("localhost:5432"))))
+
+		require.NoError(t, p.SaveEphemeralState())
+		data, err := p.FileSystem.ReadFile(opts.StateFile)
+		require.NoError(t, err)
+		var state EphemeralState
+		require.NoError(t, json.Unmarshal(data, &state))
+		require.Len(t, state.Installations, 1)
+
+		stored, err := p.Claims.ReadClaim(c.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "topsecret", stored.Parameters["password"], "the stored claim should not be modified")
+		return state
+	}
+
+	t.Run("omitted by default", func(t *testing.T) {
+		state := saveState(t, EphemeralOptions{StateFile: "state.json"})
+		i := state.Installations[0]
+
+		require.Len(t, i.Claims, 1)
+		assert.Equal(t, map[string]interface{}{"region": "eastus"}, i.Claims[0].Parameters)
+		require.Len(t, i.Outputs, 1)
+		assert.Equal(t, "connstr", i.Outputs[0].Name)
+	})
+
+	t.Run("included when requested", func(t *testing.T) {
+		state := saveState(t, EphemeralOptions{StateFile: "state.json", IncludeSensitive: true})
+		i := state.Installations[0]
+
+		require.Len(t, i.Claims, 1)
+		assert.Equal(t, "topsecret", i.Claims[0].Parameters["password"])
+		assert.Len(t, i.Outputs, 2)
+	})
+}
+
+func TestPorter_SaveEphemeralState_NotEphemeral(t *testing.T) {
+	p := NewTestPorter(t)
+	p.ephemeralOpts.StateFile = "state.json"
+
+	require.NoError(t, p.SaveEphemeralState())
+	exists, _ := p.FileSystem.Exists("state.json")
+	assert.False(t, exists, "the state should only be saved in ephemeral mode")
+}
+
+func TestPorter_Ephemeral_RefusesPersistentCommands(t *testing.T) {
+	p := NewTestPorter(t)
+	require.NoError(t, p.UseEphemeralStorage(EphemeralOptions{}))
+
+	t.Run("migrations are refused", func(t *testing.T) {
+		err := p.MigrateStorage()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot migrate storage: porter is running in ephemeral mode")
+	})
+
+	t.Run("credential edits are refused", func(t *testing.T) {
+		err := p.DeleteCredential(CredentialDeleteOptions{Name: "mycreds"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot delete credentials: porter is running in ephemeral mode")
+	})
+
+	t.Run("read commands are allowed", func(t *testing.T) {
+		_, err := p.ListInstallations()
+		require.NoError(t, err)
+	})
+}
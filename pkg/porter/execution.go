@@ -0,0 +1,117 @@
+package porter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cnabio/cnab-go/claim"
+	"github.com/pkg/errors"
+)
+
+// ExecutionSettingsClaimKey is the key in the custom section of a claim where
+// the settings used to execute the action are recorded.
+const ExecutionSettingsClaimKey = "sh.porter.execution"
+
+// ExecutionSettings are how an action was executed, recorded with the
+// installation so that later actions use the same settings by default.
+type ExecutionSettings struct {
+	// Driver used to execute the action.
+	Driver string `json:"driver,omitempty"`
+
+	// AllowDockerHostAccess indicates if the bundle had access to the host's Docker daemon.
+	AllowDockerHostAccess bool `json:"allowDockerHostAccess,omitempty"`
+
+	// RelocationMapping is the contents of the relocation mapping used for the action, if one was used.
+	RelocationMapping string `json:"relocationMapping,omitempty"`
+}
+
+// readExecutionSettings returns the execution settings recorded on the last
+// claim of an installation, or nil when there are none.
+func (p *Porter) readExecutionSettings(installation string) (*ExecutionSettings, error) {
+	c, err := p.Claims.ReadLastClaim(installation)
+	if err != nil {
+		if strings.Contains(err.Error(), claim.ErrInstallationNotFound.Error()) {
+			// The installation doesn't exist yet, the action will report a better error
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "could not read the execution settings of installation %s", installation)
+	}
+
+	custom, ok := c.Custom.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	data, ok := custom[ExecutionSettingsClaimKey]
+	if !ok {
+		return nil, nil
+	}
+
+	dataB, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not marshal the execution settings recorded on claim %s", c.ID)
+	}
+	var settings ExecutionSettings
+	if err = json.Unmarshal(dataB, &settings); err != nil {
+		return nil, errors.Wrapf(err, "could not unmarshal the execution settings recorded on claim %s", c.ID)
+	}
+	return &settings, nil
+}
+
+// resolveExecutionSettings defaults the driver, docker host access and
+// relocation mapping of an action to the settings recorded with the
+// installation, and warns when they are changed by the options.
+func (p *Porter) resolveExecutionSettings(action string, opts *BundleActionOptions) error {
+	if opts.executionSettingsResolved {
+		return nil
+	}
+
+	if opts.RelocationMapping != "" {
+		data, err := p.FileSystem.ReadFile(opts.RelocationMapping)
+		if err != nil {
+			return errors.Wrapf(err, "could not read relocation mapping %s", opts.RelocationMapping)
+		}
+		opts.relocationMappingContent = string(data)
+	}
+
+	var recorded *ExecutionSettings
+	if action != claim.ActionInstall {
+		var err error
+		recorded, err = p.readExecutionSettings(opts.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	if recorded != nil {
+		if opts.driverDefaulted && recorded.Driver != "" {
+			opts.Driver = recorded.Driver
+		} else if recorded.Driver != "" && opts.Driver != recorded.Driver {
+			fmt.Fprintf(p.Err, "WARNING: using the %s driver instead of the %s driver that installation %s used previously\n",
+				opts.Driver, recorded.Driver, opts.Name)
+		}
+
+		if !opts.AllowAccessToDockerHostSet {
+			opts.AllowAccessToDockerHost = recorded.AllowDockerHostAccess
+		} else if recorded.AllowDockerHostAccess && !opts.AllowAccessToDockerHost {
+			fmt.Fprintf(p.Err, "WARNING: not allowing access to the host's Docker daemon, which installation %s had previously\n", opts.Name)
+		} else if !recorded.AllowDockerHostAccess && opts.AllowAccessToDockerHost {
+			fmt.Fprintf(p.Err, "WARNING: allowing access to the host's Docker daemon, which installation %s did not have previously\n", opts.Name)
+		}
+
+		if opts.relocationMappingContent == "" {
+			opts.relocationMappingContent = recorded.RelocationMapping
+		} else if recorded.RelocationMapping != "" && opts.relocationMappingContent != recorded.RelocationMapping {
+			fmt.Fprintf(p.Err, "WARNING: using a different relocation mapping than installation %s used previously\n", opts.Name)
+		} else if recorded.RelocationMapping == "" {
+			fmt.Fprintf(p.Err, "WARNING: using a relocation mapping, which installation %s did not use previously\n", opts.Name)
+		}
+	}
+
+	if opts.Driver == "" {
+		opts.Driver = DefaultDriver
+	}
+
+	opts.executionSettingsResolved = true
+	return nil
+}
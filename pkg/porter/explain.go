@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	configadapter "get.porter.sh/porter/pkg/cnab/config-adapter"
 	"get.porter.sh/porter/pkg/cnab/extensions"
@@ -27,6 +28,11 @@ type PrintableBundle struct {
 	Description   string                `json:"description,omitempty" yaml:"description,omitempty"`
 	Version       string                `json:"version" yaml:"version"`
 	PorterVersion string                `json:"porterVersion,omitempty" yaml:"porterVersion,omitempty"`
+	Build         *PrintableBuild       `json:"build,omitempty" yaml:"build,omitempty"`
+	Maintainers   []bundle.Maintainer   `json:"maintainers,omitempty" yaml:"maintainers,omitempty"`
+	License       string                `json:"license,omitempty" yaml:"license,omitempty"`
+	Keywords      []string              `json:"keywords,omitempty" yaml:"keywords,omitempty"`
+	Links         *PrintableLinks       `json:"links,omitempty" yaml:"links,omitempty"`
 	Parameters    []PrintableParameter  `json:"parameters,omitempty" yaml:"parameters,omitempty"`
 	Credentials   []PrintableCredential `json:"credentials,omitempty" yaml:"credentials,omitempty"`
 	Outputs       []PrintableOutput     `json:"outputs,omitempty" yaml:"outputs,omitempty"`
@@ -34,6 +40,35 @@ type PrintableBundle struct {
 	Dependencies  []PrintableDependency `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
 }
 
+// PrintableBuild holds when and from which source the bundle was built.
+type PrintableBuild struct {
+	Time   time.Time `json:"time" yaml:"time"`
+	Commit string    `json:"commit,omitempty" yaml:"commit,omitempty"`
+	Dirty  bool      `json:"dirty,omitempty" yaml:"dirty,omitempty"`
+}
+
+// PrintableLinks holds the urls to additional information about a bundle.
+type PrintableLinks struct {
+	Source        string `json:"source,omitempty" yaml:"source,omitempty"`
+	Documentation string `json:"documentation,omitempty" yaml:"documentation,omitempty"`
+}
+
+// generatePrintableLinks returns the links defined on the bundle, or nil
+// when the bundle doesn't define any.
+func generatePrintableLinks(bun bundle.Bundle) (*PrintableLinks, error) {
+	links, err := extensions.ReadLinks(bun)
+	if err != nil {
+		return nil, err
+	}
+	if links.Source == "" && links.Documentation == "" {
+		return nil, nil
+	}
+	return &PrintableLinks{
+		Source:        links.Source,
+		Documentation: links.Documentation,
+	}, nil
+}
+
 type PrintableCredential struct {
 	Name        string `json:"name" yaml:"name"`
 	Description string `json:"description" yaml:"description"`
@@ -79,6 +114,7 @@ func (s SortPrintableOutput) Swap(i, j int) {
 type PrintableDependency struct {
 	Alias     string `json:"alias" yaml:"alias"`
 	Reference string `json:"reference" yaml:"reference"`
+	Path      string `json:"path,omitempty" yaml:"path,omitempty"`
 }
 
 type PrintableParameter struct {
@@ -88,6 +124,8 @@ type PrintableParameter struct {
 	ApplyTo     string      `json:"applyTo" yaml:"applyTo"`
 	Description string      `json:"description" yaml:"description"`
 	Required    bool        `json:"required" yaml:"required"`
+	Deprecated  string      `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Aliases     []string    `json:"aliases,omitempty" yaml:"aliases,omitempty"`
 }
 
 type SortPrintableParameter []PrintableParameter
@@ -203,6 +241,22 @@ func generatePrintable(bun bundle.Bundle, action string) (*PrintableBundle, erro
 		Description:   bun.Description,
 		Version:       bun.Version,
 		PorterVersion: stamp.Version,
+		Maintainers:   bun.Maintainers,
+		License:       bun.License,
+		Keywords:      bun.Keywords,
+	}
+
+	if stamp.Build != nil {
+		pb.Build = &PrintableBuild{
+			Time:   stamp.Build.Time,
+			Commit: stamp.Build.Commit,
+			Dirty:  stamp.Build.Dirty,
+		}
+	}
+
+	pb.Links, err = generatePrintableLinks(bun)
+	if err != nil {
+		return nil, err
 	}
 
 	actions := make([]PrintableAction, 0, len(bun.Actions))
@@ -230,6 +284,11 @@ func generatePrintable(bun bundle.Bundle, action string) (*PrintableBundle, erro
 	}
 	sort.Sort(SortPrintableCredential(creds))
 
+	paramMetadata, err := extensions.ReadPorterParameters(bun)
+	if err != nil {
+		return nil, err
+	}
+
 	params := make([]PrintableParameter, 0, len(bun.Parameters))
 	for p, v := range bun.Parameters {
 		if parameters.IsInternal(p, bun) {
@@ -249,6 +308,8 @@ func generatePrintable(bun bundle.Bundle, action string) (*PrintableBundle, erro
 		pp.ApplyTo = generateApplyToString(v.ApplyTo)
 		pp.Required = v.Required
 		pp.Description = v.Description
+		pp.Deprecated = paramMetadata[p].Deprecated
+		pp.Aliases = paramMetadata[p].Aliases
 
 		if shouldIncludeInExplainOutput(&v, action) {
 			params = append(params, pp)
@@ -288,6 +349,7 @@ func generatePrintable(bun bundle.Bundle, action string) (*PrintableBundle, erro
 		pd := PrintableDependency{}
 		pd.Alias = dep.Alias
 		pd.Reference = dep.Reference
+		pd.Path = dep.Path
 
 		dependencies = append(dependencies, pd)
 	}
@@ -325,6 +387,17 @@ func (p *Porter) printBundleExplainTable(bun *PrintableBundle) error {
 	if bun.PorterVersion != "" {
 		fmt.Fprintf(p.Out, "Porter Version: %s\n", bun.PorterVersion)
 	}
+	if bun.Build != nil {
+		fmt.Fprintf(p.Out, "Built: %s\n", bun.Build.Time.Format(time.RFC3339))
+		if bun.Build.Commit != "" {
+			commit := bun.Build.Commit
+			if bun.Build.Dirty {
+				commit += " (with uncommitted changes)"
+			}
+			fmt.Fprintf(p.Out, "Source Commit: %s\n", commit)
+		}
+	}
+	p.printBundleMetadata(bun.Maintainers, bun.License, bun.Keywords, bun.Links)
 	fmt.Fprintln(p.Out, "")
 
 	p.printCredentialsExplainBlock(bun)
@@ -335,6 +408,38 @@ func (p *Porter) printBundleExplainTable(bun *PrintableBundle) error {
 	return nil
 }
 
+// printBundleMetadata prints the optional catalog metadata for a bundle,
+// skipping any fields that the bundle doesn't define.
+func (p *Porter) printBundleMetadata(maintainers []bundle.Maintainer, license string, keywords []string, links *PrintableLinks) {
+	if license != "" {
+		fmt.Fprintf(p.Out, "License: %s\n", license)
+	}
+	if len(keywords) > 0 {
+		fmt.Fprintf(p.Out, "Keywords: %s\n", strings.Join(keywords, ", "))
+	}
+	if links != nil {
+		if links.Source != "" {
+			fmt.Fprintf(p.Out, "Source: %s\n", links.Source)
+		}
+		if links.Documentation != "" {
+			fmt.Fprintf(p.Out, "Documentation: %s\n", links.Documentation)
+		}
+	}
+	if len(maintainers) > 0 {
+		fmt.Fprintln(p.Out, "Maintainers:")
+		for _, m := range maintainers {
+			contact := m.Name
+			if m.Email != "" {
+				contact += fmt.Sprintf(" <%s>", m.Email)
+			}
+			if m.URL != "" {
+				contact += fmt.Sprintf(" (%s)", m.URL)
+			}
+			fmt.Fprintf(p.Out, "  - %s\n", contact)
+		}
+	}
+}
+
 func (p *Porter) printCredentialsExplainBlock(bun *PrintableBundle) error {
 	if len(bun.Credentials) > 0 {
 		fmt.Fprintln(p.Out, "Credentials:")
@@ -380,7 +485,11 @@ func (p *Porter) printParametersExplainTable(bun *PrintableBundle) error {
 			if !ok {
 				return nil
 			}
-			return []interface{}{p.Name, p.Description, p.Type, p.Default, p.Required, p.ApplyTo}
+			description := p.Description
+			if p.Deprecated != "" {
+				description = strings.TrimSpace(fmt.Sprintf("DEPRECATED: %s %s", p.Deprecated, description))
+			}
+			return []interface{}{p.Name, description, p.Type, p.Default, p.Required, p.ApplyTo}
 		}
 	return printer.PrintTable(p.Out, bun.Parameters, printParamRow, "Name", "Description", "Type", "Default", "Required", "Applies To")
 }
@@ -459,7 +568,11 @@ func (p *Porter) printDependenciesExplainTable(bun *PrintableBundle) error {
 			if !ok {
 				return nil
 			}
-			return []interface{}{o.Alias, o.Reference}
+			ref := o.Reference
+			if o.Path != "" {
+				ref = o.Path
+			}
+			return []interface{}{o.Alias, ref}
 		}
 	return printer.PrintTable(p.Out, bun.Dependencies, printDependencyRow, "Alias", "Reference")
 }
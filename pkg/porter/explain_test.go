@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"testing"
+	"time"
 
+	configadapter "get.porter.sh/porter/pkg/cnab/config-adapter"
 	"get.porter.sh/porter/pkg/cnab/extensions"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-go/bundle/definition"
@@ -234,6 +236,46 @@ func TestExplain_generatePrintableBundleParams(t *testing.T) {
 	assert.Equal(t, 0, len(pb.Actions))
 }
 
+func TestExplain_generatePrintableBundleDeprecatedParams(t *testing.T) {
+	bun := bundle.Bundle{
+		Definitions: definition.Definitions{
+			"string": &definition.Schema{
+				Type: "string",
+			},
+		},
+		Parameters: map[string]bundle.Parameter{
+			"region": {
+				Definition: "string",
+			},
+			"tshirt-size": {
+				Definition:  "string",
+				Description: "Size of the cluster",
+			},
+		},
+		Custom: map[string]interface{}{
+			extensions.ParametersExtensionKey: map[string]interface{}{
+				"region":      map[string]interface{}{"aliases": []string{"location"}},
+				"tshirt-size": map[string]interface{}{"deprecated": "use node-count instead"},
+			},
+		},
+	}
+
+	pb, err := generatePrintable(bun, "")
+	require.NoError(t, err)
+
+	require.Len(t, pb.Parameters, 2)
+	assert.Equal(t, []string{"location"}, pb.Parameters[0].Aliases)
+	assert.Empty(t, pb.Parameters[0].Deprecated)
+	assert.Equal(t, "use node-count instead", pb.Parameters[1].Deprecated)
+
+	p := NewTestPorter(t)
+	err = p.printParametersExplainTable(pb)
+	require.NoError(t, err)
+
+	gotOutput := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, gotOutput, "DEPRECATED: use node-count instead Size of the cluster")
+}
+
 func TestExplain_generatePrintableBundleParamsWithAction(t *testing.T) {
 	bun := bundle.Bundle{
 		RequiredExtensions: []string{
@@ -521,3 +563,87 @@ func TestExplain_generateJSONForDependencies(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, string(expected), gotOutput)
 }
+
+func TestExplain_generatePrintableBundleMetadata(t *testing.T) {
+	bun := bundle.Bundle{
+		Name:    "mybun",
+		Version: "0.1.0",
+		License: "Apache-2.0",
+		Maintainers: []bundle.Maintainer{
+			{Name: "Jane Doe", Email: "jane@example.com", URL: "https://example.com/jane"},
+			{Name: "John Doe"},
+		},
+		Keywords: []string{"hello", "demo"},
+		Custom: map[string]interface{}{
+			extensions.LinksExtensionKey: map[string]interface{}{
+				"source":        "https://github.com/getporter/hello",
+				"documentation": "https://porter.sh/hello",
+			},
+		},
+	}
+
+	pb, err := generatePrintable(bun, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Apache-2.0", pb.License)
+	assert.Equal(t, []string{"hello", "demo"}, pb.Keywords)
+	assert.Equal(t, bun.Maintainers, pb.Maintainers)
+	require.NotNil(t, pb.Links)
+	assert.Equal(t, "https://github.com/getporter/hello", pb.Links.Source)
+	assert.Equal(t, "https://porter.sh/hello", pb.Links.Documentation)
+
+	p := NewTestPorter(t)
+	err = p.printBundleExplainTable(pb)
+	require.NoError(t, err)
+
+	gotOutput := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, gotOutput, `Version: 0.1.0
+License: Apache-2.0
+Keywords: hello, demo
+Source: https://github.com/getporter/hello
+Documentation: https://porter.sh/hello
+Maintainers:
+  - Jane Doe <jane@example.com> (https://example.com/jane)
+  - John Doe
+`)
+}
+
+func TestExplain_generatePrintableBundleNoMetadata(t *testing.T) {
+	pb, err := generatePrintable(bundle.Bundle{Name: "mybun"}, "")
+	require.NoError(t, err)
+
+	assert.Nil(t, pb.Links, "links should be omitted when the bundle doesn't define any")
+}
+
+func TestExplain_generatePrintableBundleBuild(t *testing.T) {
+	buildTime := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	bun := bundle.Bundle{
+		Name:    "mybun",
+		Version: "1.2.4-dev.5+g1a2b3c4",
+		Custom: map[string]interface{}{
+			"sh.porter": configadapter.Stamp{
+				Version: "v0.30.0",
+				Build: &configadapter.BuildRecord{
+					Time:            buildTime,
+					VersionOverride: "1.2.4-dev.5+g1a2b3c4",
+					Commit:          "1a2b3c4d5e6f",
+					Dirty:           true,
+				},
+			},
+		},
+	}
+
+	pb, err := generatePrintable(bun, "")
+	require.NoError(t, err)
+	require.NotNil(t, pb.Build)
+	assert.Equal(t, buildTime, pb.Build.Time)
+	assert.Equal(t, "1a2b3c4d5e6f", pb.Build.Commit)
+	assert.True(t, pb.Build.Dirty)
+
+	p := NewTestPorter(t)
+	err = p.printBundleExplainTable(pb)
+	require.NoError(t, err)
+
+	gotOutput := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, gotOutput, "Porter Version: v0.30.0\nBuilt: 2020-10-01T12:00:00Z\nSource Commit: 1a2b3c4d5e6f (with uncommitted changes)\n")
+}
@@ -9,6 +9,8 @@ import (
 	"testing"
 	"time"
 
+	"get.porter.sh/porter/pkg/approvals"
+	"get.porter.sh/porter/pkg/build"
 	"get.porter.sh/porter/pkg/cache"
 	"get.porter.sh/porter/pkg/claims"
 	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
@@ -19,9 +21,11 @@ import (
 	"get.porter.sh/porter/pkg/mixin"
 	"get.porter.sh/porter/pkg/parameters"
 	"get.porter.sh/porter/pkg/plugins"
+	"get.porter.sh/porter/pkg/secrets"
 	"get.porter.sh/porter/pkg/yaml"
 	"github.com/cnabio/cnab-go/bundle"
 	cnabcreds "github.com/cnabio/cnab-go/credentials"
+	"github.com/cnabio/cnab-go/utils/crud"
 	"github.com/stretchr/testify/require"
 )
 
@@ -33,6 +37,7 @@ type TestPorter struct {
 	TestParameters  *parameters.TestParameterProvider
 	TestCache       *cache.TestCache
 	TestRegistry    *cnabtooci.TestRegistry
+	TestUsers       *approvals.TestUserProvider
 
 	// original directory where the test was being executed
 	TestDir string
@@ -53,6 +58,7 @@ func NewTestPorter(t *testing.T) *TestPorter {
 	testCache := cache.NewTestCache(cache.New(tc.Config))
 	testClaims := claims.NewTestClaimProvider(t)
 	testRegistry := cnabtooci.NewTestRegistry()
+	testUsers := approvals.NewTestUserProvider("test")
 
 	p := New()
 	p.Config = tc.Config
@@ -61,8 +67,11 @@ func NewTestPorter(t *testing.T) *TestPorter {
 	p.Cache = testCache
 	p.Builder = NewTestBuildProvider()
 	p.Claims = testClaims
+	p.Approvals = approvals.NewApprovalStore(crud.NewBackingStore(crud.NewMockStore()))
+	p.Users = testUsers
 	p.Credentials = testCredentials
 	p.Parameters = testParameters
+	p.Secrets = secrets.NewSecretStore(testCredentials.TestSecrets)
 	p.CNAB = cnabprovider.NewTestRuntimeWithConfig(tc, testClaims, testCredentials, testParameters)
 	p.Registry = testRegistry
 
@@ -74,6 +83,7 @@ func NewTestPorter(t *testing.T) *TestPorter {
 		TestParameters:  &testParameters,
 		TestCache:       testCache,
 		TestRegistry:    testRegistry,
+		TestUsers:       testUsers,
 		RepoRoot:        tc.TestContext.FindRepoRoot(),
 	}
 }
@@ -208,16 +218,37 @@ func (p *TestPorter) AddTestBundleDir(bundleDir string, generateUniqueName bool)
 }
 
 type TestBuildProvider struct {
+	// ImageOptions used in the last call to BuildInvocationImage
+	ImageOptions build.ImageOptions
+
+	// ApplicationImages are the options of each call to BuildApplicationImage
+	ApplicationImages []build.ApplicationImageOptions
+
+	// ImageDigests are the repository digests returned by ResolveImageDigest, keyed by image.
+	ImageDigests map[string]string
 }
 
 func NewTestBuildProvider() *TestBuildProvider {
 	return &TestBuildProvider{}
 }
 
-func (t *TestBuildProvider) BuildInvocationImage(manifest *manifest.Manifest) error {
+func (t *TestBuildProvider) BuildInvocationImage(manifest *manifest.Manifest, opts build.ImageOptions) error {
+	t.ImageOptions = opts
 	return nil
 }
 
 func (t *TestBuildProvider) TagInvocationImage(origTag, newTag string) error {
 	return nil
 }
+
+func (t *TestBuildProvider) BuildApplicationImage(opts build.ApplicationImageOptions) error {
+	t.ApplicationImages = append(t.ApplicationImages, opts)
+	return nil
+}
+
+func (t *TestBuildProvider) ResolveImageDigest(img string) (string, error) {
+	if t.ImageDigests == nil {
+		return "", nil
+	}
+	return t.ImageDigests[img], nil
+}
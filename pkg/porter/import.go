@@ -0,0 +1,342 @@
+package porter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"get.porter.sh/porter/pkg/context"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/storage"
+	"github.com/cnabio/cnab-go/claim"
+	"github.com/pkg/errors"
+)
+
+var (
+	ImportCNABAllowedFormats = []printer.Format{printer.FormatTable, printer.FormatYaml, printer.FormatJson}
+	ImportCNABDefaultFormat  = printer.FormatTable
+)
+
+const (
+	// ImportStatusImported indicates that all records for the installation were imported.
+	ImportStatusImported = "imported"
+
+	// ImportStatusSkipped indicates that the installation records could not be read or were invalid.
+	ImportStatusSkipped = "skipped"
+
+	// ImportStatusConflict indicates that the installation, or one of its claims,
+	// already exists in Porter's storage.
+	ImportStatusConflict = "conflict"
+)
+
+// ImportCNABOptions represent options for Porter's installations import-cnab command.
+type ImportCNABOptions struct {
+	printer.PrintOptions
+
+	// Dir is the directory containing claim data in the cnab-go filesystem layout.
+	Dir string
+}
+
+// Validate prepares for an import of claims from another CNAB tool and validates the args/options.
+func (o *ImportCNABOptions) Validate(args []string, cxt *context.Context) error {
+	if len(args) != 1 {
+		return errors.Errorf("expected a single argument, the directory containing the claims to import, but got %d", len(args))
+	}
+
+	o.Dir = cxt.FileSystem.Abs(args[0])
+	isDir, err := cxt.FileSystem.IsDir(o.Dir)
+	if err != nil || !isDir {
+		return errors.Errorf("%s is not a directory", args[0])
+	}
+
+	return o.PrintOptions.Validate(ImportCNABDefaultFormat, ImportCNABAllowedFormats)
+}
+
+// ImportedInstallation reports the outcome of importing an installation.
+type ImportedInstallation struct {
+	Installation string `json:"installation" yaml:"installation"`
+	Status       string `json:"status" yaml:"status"`
+	Claims       int    `json:"claims" yaml:"claims"`
+	Message      string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// importedRecords are the records of a single installation, normalized to the
+// current claim schema.
+type importedRecords struct {
+	claims  []claim.Claim
+	results []claim.Result
+	outputs []claim.Output
+}
+
+// ImportCNABClaims imports the installations found in a directory that uses
+// the cnab-go filesystem layout, and prints a report of what was imported.
+//
+// The directory may contain claims in the current layout, with claims in
+// claims/INSTALLATION/CLAIMID.json, results in results/CLAIMID/RESULTID.json and
+// outputs in outputs/RESULTID/RESULTID-OUTPUTNAME, or claims in the older
+// single document layout, claims/INSTALLATION.json.
+func (p *Porter) ImportCNABClaims(opts ImportCNABOptions) error {
+	if err := p.ensureWritable("import installations"); err != nil {
+		return err
+	}
+
+	report, err := p.importCNABClaims(opts.Dir)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, report)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, report)
+	case printer.FormatTable:
+		row :=
+			func(v interface{}) []interface{} {
+				i, ok := v.(ImportedInstallation)
+				if !ok {
+					return nil
+				}
+				return []interface{}{i.Installation, i.Status, i.Claims, i.Message}
+			}
+		return printer.PrintTable(p.Out, report, row,
+			"INSTALLATION", "STATUS", "CLAIMS", "MESSAGE")
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+}
+
+func (p *Porter) importCNABClaims(dir string) ([]ImportedInstallation, error) {
+	claimsDir := filepath.Join(dir, claim.ItemTypeClaims)
+	entries, err := p.FileSystem.ReadDir(claimsDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not list claims in %s", claimsDir)
+	}
+
+	existing, err := p.Claims.ListInstallations()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list existing installations")
+	}
+	sort.Strings(existing)
+
+	report := make([]ImportedInstallation, 0, len(entries))
+	for _, entry := range entries {
+		var name string
+		var records importedRecords
+		if entry.IsDir() {
+			name = entry.Name()
+			records, err = p.readCNABInstallation(dir, name)
+		} else if filepath.Ext(entry.Name()) == ".json" {
+			name = strings.TrimSuffix(entry.Name(), ".json")
+			records, err = p.readLegacyCNABInstallation(filepath.Join(claimsDir, entry.Name()), name)
+		} else {
+			continue
+		}
+
+		result := ImportedInstallation{Installation: name, Claims: len(records.claims)}
+		if err != nil {
+			result.Status = ImportStatusSkipped
+			result.Message = err.Error()
+		} else if i := sort.SearchStrings(existing, name); i < len(existing) && existing[i] == name {
+			result.Status = ImportStatusConflict
+			result.Message = "an installation with this name already exists"
+		} else if id, conflict := p.findConflictingClaim(records); conflict {
+			result.Status = ImportStatusConflict
+			result.Message = fmt.Sprintf("claim %s already exists", id)
+		} else if err = p.saveImportedRecords(records); err != nil {
+			result.Status = ImportStatusSkipped
+			result.Message = err.Error()
+		} else {
+			result.Status = ImportStatusImported
+		}
+		report = append(report, result)
+	}
+
+	return report, nil
+}
+
+// readCNABInstallation reads the claims, results and outputs for an
+// installation stored in the current cnab-go filesystem layout.
+func (p *Porter) readCNABInstallation(dir string, name string) (importedRecords, error) {
+	var records importedRecords
+
+	claimFiles, err := p.readCNABDir(filepath.Join(dir, claim.ItemTypeClaims, name))
+	if err != nil {
+		return records, err
+	}
+
+	for _, claimFile := range claimFiles {
+		var c claim.Claim
+		if err := p.readCNABFile(claimFile, &c); err != nil {
+			return records, err
+		}
+		if c.Installation != name {
+			return records, errors.Errorf("claim %s belongs to installation %q", c.ID, c.Installation)
+		}
+		if err := normalizeImportedClaim(&c); err != nil {
+			return records, err
+		}
+		records.claims = append(records.claims, c)
+
+		resultFiles, err := p.readCNABDir(filepath.Join(dir, claim.ItemTypeResults, c.ID))
+		if err != nil {
+			return records, err
+		}
+		for _, resultFile := range resultFiles {
+			var r claim.Result
+			if err := p.readCNABFile(resultFile, &r); err != nil {
+				return records, err
+			}
+			if err := normalizeImportedResult(&r); err != nil {
+				return records, errors.Wrapf(err, "invalid result %s", resultFile)
+			}
+			records.results = append(records.results, r)
+
+			outputsDir := filepath.Join(dir, claim.ItemTypeOutputs, r.ID)
+			outputFiles, err := p.FileSystem.ReadDir(outputsDir)
+			if err != nil {
+				// Not every result has outputs
+				continue
+			}
+			prefix := r.ID + "-"
+			for _, outputFile := range outputFiles {
+				if outputFile.IsDir() || !strings.HasPrefix(outputFile.Name(), prefix) {
+					continue
+				}
+				value, err := p.FileSystem.ReadFile(filepath.Join(outputsDir, outputFile.Name()))
+				if err != nil {
+					return records, errors.Wrapf(err, "could not read output %s", outputFile.Name())
+				}
+				outputName := strings.TrimPrefix(outputFile.Name(), prefix)
+				records.outputs = append(records.outputs, claim.NewOutput(c, r, outputName, value))
+			}
+		}
+	}
+
+	if len(records.claims) == 0 {
+		return records, errors.New("no claims found")
+	}
+	return records, nil
+}
+
+// readLegacyCNABInstallation reads an installation stored in the older single
+// document claim format, converting it with the same logic used to migrate
+// Porter's storage.
+func (p *Porter) readLegacyCNABInstallation(path string, name string) (importedRecords, error) {
+	var records importedRecords
+
+	data, err := p.FileSystem.ReadFile(path)
+	if err != nil {
+		return records, errors.Wrapf(err, "could not read %s", path)
+	}
+
+	var w = ioutil.Discard
+	if p.Debug {
+		w = p.Err
+	}
+	records.claims, records.results, records.outputs, err = storage.ConvertLegacyClaim(w, name, data)
+	if err != nil {
+		return importedRecords{}, err
+	}
+
+	for i := range records.claims {
+		if records.claims[i].Installation != name {
+			return importedRecords{}, errors.Errorf("claim belongs to installation %q", records.claims[i].Installation)
+		}
+		if err := normalizeImportedClaim(&records.claims[i]); err != nil {
+			return importedRecords{}, err
+		}
+	}
+	for i := range records.results {
+		if err := normalizeImportedResult(&records.results[i]); err != nil {
+			return importedRecords{}, err
+		}
+	}
+
+	return records, nil
+}
+
+// readCNABDir lists the json documents in a directory, sorted by name.
+func (p *Porter) readCNABDir(dir string) ([]string, error) {
+	files, err := p.FileSystem.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not list %s", dir)
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		if !f.IsDir() && filepath.Ext(f.Name()) == ".json" {
+			paths = append(paths, filepath.Join(dir, f.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (p *Porter) readCNABFile(path string, v interface{}) error {
+	data, err := p.FileSystem.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "could not read %s", path)
+	}
+
+	return errors.Wrapf(json.Unmarshal(data, v), "could not parse %s", path)
+}
+
+// normalizeImportedClaim updates a claim to the current claim schema and validates it.
+func normalizeImportedClaim(c *claim.Claim) error {
+	schemaVersion, err := claim.GetDefaultSchemaVersion()
+	if err != nil {
+		return err
+	}
+	c.SchemaVersion = schemaVersion
+
+	return errors.Wrapf(c.Validate(), "invalid claim %s", c.ID)
+}
+
+// normalizeImportedResult updates the status values used by older versions of
+// the claim schema and validates the result.
+func normalizeImportedResult(r *claim.Result) error {
+	switch r.Status {
+	case "success":
+		r.Status = claim.StatusSucceeded
+	case "failure":
+		r.Status = claim.StatusFailed
+	}
+
+	return r.Validate()
+}
+
+// findConflictingClaim checks if any of the imported claims already exist.
+func (p *Porter) findConflictingClaim(records importedRecords) (string, bool) {
+	for _, c := range records.claims {
+		if _, err := p.Claims.ReadClaim(c.ID); err == nil {
+			return c.ID, true
+		}
+	}
+	return "", false
+}
+
+func (p *Porter) saveImportedRecords(records importedRecords) error {
+	for _, c := range records.claims {
+		if err := p.Claims.SaveClaim(c); err != nil {
+			return errors.Wrapf(err, "could not save claim %s", c.ID)
+		}
+	}
+
+	for _, r := range records.results {
+		if err := p.Claims.SaveResult(r); err != nil {
+			return errors.Wrapf(err, "could not save result %s", r.ID)
+		}
+	}
+
+	for _, o := range records.outputs {
+		if err := p.Claims.SaveOutput(o); err != nil {
+			return errors.Wrapf(err, "could not save output %s", o.Name)
+		}
+	}
+
+	return nil
+}
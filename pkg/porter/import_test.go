@@ -0,0 +1,66 @@
+package porter
+
+import (
+	"testing"
+
+	"get.porter.sh/porter/pkg/printer"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/claim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPorter_ImportCNABClaims(t *testing.T) {
+	p := NewTestPorter(t)
+	p.TestConfig.TestContext.AddTestDirectory("testdata/import-cnab", "/cnab")
+
+	report, err := p.importCNABClaims("/cnab")
+	require.NoError(t, err, "import failed")
+	require.Len(t, report, 3)
+
+	byName := make(map[string]ImportedInstallation, len(report))
+	for _, i := range report {
+		byName[i.Installation] = i
+	}
+
+	assert.Equal(t, ImportStatusSkipped, byName["broken"].Status)
+
+	assert.Equal(t, ImportStatusImported, byName["example-exec-outputs"].Status)
+	assert.Equal(t, 1, byName["example-exec-outputs"].Claims)
+	outputs, err := p.Claims.ReadLastOutputs("example-exec-outputs")
+	require.NoError(t, err, "ReadLastOutputs failed")
+	assert.Equal(t, 2, outputs.Len())
+
+	assert.Equal(t, ImportStatusImported, byName["legacy"].Status)
+	assert.Equal(t, 2, byName["legacy"].Claims, "the legacy claim should be split into an install and upgrade claim")
+	i, err := p.Claims.ReadInstallation("legacy")
+	require.NoError(t, err, "ReadInstallation failed")
+	assert.Equal(t, claim.StatusSucceeded, i.GetLastStatus())
+}
+
+func TestPorter_ImportCNABClaims_Conflict(t *testing.T) {
+	p := NewTestPorter(t)
+	p.TestConfig.TestContext.AddTestDirectory("testdata/import-cnab", "/cnab")
+	p.TestClaims.CreateClaim("legacy", claim.ActionInstall, bundle.Bundle{}, nil)
+
+	opts := ImportCNABOptions{}
+	err := opts.Validate([]string{"/cnab"}, p.Context)
+	require.NoError(t, err, "Validate failed")
+	opts.Format = printer.FormatJson
+
+	err = p.ImportCNABClaims(opts)
+	require.NoError(t, err, "ImportCNABClaims failed")
+
+	gotOutput := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, gotOutput, `"installation": "legacy",
+    "status": "conflict"`)
+
+	// Importing the same data again should conflict with what was just imported
+	report, err := p.importCNABClaims("/cnab")
+	require.NoError(t, err, "import failed")
+	for _, i := range report {
+		if i.Installation != "broken" {
+			assert.Equal(t, ImportStatusConflict, i.Status, "expected %s to conflict", i.Installation)
+		}
+	}
+}
@@ -13,6 +13,10 @@ type InspectableBundle struct {
 	Name             string                     `json:"name" yaml:"name"`
 	Description      string                     `json:"description,omitempty" yaml:"description,omitempty"`
 	Version          string                     `json:"version" yaml:"version"`
+	Maintainers      []bundle.Maintainer        `json:"maintainers,omitempty" yaml:"maintainers,omitempty"`
+	License          string                     `json:"license,omitempty" yaml:"license,omitempty"`
+	Keywords         []string                   `json:"keywords,omitempty" yaml:"keywords,omitempty"`
+	Links            *PrintableLinks            `json:"links,omitempty" yaml:"links,omitempty"`
 	InvocationImages []PrintableInvocationImage `json:"invocationImages" yaml:"invocationImages"`
 	Images           []PrintableImage           `json:"images,omitempty" yaml:"images,omitempty"`
 }
@@ -70,7 +74,17 @@ func generateInspectableBundle(bun bundle.Bundle, reloMap map[string]string) (*I
 		Name:        bun.Name,
 		Description: bun.Description,
 		Version:     bun.Version,
+		Maintainers: bun.Maintainers,
+		License:     bun.License,
+		Keywords:    bun.Keywords,
 	}
+
+	var err error
+	ib.Links, err = generatePrintableLinks(bun)
+	if err != nil {
+		return nil, err
+	}
+
 	ib.InvocationImages, ib.Images = handleInspectRelocate(bun, reloMap)
 	return ib, nil
 }
@@ -119,6 +133,7 @@ func (p *Porter) printBundleInspectTable(bun *InspectableBundle) error {
 	fmt.Fprintf(p.Out, "Name: %s\n", bun.Name)
 	fmt.Fprintf(p.Out, "Description: %s\n", bun.Description)
 	fmt.Fprintf(p.Out, "Version: %s\n", bun.Version)
+	p.printBundleMetadata(bun.Maintainers, bun.License, bun.Keywords, bun.Links)
 	fmt.Fprintln(p.Out, "")
 
 	p.printInvocationImageInspectBlock(bun)
@@ -0,0 +1,39 @@
+package porter
+
+import (
+	"testing"
+
+	"get.porter.sh/porter/pkg/cnab/extensions"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect_generateInspectableBundleMetadata(t *testing.T) {
+	bun := bundle.Bundle{
+		Name:        "mybun",
+		Version:     "0.1.0",
+		License:     "MIT",
+		Maintainers: []bundle.Maintainer{{Name: "Jane Doe"}},
+		Keywords:    []string{"hello"},
+		Custom: map[string]interface{}{
+			extensions.LinksExtensionKey: extensions.Links{Documentation: "https://porter.sh/hello"},
+		},
+	}
+
+	ib, err := generateInspectableBundle(bun, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "MIT", ib.License)
+	assert.Equal(t, []string{"hello"}, ib.Keywords)
+	assert.Equal(t, bun.Maintainers, ib.Maintainers)
+	require.NotNil(t, ib.Links)
+	assert.Equal(t, "https://porter.sh/hello", ib.Links.Documentation)
+
+	p := NewTestPorter(t)
+	err = p.printBundleInspectTable(ib)
+	require.NoError(t, err)
+
+	gotOutput := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, gotOutput, "License: MIT\nKeywords: hello\nDocumentation: https://porter.sh/hello\nMaintainers:\n  - Jane Doe\n")
+}
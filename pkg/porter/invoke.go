@@ -37,5 +37,9 @@ func (o InvokeOptions) Validate(args []string, p *Porter) error {
 // InvokeBundle accepts a set of pre-validated InvokeOptions and uses
 // them to upgrade a bundle.
 func (p *Porter) InvokeBundle(opts InvokeOptions) error {
+	if opts.RequestApproval {
+		return p.requestApproval(opts)
+	}
+
 	return p.ExecuteAction(opts)
 }
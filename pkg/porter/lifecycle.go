@@ -1,6 +1,7 @@
 package porter
 
 import (
+	"get.porter.sh/porter/pkg/approvals"
 	cnabprovider "get.porter.sh/porter/pkg/cnab/provider"
 	"github.com/pkg/errors"
 )
@@ -23,6 +24,25 @@ type BundleActionOptions struct {
 	sharedOptions
 	BundlePullOptions
 	AllowAccessToDockerHost bool
+
+	// AllowAccessToDockerHostSet indicates that AllowAccessToDockerHost was
+	// specified, instead of defaulting to the setting recorded with the installation.
+	AllowAccessToDockerHostSet bool
+
+	// RequestApproval records a request for the action, which is executed once
+	// it is approved by another user, instead of executing it immediately.
+	RequestApproval bool
+
+	// approval of the action, recorded on the claim.
+	approval *approvals.Approval
+
+	// relocationMappingContent is the relocation mapping for the action, which
+	// is recorded on the claim.
+	relocationMappingContent string
+
+	// executionSettingsResolved indicates that the execution settings recorded
+	// with the installation were already applied to the options.
+	executionSettingsResolved bool
 }
 
 func (o *BundleActionOptions) Validate(args []string, porter *Porter) error {
@@ -59,6 +79,13 @@ func (o *BundleActionOptions) GetOptions() *BundleActionOptions {
 // that can be used to execute the action.
 func (p *Porter) BuildActionArgs(action BundleAction) (cnabprovider.ActionArguments, error) {
 	opts := action.GetOptions()
+
+	// Default to how the installation was executed previously
+	err := p.resolveExecutionSettings(action.GetAction(), opts)
+	if err != nil {
+		return cnabprovider.ActionArguments{}, err
+	}
+
 	args := cnabprovider.ActionArguments{
 		Action:                action.GetAction(),
 		Installation:          opts.Name,
@@ -68,9 +95,21 @@ func (p *Porter) BuildActionArgs(action BundleAction) (cnabprovider.ActionArgume
 		Driver:                opts.Driver,
 		RelocationMapping:     opts.RelocationMapping,
 		AllowDockerHostAccess: opts.AllowAccessToDockerHost,
+		Custom: map[string]interface{}{
+			ExecutionSettingsClaimKey: ExecutionSettings{
+				Driver:                opts.Driver,
+				AllowDockerHostAccess: opts.AllowAccessToDockerHost,
+				RelocationMapping:     opts.relocationMappingContent,
+			},
+		},
 	}
 
-	err := opts.LoadParameters(p)
+	// Use the relocation mapping recorded with the installation
+	if args.RelocationMapping == "" {
+		args.RelocationMappingContent = opts.relocationMappingContent
+	}
+
+	err = opts.LoadParameters(p)
 	if err != nil {
 		return cnabprovider.ActionArguments{}, err
 	}
@@ -82,6 +121,14 @@ func (p *Porter) BuildActionArgs(action BundleAction) (cnabprovider.ActionArgume
 	}
 	copy(args.CredentialIdentifiers, opts.CredentialIdentifiers)
 
+	// The approval only applies to this action, so remove any approval
+	// carried over from the previous claim
+	if opts.approval != nil {
+		args.Custom[approvals.ClaimExtensionKey] = *opts.approval
+	} else {
+		args.Custom[approvals.ClaimExtensionKey] = nil
+	}
+
 	return args, nil
 }
 
@@ -7,6 +7,7 @@ import (
 
 	"get.porter.sh/porter/pkg/config"
 	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/claim"
 	"github.com/cnabio/cnab-to-oci/relocation"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -131,6 +132,7 @@ func TestPorter_BuildActionArgs(t *testing.T) {
 	t.Run("remaining fields", func(t *testing.T) {
 		p := NewTestPorter(t)
 		p.TestConfig.TestContext.AddTestFile("testdata/porter.yaml", "porter.yaml")
+		p.TestConfig.TestContext.AddTestFileContents([]byte("{}"), "relocation-mapping.json")
 		opts := InstallOptions{
 			BundleActionOptions: &BundleActionOptions{
 				sharedOptions: sharedOptions{
@@ -176,6 +178,79 @@ func TestPorter_BuildActionArgs(t *testing.T) {
 	})
 }
 
+func TestPorter_BuildActionArgs_ExecutionSettings(t *testing.T) {
+	p := NewTestPorter(t)
+	p.TestConfig.TestContext.AddTestFile("testdata/porter.yaml", "porter.yaml")
+	p.TestConfig.TestContext.AddTestFile("testdata/bundle.json", ".cnab/bundle.json")
+	reloMap := `{"getporter/mysql:5.7":"example.com/mysql:5.7"}`
+	p.TestConfig.TestContext.AddTestFileContents([]byte(reloMap), "relocation-mapping.json")
+
+	installOpts := NewInstallOptions()
+	installOpts.Name = "mybuns"
+	installOpts.Driver = DebugDriver
+	installOpts.AllowAccessToDockerHost = true
+	require.NoError(t, installOpts.Validate(nil, p.Porter), "Validate failed")
+	installOpts.RelocationMapping = "relocation-mapping.json"
+	installArgs, err := p.BuildActionArgs(installOpts)
+	require.NoError(t, err, "BuildActionArgs failed")
+
+	assert.Equal(t, ExecutionSettings{Driver: DebugDriver, AllowDockerHostAccess: true, RelocationMapping: reloMap},
+		installArgs.Custom[ExecutionSettingsClaimKey], "the execution settings should be recorded on the claim")
+
+	// Record the install like the runtime would
+	c, err := claim.New("mybuns", claim.ActionInstall, bundle.Bundle{Name: "mybuns", Version: "0.1.0"}, nil)
+	require.NoError(t, err)
+	c.Custom = installArgs.Custom
+	require.NoError(t, p.Claims.SaveClaim(c))
+
+	t.Run("defaults to the recorded settings", func(t *testing.T) {
+		opts := NewUpgradeOptions()
+		opts.Name = "mybuns"
+		require.NoError(t, opts.Validate(nil, p.Porter), "Validate failed")
+		args, err := p.BuildActionArgs(opts)
+		require.NoError(t, err, "BuildActionArgs failed")
+
+		assert.Equal(t, DebugDriver, args.Driver, "the driver should default to the recorded driver")
+		assert.True(t, args.AllowDockerHostAccess, "docker host access should default to the recorded setting")
+		assert.Empty(t, args.RelocationMapping)
+		assert.Equal(t, reloMap, args.RelocationMappingContent, "the relocation mapping should default to the recorded mapping")
+		assert.Empty(t, p.TestConfig.TestContext.GetError(), "no warnings should be printed when the settings are not changed")
+	})
+
+	t.Run("warns when the settings are changed", func(t *testing.T) {
+		p.TestConfig.TestContext.AddTestFileContents([]byte(`{}`), "other-mapping.json")
+
+		opts := NewUpgradeOptions()
+		opts.Name = "mybuns"
+		opts.Driver = DockerDriver
+		require.NoError(t, opts.Validate(nil, p.Porter), "Validate failed")
+		opts.RelocationMapping = "other-mapping.json"
+		args, err := p.BuildActionArgs(opts)
+		require.NoError(t, err, "BuildActionArgs failed")
+
+		assert.Equal(t, DockerDriver, args.Driver, "an explicit driver should override the recorded driver")
+		assert.Equal(t, "other-mapping.json", args.RelocationMapping)
+		stderr := p.TestConfig.TestContext.GetError()
+		assert.Contains(t, stderr, "WARNING: using the docker driver instead of the debug driver that installation mybuns used previously")
+		assert.Contains(t, stderr, "WARNING: using a different relocation mapping than installation mybuns used previously")
+	})
+
+	t.Run("explicitly disallowing docker host access", func(t *testing.T) {
+		p.TestConfig.TestContext.ClearOutputs()
+
+		opts := NewUpgradeOptions()
+		opts.Name = "mybuns"
+		opts.AllowAccessToDockerHost = false
+		opts.AllowAccessToDockerHostSet = true
+		require.NoError(t, opts.Validate(nil, p.Porter), "Validate failed")
+		args, err := p.BuildActionArgs(opts)
+		require.NoError(t, err, "BuildActionArgs failed")
+
+		assert.False(t, args.AllowDockerHostAccess, "an explicit false should override the recorded setting")
+		assert.Contains(t, p.TestConfig.TestContext.GetError(), "WARNING: not allowing access to the host's Docker daemon, which installation mybuns had previously")
+	})
+}
+
 func TestManifestIgnoredWithTag(t *testing.T) {
 	p := NewTestPorter(t)
 	t.Run("ignore manifest in cwd if tag present", func(t *testing.T) {
@@ -63,6 +63,12 @@ func (p *Porter) Lint(opts LintOptions) (linter.Results, error) {
 	}
 
 	l := linter.New(p.Context, p.Mixins)
+	if p.Data != nil && len(p.Data.LintRulePacks) > 0 {
+		l.RulePacks, err = linter.LoadRulePacks(p.Context, p.Data.LintRulePacks)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return l.Lint(p.Manifest)
 }
 
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"testing"
 
+	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/linter"
 	"get.porter.sh/porter/pkg/mixin"
 	"github.com/stretchr/testify/assert"
@@ -30,6 +31,32 @@ func TestPorter_Lint(t *testing.T) {
 	assert.Len(t, results, 1, "Lint returned the wrong number of results")
 }
 
+func TestPorter_Lint_RulePacks(t *testing.T) {
+	p := NewTestPorter(t)
+	p.TestConfig.TestContext.AddTestFile("testdata/porter.yaml", "porter.yaml")
+	p.TestConfig.TestContext.AddTestFile("testdata/lint/rules.yaml", "/home/me/.porter/rules.yaml")
+	p.Data = &config.Data{LintRulePacks: []string{"/home/me/.porter/rules.yaml"}}
+
+	var opts LintOptions
+	err := opts.Validate(p.Context)
+	require.NoError(t, err, "Validate failed")
+
+	results, err := p.Lint(opts)
+	require.NoError(t, err, "Lint failed")
+	require.Len(t, results, 1, "Lint returned the wrong number of results")
+	assert.Equal(t, linter.Code("acme-101"), results[0].Code)
+	assert.Equal(t, "maintainers", results[0].Location.Path)
+	assert.Equal(t, "https://example.com/bundle-standards#maintainers", results[0].URL)
+
+	t.Run("invalid rule pack", func(t *testing.T) {
+		p.TestConfig.TestContext.AddTestFileContents([]byte("rules:\n  - code: acme-100\n    type: unknown\n    path: description\n"), "/home/me/.porter/rules.yaml")
+
+		_, err := p.Lint(opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid rule 1 in the lint rule pack /home/me/.porter/rules.yaml")
+	})
+}
+
 func TestPorter_PrintLintResults(t *testing.T) {
 	lintResults := linter.Results{
 		{
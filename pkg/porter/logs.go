@@ -2,9 +2,13 @@ package porter
 
 import (
 	"fmt"
+	"time"
 
 	"get.porter.sh/porter/pkg/context"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/runtime/steplogs"
 	"github.com/cnabio/cnab-go/claim"
+	"github.com/dustin/go-humanize"
 	"github.com/pkg/errors"
 )
 
@@ -12,6 +16,16 @@ import (
 type LogsShowOptions struct {
 	sharedOptions
 	ClaimID string
+
+	// Step is the number of the step to show the logs of, starting from 1.
+	Step int
+
+	// FailedStep shows the logs of the step that failed.
+	FailedStep bool
+
+	// Summary lists each step with its status and the size of its logs,
+	// instead of showing the logs.
+	Summary bool
 }
 
 // Installation name passed to the command.
@@ -36,6 +50,20 @@ func (o *LogsShowOptions) Validate(cxt *context.Context) error {
 		return errors.New("either --installation or --run is required")
 	}
 
+	if o.Step < 0 {
+		return errors.Errorf("invalid --step %d, steps are numbered starting from 1", o.Step)
+	}
+
+	selected := 0
+	for _, set := range []bool{o.Step > 0, o.FailedStep, o.Summary} {
+		if set {
+			selected++
+		}
+	}
+	if selected > 1 {
+		return errors.New("only one of --step, --failed-step or --summary can be specified")
+	}
+
 	return nil
 }
 
@@ -50,10 +78,70 @@ func (p *Porter) ShowInstallationLogs(opts *LogsShowOptions) error {
 		return errors.New("no logs found")
 	}
 
-	fmt.Fprintln(p.Out, logs)
+	if opts.Step == 0 && !opts.FailedStep && !opts.Summary {
+		fmt.Fprintln(p.Out, steplogs.Strip(logs))
+		return nil
+	}
+
+	steps := steplogs.Parse(logs)
+	if len(steps) == 0 {
+		return errors.New("the logs are not split into steps because they were created by an older version of porter, show the logs without --step, --failed-step or --summary")
+	}
+
+	if opts.Summary {
+		return p.printStepLogsSummary(steps)
+	}
+
+	step, err := selectStepLogs(steps, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(p.Out, step.Logs)
 	return nil
 }
 
+// selectStepLogs finds the step requested with --step or --failed-step.
+func selectStepLogs(steps []steplogs.Step, opts *LogsShowOptions) (steplogs.Step, error) {
+	if opts.FailedStep {
+		for _, step := range steps {
+			if step.Status == steplogs.StatusFailed {
+				return step, nil
+			}
+		}
+
+		// When the run was stopped, the last step may not have recorded its end
+		last := steps[len(steps)-1]
+		if last.Status == steplogs.StatusUnknown {
+			return last, nil
+		}
+		return steplogs.Step{}, errors.New("no step failed")
+	}
+
+	for _, step := range steps {
+		if step.Index == opts.Step {
+			return step, nil
+		}
+	}
+	return steplogs.Step{}, errors.Errorf("invalid --step %d, the run only executed %d steps", opts.Step, len(steps))
+}
+
+func (p *Porter) printStepLogsSummary(steps []steplogs.Step) error {
+	printStepRow := func(v interface{}) []interface{} {
+		step, ok := v.(steplogs.Step)
+		if !ok {
+			return nil
+		}
+
+		duration := ""
+		if step.Duration() > 0 {
+			duration = step.Duration().Round(time.Millisecond).String()
+		}
+		return []interface{}{step.Index, step.Description, step.Mixin, step.Status, duration, humanize.Bytes(uint64(step.Size()))}
+	}
+	return printer.PrintTable(p.Out, steps, printStepRow, "STEP", "DESCRIPTION", "MIXIN", "STATUS", "DURATION", "SIZE")
+}
+
 // GetInstallationLogs gets logs for an installation, according to the provided options
 func (p *Porter) GetInstallationLogs(opts *LogsShowOptions) (string, bool, error) {
 	err := p.applyDefaultOptions(&opts.sharedOptions)
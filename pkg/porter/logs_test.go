@@ -1,6 +1,7 @@
 package porter
 
 import (
+	"io/ioutil"
 	"testing"
 
 	"get.porter.sh/porter/pkg/context"
@@ -52,6 +53,15 @@ func TestLogsShowOptions_Validate(t *testing.T) {
 		assert.Contains(t, err.Error(), "either --installation or --run should be specified, not both")
 	})
 
+	t.Run("step and failed step specified", func(t *testing.T) {
+		c := context.NewTestContext(t)
+		opts := LogsShowOptions{Step: 1, FailedStep: true}
+		opts.Name = "mybun"
+
+		err := opts.Validate(c.Context)
+		require.EqualError(t, err, "only one of --step, --failed-step or --summary can be specified")
+	})
+
 	t.Run("neither specified", func(t *testing.T) {
 		c := context.NewTestContext(t)
 		opts := LogsShowOptions{}
@@ -95,3 +105,75 @@ func TestPorter_ShowInstallationLogs(t *testing.T) {
 		assert.Contains(t, p.TestConfig.TestContext.GetOutput(), testLogs)
 	})
 }
+
+func TestPorter_ShowInstallationLogs_Steps(t *testing.T) {
+	newPorter := func(t *testing.T, logs string) *TestPorter {
+		p := NewTestPorter(t)
+		c := p.TestClaims.CreateClaim("test", claim.ActionInstall, bundle.Bundle{}, nil)
+		r := p.TestClaims.CreateResult(c, claim.StatusFailed)
+		p.TestClaims.CreateOutput(c, r, claim.OutputInvocationImageLogs, []byte(logs))
+		r.OutputMetadata.SetGeneratedByBundle(claim.OutputInvocationImageLogs, false)
+		p.TestClaims.SaveResult(r)
+		return p
+	}
+
+	stepLogs, err := ioutil.ReadFile("testdata/logs/steps.txt")
+	require.NoError(t, err)
+
+	t.Run("all steps", func(t *testing.T) {
+		p := newPorter(t, string(stepLogs))
+		opts := LogsShowOptions{}
+		opts.Name = "test"
+		require.NoError(t, p.ShowInstallationLogs(&opts))
+
+		gotOutput := p.TestConfig.TestContext.GetOutput()
+		assert.Contains(t, gotOutput, "mysql installed")
+		assert.NotContains(t, gotOutput, "::porter-step::", "the step markers should not be displayed")
+	})
+
+	t.Run("step", func(t *testing.T) {
+		p := newPorter(t, string(stepLogs))
+		opts := LogsShowOptions{Step: 1}
+		opts.Name = "test"
+		require.NoError(t, p.ShowInstallationLogs(&opts))
+
+		assert.Equal(t, "Install MySQL\nmysql installed\n", p.TestConfig.TestContext.GetOutput())
+	})
+
+	t.Run("step out of range", func(t *testing.T) {
+		p := newPorter(t, string(stepLogs))
+		opts := LogsShowOptions{Step: 3}
+		opts.Name = "test"
+		err := p.ShowInstallationLogs(&opts)
+		require.EqualError(t, err, "invalid --step 3, the run only executed 2 steps")
+	})
+
+	t.Run("failed step", func(t *testing.T) {
+		p := newPorter(t, string(stepLogs))
+		opts := LogsShowOptions{FailedStep: true}
+		opts.Name = "test"
+		require.NoError(t, p.ShowInstallationLogs(&opts))
+
+		assert.Equal(t, "Create database\nERROR 1045 (28000): Access denied for user 'root'\n", p.TestConfig.TestContext.GetOutput())
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		p := newPorter(t, string(stepLogs))
+		opts := LogsShowOptions{Summary: true}
+		opts.Name = "test"
+		require.NoError(t, p.ShowInstallationLogs(&opts))
+
+		gotOutput := p.TestConfig.TestContext.GetOutput()
+		assert.Regexp(t, `1\s+Install MySQL\s+helm3\s+succeeded\s+5s\s+30 B`, gotOutput)
+		assert.Regexp(t, `2\s+Create database\s+exec\s+failed\s+1s`, gotOutput)
+	})
+
+	t.Run("logs without steps", func(t *testing.T) {
+		p := newPorter(t, "some mighty fine logs")
+		opts := LogsShowOptions{FailedStep: true}
+		opts.Name = "test"
+		err := p.ShowInstallationLogs(&opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "created by an older version of porter")
+	})
+}
@@ -99,6 +99,13 @@ func (g *ParameterOptions) validateParamName(args []string) error {
 // a silent build, based on the opts.Silent flag, or interactive using a survey. Returns an
 // error if unable to generate parameters
 func (p *Porter) GenerateParameters(opts ParameterOptions) error {
+	if err := p.ensureWritable("generate parameters"); err != nil {
+		return err
+	}
+	if err := p.ensurePersistent("generate parameters"); err != nil {
+		return err
+	}
+
 	err := p.prepullBundleByReference(&opts.BundleActionOptions)
 	if err != nil {
 		return errors.Wrap(err, "unable to pull bundle before invoking parameters generate")
@@ -163,6 +170,13 @@ func (o *ParameterEditOptions) Validate(args []string) error {
 
 // EditParameter edits the parameters of the provided name.
 func (p *Porter) EditParameter(opts ParameterEditOptions) error {
+	if err := p.ensureWritable("edit parameters"); err != nil {
+		return err
+	}
+	if err := p.ensurePersistent("edit parameters"); err != nil {
+		return err
+	}
+
 	paramSet, err := p.Parameters.Read(opts.Name)
 	if err != nil {
 		return err
@@ -262,6 +276,13 @@ type ParameterDeleteOptions struct {
 // DeleteParameter deletes the parameter set corresponding to the provided
 // names.
 func (p *Porter) DeleteParameter(opts ParameterDeleteOptions) error {
+	if err := p.ensureWritable("delete parameters"); err != nil {
+		return err
+	}
+	if err := p.ensurePersistent("delete parameters"); err != nil {
+		return err
+	}
+
 	err := p.Parameters.Delete(opts.Name)
 	if err != nil && strings.Contains(err.Error(), crud.ErrRecordDoesNotExist.Error()) {
 		if p.Debug {
@@ -293,7 +314,8 @@ func validateParameterName(args []string) error {
 }
 
 // loadParameterSets loads parameter values per their parameter set strategies
-func (p *Porter) loadParameterSets(params []string) (valuesource.Set, error) {
+// for the specified installation
+func (p *Porter) loadParameterSets(installation string, params []string) (valuesource.Set, error) {
 	resolvedParameters := valuesource.Set{}
 	for _, name := range params {
 		var pset parameters.ParameterSet
@@ -326,7 +348,7 @@ func (p *Porter) loadParameterSets(params []string) (valuesource.Set, error) {
 			}
 		}
 
-		rc, err := p.Parameters.ResolveAll(pset)
+		rc, err := p.Parameters.ResolveAll(pset, installation)
 		if err != nil {
 			return nil, err
 		}
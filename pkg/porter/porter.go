@@ -3,6 +3,7 @@
 package porter
 
 import (
+	"get.porter.sh/porter/pkg/approvals"
 	buildprovider "get.porter.sh/porter/pkg/build/provider"
 	"get.porter.sh/porter/pkg/cache"
 	"get.porter.sh/porter/pkg/claims"
@@ -14,10 +15,14 @@ import (
 	"get.porter.sh/porter/pkg/mixin"
 	"get.porter.sh/porter/pkg/parameters"
 	"get.porter.sh/porter/pkg/plugins"
+	"get.porter.sh/porter/pkg/secrets"
+	secretplugins "get.porter.sh/porter/pkg/secrets/pluginstore"
 	"get.porter.sh/porter/pkg/storage"
 	"get.porter.sh/porter/pkg/storage/pluginstore"
 	"get.porter.sh/porter/pkg/templates"
 	"github.com/cnabio/cnab-go/claim"
+	cnabsecrets "github.com/cnabio/cnab-go/secrets"
+	"github.com/pkg/errors"
 )
 
 // Porter is the logic behind the porter client.
@@ -27,7 +32,10 @@ type Porter struct {
 	Cache       cache.BundleCache
 	Credentials credentials.CredentialProvider
 	Parameters  parameters.ParameterProvider
+	Secrets     cnabsecrets.Store
 	Claims      claim.Provider
+	Approvals   approvals.ApprovalProvider
+	Users       approvals.UserProvider
 	Registry    cnabtooci.RegistryProvider
 	Templates   *templates.Templates
 	Builder     BuildProvider
@@ -36,6 +44,9 @@ type Porter struct {
 	Plugins     plugins.PluginProvider
 	CNAB        cnabprovider.CNABProvider
 	Storage     storage.StorageProvider
+
+	// ephemeralOpts are the options used when running in ephemeral mode.
+	ephemeralOpts EphemeralOptions
 }
 
 // New porter client, initialized with useful defaults.
@@ -56,8 +67,11 @@ func NewWithConfig(c *config.Config) *Porter {
 		Cache:       cache,
 		Storage:     storageManager,
 		Claims:      claimStorage,
+		Approvals:   approvals.NewApprovalStore(storageManager),
+		Users:       approvals.OSUserProvider{},
 		Credentials: credStorage,
 		Parameters:  paramStorage,
+		Secrets:     secrets.NewSecretStore(secretplugins.NewStore(c)),
 		Registry:    cnabtooci.NewRegistry(c.Context),
 		Templates:   templates.NewTemplates(),
 		Builder:     buildprovider.NewDockerBuilder(c.Context),
@@ -82,3 +96,12 @@ func (p *Porter) LoadManifestFrom(file string) error {
 	p.Manifest = m
 	return nil
 }
+
+// ensureWritable returns an error when Porter is running in read-only mode,
+// explaining which operation was refused.
+func (p *Porter) ensureWritable(operation string) error {
+	if p.ReadOnly {
+		return errors.Wrapf(config.ErrReadOnly, "cannot %s", operation)
+	}
+	return nil
+}
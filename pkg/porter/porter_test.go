@@ -0,0 +1,54 @@
+package porter
+
+import (
+	"testing"
+
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/claim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPorter_ReadOnly(t *testing.T) {
+	p := NewTestPorter(t)
+	c := p.TestClaims.CreateClaim("test", claim.ActionInstall, bundle.Bundle{}, nil)
+	p.TestClaims.CreateResult(c, claim.StatusSucceeded)
+	p.ReadOnly = true
+
+	t.Run("read commands are allowed", func(t *testing.T) {
+		_, err := p.ListInstallations()
+		require.NoError(t, err, "ListInstallations should be allowed in read-only mode")
+	})
+
+	t.Run("actions are refused", func(t *testing.T) {
+		opts := NewInstallOptions()
+		opts.Name = "test"
+		err := p.InstallBundle(opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot install: porter is running in read-only mode")
+	})
+
+	t.Run("deletes are refused", func(t *testing.T) {
+		opts := DeleteOptions{}
+		opts.Name = "test"
+		opts.Force = true
+		err := p.DeleteInstallation(opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot delete the installation: porter is running in read-only mode")
+
+		_, err = p.Claims.ReadInstallation("test")
+		require.NoError(t, err, "the installation should not have been deleted")
+	})
+
+	t.Run("edits are refused", func(t *testing.T) {
+		err := p.EditCredential(CredentialEditOptions{Name: "mycreds"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot edit credentials: porter is running in read-only mode")
+	})
+
+	t.Run("migrations are refused", func(t *testing.T) {
+		err := p.MigrateStorage()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot migrate storage: porter is running in read-only mode")
+	})
+}
@@ -8,7 +8,11 @@ import (
 	"strings"
 
 	"get.porter.sh/porter/pkg/build"
+	configadapter "get.porter.sh/porter/pkg/cnab/config-adapter"
+	"get.porter.sh/porter/pkg/cnab/extensions"
+	"get.porter.sh/porter/pkg/config"
 	portercontext "get.porter.sh/porter/pkg/context"
+	"get.porter.sh/porter/pkg/manifest"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-go/bundle/loader"
 	"github.com/cnabio/cnab-go/packager"
@@ -28,10 +32,21 @@ type PublishOptions struct {
 	Tag         string
 	Registry    string
 	ArchiveFile string
+
+	// References are the bundle references to publish to. When set, the
+	// first reference is also used as the Reference.
+	References []string
 }
 
 // Validate performs validation on the publish options
 func (o *PublishOptions) Validate(cxt *portercontext.Context) error {
+	if len(o.References) == 0 && o.Reference != "" {
+		o.References = []string{o.Reference}
+	}
+	if len(o.References) > 0 {
+		o.Reference = o.References[0]
+	}
+
 	if o.ArchiveFile != "" {
 		// Verify the archive file can be accessed
 		if _, err := cxt.FileSystem.Stat(o.ArchiveFile); err != nil {
@@ -53,8 +68,14 @@ func (o *PublishOptions) Validate(cxt *portercontext.Context) error {
 		}
 	}
 
-	if o.Reference != "" {
-		return o.validateReference()
+	if len(o.References) > 1 && (o.Tag != "" || o.Registry != "") {
+		return errors.New("--tag and --registry cannot be used when publishing to multiple references, include the tag and registry in each --reference instead")
+	}
+
+	for _, ref := range o.References {
+		if err := (BundlePullOptions{Reference: ref}).validateReference(); err != nil {
+			return err
+		}
 	}
 
 	if o.Tag != "" {
@@ -117,6 +138,7 @@ func (p *Porter) publishFromFile(opts PublishOptions) error {
 
 	// Capture original invocation image name as it may be updated below
 	origInvImg := p.Manifest.Image
+	origAppImgs := p.getBuiltImages()
 
 	// Check for tag and registry overrides optionally supplied on publish
 	if opts.Tag != "" {
@@ -131,42 +153,196 @@ func (p *Porter) publishFromFile(opts PublishOptions) error {
 		p.Manifest.Reference = ""
 	}
 
-	// Update invocation image and reference with opts.Reference, which may be
-	// empty, which is fine - we still may need to pick up tag and/or registry
-	// overrides
-	if err := p.Manifest.SetInvocationImageAndReference(opts.Reference); err != nil {
-		return errors.Wrap(err, "unable to set invocation image name and reference")
+	refs, err := p.getPublishReferences(opts)
+	if err != nil {
+		return err
 	}
 
-	if origInvImg != p.Manifest.Image {
-		// Tag it so that it will be known/found by Docker for publishing
-		if err := p.Builder.TagInvocationImage(origInvImg, p.Manifest.Image); err != nil {
+	// Check that the path dependencies are published before pushing anything
+	depRefs, err := p.resolvePathDependencies(opts)
+	if err != nil {
+		return err
+	}
+
+	// Images are pushed once per repository, and reused by the other references
+	pushed := make(map[string]pushedImage)
+	published := make([]publishedBundle, 0, len(refs))
+	for _, ref := range refs {
+		pb, err := p.publishToReference(opts, ref, origInvImg, origAppImgs, depRefs, pushed)
+		if err != nil {
 			return err
 		}
+		published = append(published, pb)
+	}
+
+	p.printPublishSummary(published)
+	return nil
+}
+
+// publishedBundle is where a bundle was published, and the invocation image it uses.
+type publishedBundle struct {
+	Reference       string
+	InvocationImage string
+	Digest          string
+}
+
+// pushedImage is an image that was pushed while publishing a bundle.
+type pushedImage struct {
+	// Image is the name the image was pushed as, in the format REGISTRY/NAME:TAG.
+	Image string
+
+	// Tag is the Docker tag portion of the Image.
+	Tag string
+
+	// Digest of the pushed image.
+	Digest string
+}
+
+// getPublishReferences returns the bundle references to publish to: those
+// specified with --reference, otherwise those defined in the manifest. An
+// empty reference publishes to the reference derived from the manifest.
+func (p *Porter) getPublishReferences(opts PublishOptions) ([]string, error) {
+	if len(opts.References) > 0 {
+		return opts.References, nil
+	}
+	if opts.Reference != "" {
+		return []string{opts.Reference}, nil
+	}
+
+	// The manifest references are not used when the tag or registry is overridden
+	if opts.Tag == "" && opts.Registry == "" {
+		refs, err := p.Manifest.GetPublishReferences()
+		if err != nil {
+			return nil, err
+		}
+		if len(refs) > 0 {
+			return refs, nil
+		}
+	}
+
+	return []string{""}, nil
+}
+
+// publishToReference pushes the invocation image, the images that porter
+// built and the bundle to a single bundle reference. Images that were already
+// pushed to the same repository, tracked in pushed, are not pushed again.
+func (p *Porter) publishToReference(opts PublishOptions, ref string, origInvImg string, origAppImgs map[string]string, depRefs map[string]string, pushed map[string]pushedImage) (publishedBundle, error) {
+	// Update invocation image and reference with ref, which may be
+	// empty, which is fine - we still may need to pick up tag and/or registry
+	// overrides
+	if err := p.Manifest.SetInvocationImageAndReference(ref); err != nil {
+		return publishedBundle{}, errors.Wrap(err, "unable to set invocation image name and reference")
 	}
 
 	if p.Manifest.Reference == "" {
-		return errors.New("porter.yaml is missing registry or reference values needed for publishing")
+		return publishedBundle{}, errors.New("porter.yaml is missing registry or reference values needed for publishing")
 	}
 
-	digest, err := p.Registry.PushInvocationImage(p.Manifest.Image)
+	invImg, err := p.pushImageOnce(origInvImg, p.Manifest.Image, pushed, p.Registry.PushInvocationImage)
 	if err != nil {
-		return errors.Wrapf(err, "unable to push CNAB invocation image %q", p.Manifest.Image)
+		return publishedBundle{}, errors.Wrapf(err, "unable to push CNAB invocation image %q", p.Manifest.Image)
+	}
+	p.Manifest.Image = invImg.Image
+
+	// Push the images that porter built next to the invocation image, and
+	// record their digests so that they are included in the bundle
+	newAppImgs := p.getBuiltImages()
+	for alias, newImg := range newAppImgs {
+		pushedImg, err := p.pushImageOnce(origAppImgs[alias], newImg, pushed, p.Registry.PushImage)
+		if err != nil {
+			return publishedBundle{}, errors.Wrapf(err, "unable to push image %q", newImg)
+		}
+		img := p.Manifest.ImageMap[alias]
+		img.Tag = pushedImg.Tag
+		img.Digest = pushedImg.Digest
+		p.Manifest.ImageMap[alias] = img
 	}
 
-	bun, err := p.rewriteBundleWithInvocationImageDigest(digest, opts.File)
+	bun, err := p.rewriteBundleWithInvocationImageDigest(invImg.Digest, opts.File)
 	if err != nil {
-		return err
+		return publishedBundle{}, err
+	}
+
+	err = rewritePathDependencies(&bun, depRefs)
+	if err != nil {
+		return publishedBundle{}, err
 	}
 
 	rm, err := p.Registry.PushBundle(bun, p.Manifest.Reference, opts.InsecureRegistry)
 	if err != nil {
-		return err
+		return publishedBundle{}, err
 	}
 
 	// Perhaps we have a cached version of a bundle with the same reference, previously pulled
 	// If so, replace it, as it is most likely out-of-date per this publish
-	return p.refreshCachedBundle(bun, p.Manifest.Reference, rm)
+	err = p.refreshCachedBundle(bun, p.Manifest.Reference, rm)
+	if err != nil {
+		return publishedBundle{}, err
+	}
+
+	return publishedBundle{
+		Reference:       p.Manifest.Reference,
+		InvocationImage: invImg.Image,
+		Digest:          invImg.Digest,
+	}, nil
+}
+
+// pushImageOnce tags the local image with the name it is published as and
+// pushes it. When an image was already pushed to the same repository, it is
+// not pushed again, and the previously pushed image is returned instead so
+// that every bundle reference uses the same image and digest.
+func (p *Porter) pushImageOnce(localImg string, img string, pushed map[string]pushedImage, push func(string) (string, error)) (pushedImage, error) {
+	named, err := reference.ParseNormalizedNamed(img)
+	if err != nil {
+		return pushedImage{}, errors.Wrapf(err, "invalid image reference %s", img)
+	}
+	if prev, ok := pushed[named.Name()]; ok {
+		return prev, nil
+	}
+
+	if localImg != img {
+		// Tag it so that it will be known/found by Docker for publishing
+		if err := p.Builder.TagInvocationImage(localImg, img); err != nil {
+			return pushedImage{}, err
+		}
+	}
+
+	digest, err := push(img)
+	if err != nil {
+		return pushedImage{}, err
+	}
+
+	result := pushedImage{Image: img, Digest: digest}
+	if tagged, ok := named.(reference.Tagged); ok {
+		result.Tag = tagged.Tag()
+	}
+	pushed[named.Name()] = result
+	return result, nil
+}
+
+// printPublishSummary lists where the bundle was published, when it was
+// published to more than one reference.
+func (p *Porter) printPublishSummary(published []publishedBundle) {
+	if len(published) < 2 {
+		return
+	}
+
+	fmt.Fprintf(p.Out, "\nPublished the bundle to %d references:\n", len(published))
+	for _, pb := range published {
+		fmt.Fprintf(p.Out, "  %s with the invocation image %s (%s)\n", pb.Reference, pb.InvocationImage, pb.Digest)
+	}
+}
+
+// getBuiltImages returns the reference of each image that porter builds,
+// keyed by the image alias.
+func (p *Porter) getBuiltImages() map[string]string {
+	imgs := make(map[string]string)
+	for alias, img := range p.Manifest.ImageMap {
+		if img.Build != nil {
+			imgs[alias] = fmt.Sprintf("%s:%s", img.Repository, img.Tag)
+		}
+	}
+	return imgs
 }
 
 // publishFromArchive (re-)publishes a bundle, provided by the archive file, using the provided tag.
@@ -198,7 +374,10 @@ func (p *Porter) publishFromArchive(opts PublishOptions) error {
 		return err
 	}
 
-	fmt.Fprintf(p.Out, "Beginning bundle publish to %s. This may take some time.\n", opts.Reference)
+	// Archives do not include the source of path dependencies, so there is nothing to publish them from
+	if err = rewritePathDependencies(&bun, nil); err != nil {
+		return err
+	}
 
 	// Use the ggcr client to read the extracted OCI Layout
 	client := ggcr.NewRegistryClient()
@@ -207,49 +386,84 @@ func (p *Porter) publishFromArchive(opts PublishOptions) error {
 		return errors.Wrapf(err, "failed to parse OCI Layout from archive %s", opts.ArchiveFile)
 	}
 
-	// Push updated images (renamed based on provided bundle tag) with same digests
-	// then update the bundle with new values (image name, digest)
+	// Remember the images in the archive, because the bundle is updated with
+	// the published images for each reference
+	origInvImgs := make([]string, len(bun.InvocationImages))
 	for i, invImg := range bun.InvocationImages {
-		newImgName, err := getNewImageNameFromBundleReference(invImg.Image, opts.Reference)
-		if err != nil {
-			return err
-		}
+		origInvImgs[i] = invImg.Image
+	}
+	origImgs := make(map[string]string, len(bun.Images))
+	for name, img := range bun.Images {
+		origImgs[name] = img.Image
+	}
 
-		digest, err := pushUpdatedImage(layout, invImg.Image, newImgName)
-		if err != nil {
-			return err
-		}
+	refs := opts.References
+	if len(refs) == 0 {
+		refs = []string{opts.Reference}
+	}
 
-		err = p.updateBundleWithNewImage(bun, newImgName, digest, i)
-		if err != nil {
-			return err
+	// Images are pushed once per repository, and reused by the other references
+	pushed := make(map[string]image.Digest)
+	published := make([]publishedBundle, 0, len(refs))
+	for _, ref := range refs {
+		fmt.Fprintf(p.Out, "Beginning bundle publish to %s. This may take some time.\n", ref)
+
+		// Push updated images (renamed based on provided bundle tag) with same digests
+		// then update the bundle with new values (image name, digest)
+		for i, origImg := range origInvImgs {
+			newImgName, err := getNewImageNameFromBundleReference(origImg, ref)
+			if err != nil {
+				return err
+			}
+
+			digest, err := pushUpdatedImageOnce(layout, origImg, newImgName, pushed)
+			if err != nil {
+				return err
+			}
+
+			err = p.updateBundleWithNewImage(bun, newImgName, digest, i)
+			if err != nil {
+				return err
+			}
 		}
-	}
-	for name, img := range bun.Images {
-		newImgName, err := getNewImageNameFromBundleReference(img.Image, opts.Reference)
-		if err != nil {
-			return err
+		for name, origImg := range origImgs {
+			newImgName, err := getNewImageNameFromBundleReference(origImg, ref)
+			if err != nil {
+				return err
+			}
+
+			digest, err := pushUpdatedImageOnce(layout, origImg, newImgName, pushed)
+			if err != nil {
+				return err
+			}
+
+			err = p.updateBundleWithNewImage(bun, newImgName, digest, name)
+			if err != nil {
+				return err
+			}
 		}
 
-		digest, err := pushUpdatedImage(layout, img.Image, newImgName)
+		rm, err := p.Registry.PushBundle(bun, ref, opts.InsecureRegistry)
 		if err != nil {
 			return err
 		}
 
-		err = p.updateBundleWithNewImage(bun, newImgName, digest, name)
+		// Perhaps we have a cached version of a bundle with the same tag, previously pulled
+		// If so, replace it, as it is most likely out-of-date per this publish
+		err = p.refreshCachedBundle(bun, ref, rm)
 		if err != nil {
 			return err
 		}
-	}
 
-	rm, err := p.Registry.PushBundle(bun, opts.Reference, opts.InsecureRegistry)
-	if err != nil {
-		return err
+		published = append(published, publishedBundle{
+			Reference:       ref,
+			InvocationImage: bun.InvocationImages[0].Image,
+			Digest:          bun.InvocationImages[0].Digest,
+		})
 	}
 
-	// Perhaps we have a cached version of a bundle with the same tag, previously pulled
-	// If so, replace it, as it is most likely out-of-date per this publish
-	return p.refreshCachedBundle(bun, opts.Reference, rm)
+	p.printPublishSummary(published)
+	return nil
 }
 
 // extractBundle extracts a bundle using the provided opts and returnsthe extracted bundle
@@ -294,6 +508,21 @@ func pushUpdatedImage(layout registry.Layout, origImg string, newImgName image.N
 	return digest, nil
 }
 
+// pushUpdatedImageOnce pushes the image, unless it was already pushed to the
+// same repository, tracked in pushed, and returns its digest.
+func pushUpdatedImageOnce(layout registry.Layout, origImg string, newImgName image.Name, pushed map[string]image.Digest) (image.Digest, error) {
+	if digest, ok := pushed[newImgName.Name()]; ok {
+		return digest, nil
+	}
+
+	digest, err := pushUpdatedImage(layout, origImg, newImgName)
+	if err != nil {
+		return image.EmptyDigest, err
+	}
+	pushed[newImgName.Name()] = digest
+	return digest, nil
+}
+
 // updateBundleWithNewImage updates a bundle with a new image (with digest) at the provided index
 func (p *Porter) updateBundleWithNewImage(bun bundle.Bundle, newImg image.Name, digest image.Digest, index interface{}) error {
 	taggedImage, err := p.rewriteImageWithDigest(newImg.String(), digest.String())
@@ -354,8 +583,16 @@ func (p *Porter) rewriteBundleWithInvocationImageDigest(digest string, manifestP
 		return bundle.Bundle{}, errors.Wrap(err, "unable to update invocation image reference")
 	}
 
+	// Keep the build record from when the bundle was built
+	var record *configadapter.BuildRecord
+	if bun, err := p.CNAB.LoadBundle(build.LOCAL_BUNDLE); err == nil {
+		if stamp, err := configadapter.LoadStamp(bun); err == nil {
+			record = stamp.Build
+		}
+	}
+
 	fmt.Fprintln(p.Out, "\nRewriting CNAB bundle.json...")
-	err = p.buildBundle(taggedImage, digest)
+	err = p.buildBundle(taggedImage, digest, record)
 	if err != nil {
 		return bundle.Bundle{}, errors.Wrap(err, "unable to rewrite CNAB bundle.json with updated invocation image digest")
 	}
@@ -369,6 +606,90 @@ func (p *Porter) rewriteBundleWithInvocationImageDigest(digest string, manifestP
 	return bun, nil
 }
 
+// resolvePathDependencies finds the published bundle reference of each path
+// dependency, returning an error when a dependency has not been published or
+// the published bundle was not built from the local bundle.
+func (p *Porter) resolvePathDependencies(opts PublishOptions) (map[string]string, error) {
+	refs := make(map[string]string)
+	for _, dep := range p.Manifest.Dependencies {
+		if dep.Path == "" {
+			continue
+		}
+
+		dir := dep.Path
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(p.FileSystem.Abs(opts.File)), dir)
+		}
+		depManifest, err := manifest.LoadManifestFrom(p.Context, filepath.Join(dir, config.Name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not load the path dependency %s", dep.Name)
+		}
+		if depManifest.Reference == "" {
+			return nil, errors.Errorf("the path dependency %s at %s is missing registry or reference values needed for publishing", dep.Name, dep.Path)
+		}
+
+		localBun, err := p.CNAB.LoadBundle(filepath.Join(dir, build.LOCAL_BUNDLE))
+		if err != nil {
+			return nil, errors.Wrapf(err, "the path dependency %s at %s must be built and published to %s before publishing this bundle", dep.Name, dep.Path, depManifest.Reference)
+		}
+		localStamp, err := configadapter.LoadStamp(localBun)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not load the stamp of the path dependency %s", dep.Name)
+		}
+
+		publishedBun, _, err := p.Registry.PullBundle(depManifest.Reference, opts.InsecureRegistry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "the path dependency %s at %s must be published to %s before publishing this bundle", dep.Name, dep.Path, depManifest.Reference)
+		}
+		publishedStamp, err := configadapter.LoadStamp(publishedBun)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not load the stamp of the published path dependency %s", dep.Name)
+		}
+		if publishedStamp.ManifestDigest != localStamp.ManifestDigest {
+			return nil, errors.Errorf("the bundle published to %s is out-of-date with the path dependency %s at %s, publish the dependency again before publishing this bundle", depManifest.Reference, dep.Name, dep.Path)
+		}
+
+		fmt.Fprintf(p.Out, "Using %s for the path dependency %s\n", depManifest.Reference, dep.Name)
+		refs[dep.Name] = depManifest.Reference
+	}
+	return refs, nil
+}
+
+// rewritePathDependencies replaces path dependencies with their published
+// bundle references, so that the published bundle does not depend upon local
+// directories.
+func rewritePathDependencies(bun *bundle.Bundle, refs map[string]string) error {
+	if !extensions.HasDependencies(*bun) {
+		return nil
+	}
+
+	deps, err := extensions.ReadDependencies(*bun)
+	if err != nil {
+		return err
+	}
+
+	rewritten := false
+	for name, dep := range deps.Requires {
+		if dep.Path == "" {
+			continue
+		}
+
+		ref, ok := refs[name]
+		if !ok {
+			return errors.Errorf("dependency %s is a local path dependency, which cannot be published", name)
+		}
+		dep.Bundle = ref
+		dep.Path = ""
+		deps.Requires[name] = dep
+		rewritten = true
+	}
+
+	if rewritten {
+		bun.Custom[extensions.DependenciesExtensionKey] = deps
+	}
+	return nil
+}
+
 func (p *Porter) rewriteImageWithDigest(InvocationImage string, digest string) (string, error) {
 	ref, err := reference.Parse(InvocationImage)
 	if err != nil {
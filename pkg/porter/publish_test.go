@@ -5,6 +5,9 @@ import (
 	"testing"
 
 	"get.porter.sh/porter/pkg/cache"
+	"get.porter.sh/porter/pkg/cnab/extensions"
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/manifest"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-to-oci/relocation"
 	"github.com/pivotal/image-relocation/pkg/image"
@@ -54,6 +57,55 @@ func TestPublish_Validate_ArchivePath(t *testing.T) {
 	require.NoError(t, err, "validating should not have failed")
 }
 
+func TestPublish_Validate_MultipleReferences(t *testing.T) {
+	p := NewTestPorter(t)
+	p.TestConfig.TestContext.AddTestFile("testdata/porter.yaml", "porter.yaml")
+
+	opts := PublishOptions{
+		References: []string{"myreg/mybuns:v0.1.0", "myreg/mybuns:latest"},
+	}
+	err := opts.Validate(p.Context)
+	require.NoError(t, err, "validating should not have failed")
+	assert.Equal(t, "myreg/mybuns:v0.1.0", opts.Reference, "the first reference should be used as the reference")
+
+	opts = PublishOptions{
+		References: []string{"myreg/mybuns:v0.1.0", "myreg/mybuns:latest"},
+		Tag:        "v0.2.0",
+	}
+	err = opts.Validate(p.Context)
+	assert.EqualError(t, err, "--tag and --registry cannot be used when publishing to multiple references, include the tag and registry in each --reference instead")
+
+	opts = PublishOptions{
+		References: []string{"myreg/mybuns:v0.1.0", "myreg/mybuns@sha256:abc"},
+	}
+	err = opts.Validate(p.Context)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid value for --reference")
+}
+
+func TestPublish_getPublishReferences(t *testing.T) {
+	p := NewTestPorter(t)
+	p.Manifest = &manifest.Manifest{
+		Name:    "mybuns",
+		Version: "0.1.0",
+		Publish: &manifest.PublishDefinition{
+			References: []string{"myreg/{{ bundle.name }}:v{{ bundle.version }}", "myreg/mybuns:latest"},
+		},
+	}
+
+	refs, err := p.getPublishReferences(PublishOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"myreg/mybuns:v0.1.0", "myreg/mybuns:latest"}, refs, "the manifest references should be used by default")
+
+	refs, err = p.getPublishReferences(PublishOptions{References: []string{"otherreg/mybuns:v0.1.0"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"otherreg/mybuns:v0.1.0"}, refs, "--reference should take precedence over the manifest")
+
+	refs, err = p.getPublishReferences(PublishOptions{Tag: "dev"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{""}, refs, "the manifest references should not be used when the tag is overridden")
+}
+
 func TestPublish_validateTag(t *testing.T) {
 	t.Run("tag is a Docker tag", func(t *testing.T) {
 		opts := PublishOptions{
@@ -241,3 +293,200 @@ func TestPublish_RefreshCachedBundle_OnlyWarning(t *testing.T) {
 	gotStderr := p.TestConfig.TestContext.GetError()
 	require.Equal(t, "warning: unable to update cache for bundle myreg/mybuns: error trying to store bundle\n", gotStderr)
 }
+
+func TestPublish_ResolvePathDependencies(t *testing.T) {
+	p := NewTestPorter(t)
+	p.TestConfig.TestContext.AddTestFile("testdata/porter.yaml", "/app/mysql/porter.yaml")
+	p.Manifest = &manifest.Manifest{
+		Dependencies: []*manifest.Dependency{
+			{Name: "mysql", Path: "mysql"},
+			{Name: "nginx", Reference: "localhost:5000/nginx:1.19"},
+		},
+	}
+	opts := PublishOptions{}
+	opts.File = "/app/porter.yaml"
+
+	t.Run("not built", func(t *testing.T) {
+		_, err := p.resolvePathDependencies(opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the path dependency mysql at mysql must be built and published to getporter/porter-hello:v0.1.0 before publishing this bundle")
+	})
+
+	p.TestConfig.TestContext.AddTestFileContents([]byte(`{"name":"porter-hello","version":"0.1.0","custom":{"sh.porter":{"manifestDigest":"abc123"}}}`), "/app/mysql/.cnab/bundle.json")
+	publish := func(manifestDigest string) {
+		p.TestRegistry.MockPullBundle = func(tag string, insecureRegistry bool) (bundle.Bundle, *relocation.ImageRelocationMap, error) {
+			return bundle.Bundle{
+				Name:    "porter-hello",
+				Version: "0.1.0",
+				Custom: map[string]interface{}{
+					config.CustomPorterKey: map[string]interface{}{"manifestDigest": manifestDigest},
+				},
+			}, nil, nil
+		}
+	}
+
+	t.Run("published", func(t *testing.T) {
+		publish("abc123")
+		refs, err := p.resolvePathDependencies(opts)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"mysql": "getporter/porter-hello:v0.1.0"}, refs)
+	})
+
+	t.Run("published bundle is out-of-date", func(t *testing.T) {
+		publish("def456")
+		_, err := p.resolvePathDependencies(opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the bundle published to getporter/porter-hello:v0.1.0 is out-of-date with the path dependency mysql at mysql")
+	})
+
+	t.Run("not published", func(t *testing.T) {
+		p.TestRegistry.MockPullBundle = func(tag string, insecureRegistry bool) (bundle.Bundle, *relocation.ImageRelocationMap, error) {
+			return bundle.Bundle{}, nil, errors.New("not found")
+		}
+		defer func() { p.TestRegistry.MockPullBundle = nil }()
+
+		_, err := p.resolvePathDependencies(opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the path dependency mysql at mysql must be published to getporter/porter-hello:v0.1.0 before publishing this bundle")
+	})
+}
+
+func TestPublish_RewritePathDependencies(t *testing.T) {
+	newBundle := func() bundle.Bundle {
+		return bundle.Bundle{
+			Custom: map[string]interface{}{
+				extensions.DependenciesExtensionKey: extensions.Dependencies{
+					Requires: map[string]extensions.Dependency{
+						"mysql": {Path: "../mysql"},
+						"nginx": {Bundle: "localhost:5000/nginx:1.19"},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("rewritten", func(t *testing.T) {
+		bun := newBundle()
+		err := rewritePathDependencies(&bun, map[string]string{"mysql": "getporter/mysql:v0.1.0"})
+		require.NoError(t, err)
+
+		deps, err := extensions.ReadDependencies(bun)
+		require.NoError(t, err)
+		assert.Equal(t, extensions.Dependency{Bundle: "getporter/mysql:v0.1.0"}, deps.Requires["mysql"])
+		assert.Equal(t, extensions.Dependency{Bundle: "localhost:5000/nginx:1.19"}, deps.Requires["nginx"])
+	})
+
+	t.Run("unresolved", func(t *testing.T) {
+		bun := newBundle()
+		err := rewritePathDependencies(&bun, nil)
+		require.EqualError(t, err, "dependency mysql is a local path dependency, which cannot be published")
+	})
+}
+
+func TestPublish_BuiltImages(t *testing.T) {
+	p := NewTestPorter(t)
+	configTpl, err := p.Templates.GetManifest()
+	require.NoError(t, err)
+	images := `
+images:
+  web:
+    build:
+      context: web
+`
+	p.TestConfig.TestContext.AddTestFileContents(append(configTpl, []byte(images)...), config.Name)
+
+	buildOpts := BuildOptions{}
+	require.NoError(t, buildOpts.Validate(p.Context), "Validate failed")
+	require.NoError(t, p.Build(buildOpts), "Build failed")
+
+	p.TestRegistry.MockPushInvocationImage = func(invocationImage string) (string, error) {
+		return "sha256:8f1133d81f1b078c865cdb11d17d1ff15f55c449d3eecca50190eed0f5e5e26f", nil
+	}
+	var pushedImages []string
+	p.TestRegistry.MockPushImage = func(image string) (string, error) {
+		pushedImages = append(pushedImages, image)
+		return "sha256:6b5a28ccbb76f12ce771a23757880c6083234255c5ba191fca1c5db1f71c1687", nil
+	}
+	var pushedBundle bundle.Bundle
+	p.TestRegistry.MockPushBundle = func(bun bundle.Bundle, tag string, insecureRegistry bool) (*relocation.ImageRelocationMap, error) {
+		pushedBundle = bun
+		return nil, nil
+	}
+
+	opts := PublishOptions{Registry: "example.com/myorg"}
+	require.NoError(t, opts.Validate(p.Context), "Validate failed")
+	require.NoError(t, p.Publish(opts), "Publish failed")
+
+	assert.Equal(t, []string{"example.com/myorg/porter-hello-web:v0.1.0"}, pushedImages, "the built image should be pushed next to the invocation image")
+	img := pushedBundle.Images["web"]
+	assert.Equal(t, "example.com/myorg/porter-hello-web@sha256:6b5a28ccbb76f12ce771a23757880c6083234255c5ba191fca1c5db1f71c1687", img.Image)
+	assert.Equal(t, "sha256:6b5a28ccbb76f12ce771a23757880c6083234255c5ba191fca1c5db1f71c1687", img.Digest)
+}
+
+func TestPublish_MultipleReferences(t *testing.T) {
+	p := NewTestPorter(t)
+	configTpl, err := p.Templates.GetManifest()
+	require.NoError(t, err)
+	images := `
+images:
+  web:
+    build:
+      context: web
+`
+	p.TestConfig.TestContext.AddTestFileContents(append(configTpl, []byte(images)...), config.Name)
+
+	buildOpts := BuildOptions{}
+	require.NoError(t, buildOpts.Validate(p.Context), "Validate failed")
+	require.NoError(t, p.Build(buildOpts), "Build failed")
+
+	var pushedInvImages []string
+	p.TestRegistry.MockPushInvocationImage = func(invocationImage string) (string, error) {
+		pushedInvImages = append(pushedInvImages, invocationImage)
+		return "sha256:8f1133d81f1b078c865cdb11d17d1ff15f55c449d3eecca50190eed0f5e5e26f", nil
+	}
+	var pushedImages []string
+	p.TestRegistry.MockPushImage = func(image string) (string, error) {
+		pushedImages = append(pushedImages, image)
+		return "sha256:6b5a28ccbb76f12ce771a23757880c6083234255c5ba191fca1c5db1f71c1687", nil
+	}
+	pushedBundles := map[string]bundle.Bundle{}
+	p.TestRegistry.MockPushBundle = func(bun bundle.Bundle, tag string, insecureRegistry bool) (*relocation.ImageRelocationMap, error) {
+		pushedBundles[tag] = bun
+		return nil, nil
+	}
+
+	opts := PublishOptions{
+		References: []string{
+			"example.com/myorg/porter-hello:v0.1.0",
+			"example.com/myorg/porter-hello:latest",
+			"mirror.example.com/porter-hello:v0.1.0",
+		},
+	}
+	require.NoError(t, opts.Validate(p.Context), "Validate failed")
+	require.NoError(t, p.Publish(opts), "Publish failed")
+
+	assert.Equal(t, []string{
+		"example.com/myorg/porter-hello-installer:v0.1.0",
+		"mirror.example.com/porter-hello-installer:v0.1.0",
+	}, pushedInvImages, "the invocation image should be pushed once per repository")
+	assert.Equal(t, []string{
+		"example.com/myorg/porter-hello-web:v0.1.0",
+		"mirror.example.com/porter-hello-web:v0.1.0",
+	}, pushedImages, "the built images should be pushed once per repository")
+
+	require.Len(t, pushedBundles, 3, "the bundle should be pushed to each reference")
+	assert.Equal(t, "example.com/myorg/porter-hello-installer:v0.1.0",
+		pushedBundles["example.com/myorg/porter-hello:latest"].InvocationImages[0].Image,
+		"the bundle should use the invocation image that was already pushed to the repository")
+	assert.Equal(t, "mirror.example.com/porter-hello-installer:v0.1.0",
+		pushedBundles["mirror.example.com/porter-hello:v0.1.0"].InvocationImages[0].Image)
+	assert.Equal(t, "mirror.example.com/porter-hello-web@sha256:6b5a28ccbb76f12ce771a23757880c6083234255c5ba191fca1c5db1f71c1687",
+		pushedBundles["mirror.example.com/porter-hello:v0.1.0"].Images["web"].Image)
+
+	gotOutput := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, gotOutput, `Published the bundle to 3 references:
+  example.com/myorg/porter-hello:v0.1.0 with the invocation image example.com/myorg/porter-hello-installer:v0.1.0 (sha256:8f1133d81f1b078c865cdb11d17d1ff15f55c449d3eecca50190eed0f5e5e26f)
+  example.com/myorg/porter-hello:latest with the invocation image example.com/myorg/porter-hello-installer:v0.1.0 (sha256:8f1133d81f1b078c865cdb11d17d1ff15f55c449d3eecca50190eed0f5e5e26f)
+  mirror.example.com/porter-hello:v0.1.0 with the invocation image mirror.example.com/porter-hello-installer:v0.1.0 (sha256:8f1133d81f1b078c865cdb11d17d1ff15f55c449d3eecca50190eed0f5e5e26f)
+`)
+}
@@ -0,0 +1,249 @@
+package porter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
+	configadapter "get.porter.sh/porter/pkg/cnab/config-adapter"
+	"get.porter.sh/porter/pkg/config"
+	portercontext "get.porter.sh/porter/pkg/context"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/docker/distribution/reference"
+	"github.com/pivotal/image-relocation/pkg/image"
+	"github.com/pivotal/image-relocation/pkg/registry/ggcr"
+	"github.com/pkg/errors"
+)
+
+// RebaseOptions are the options for porter bundle rebase.
+type RebaseOptions struct {
+	BundlePullOptions
+
+	// ArchiveFile is a bundle archive to rebase, instead of a published bundle.
+	ArchiveFile string
+
+	// Base is the new base image of the invocation image.
+	Base string
+
+	// OldBase is the base image that the invocation image was built from.
+	// Defaults to the base image recorded when the bundle was built.
+	OldBase string
+
+	// Tag of the rebased bundle, in the repository of the original bundle.
+	Tag string
+
+	// Destination is the full reference of the rebased bundle, which
+	// overrides Tag.
+	Destination string
+}
+
+// Validate the rebase options, determining where the rebased bundle is published.
+func (o *RebaseOptions) Validate(cxt *portercontext.Context) error {
+	if o.ArchiveFile != "" {
+		if o.Reference != "" {
+			return errors.New("--reference cannot be used with --archive")
+		}
+		if _, err := cxt.FileSystem.Stat(o.ArchiveFile); err != nil {
+			return errors.Wrapf(err, "unable to access --archive %s", o.ArchiveFile)
+		}
+		if o.Destination == "" {
+			return errors.New("--destination is required when rebasing a bundle archive")
+		}
+	} else {
+		if o.Reference == "" {
+			return errors.New("either --reference or --archive is required")
+		}
+		if err := o.validateReference(); err != nil {
+			return err
+		}
+	}
+
+	if o.Base == "" {
+		return errors.New("--base is required")
+	}
+	for _, img := range []string{o.Base, o.OldBase} {
+		if img == "" {
+			continue
+		}
+		if _, err := reference.ParseNormalizedNamed(img); err != nil {
+			return errors.Wrapf(err, "invalid base image %s", img)
+		}
+	}
+
+	var sourceRef reference.Named
+	if o.Reference != "" {
+		var err error
+		if sourceRef, err = reference.ParseNormalizedNamed(o.Reference); err != nil {
+			return errors.Wrapf(err, "invalid reference %s", o.Reference)
+		}
+	}
+
+	if o.Destination == "" {
+		if o.Tag == "" {
+			return errors.New("either --tag or --destination is required, so that the rebased bundle does not replace the original")
+		}
+		if strings.Contains(o.Tag, ":") || strings.Contains(o.Tag, "@") {
+			return errors.New("--tag should only be the Docker tag portion of the bundle reference, use --destination for the full bundle reference instead")
+		}
+		o.Destination = fmt.Sprintf("%s:%s", sourceRef.Name(), o.Tag)
+	}
+
+	destRef, err := reference.ParseNormalizedNamed(o.Destination)
+	if err != nil {
+		return errors.Wrapf(err, "invalid destination %s", o.Destination)
+	}
+	if _, ok := destRef.(reference.Digested); ok {
+		return errors.Errorf("invalid destination %s, the rebased bundle must be published to a tag", o.Destination)
+	}
+	if sourceRef != nil && reference.TagNameOnly(destRef).String() == reference.TagNameOnly(sourceRef).String() {
+		return errors.Errorf("the destination %s is the same as the original bundle, specify a new tag", o.Destination)
+	}
+
+	return nil
+}
+
+// Rebase replaces the base image of a bundle's invocation image without
+// rebuilding the bundle, and publishes the rebased bundle.
+func (p *Porter) Rebase(opts RebaseOptions) error {
+	var bun bundle.Bundle
+	var layoutDir string
+	if opts.ArchiveFile != "" {
+		source := p.FileSystem.Abs(opts.ArchiveFile)
+		tmpDir, err := p.FileSystem.TempDir("", "porter")
+		if err != nil {
+			return errors.Wrap(err, "error creating temp directory for archive extraction")
+		}
+		defer p.FileSystem.RemoveAll(tmpDir)
+
+		bun, err = p.extractBundle(tmpDir, source)
+		if err != nil {
+			return err
+		}
+		layoutDir = filepath.Join(tmpDir, strings.TrimSuffix(filepath.Base(source), ".tgz"), "artifacts/layout")
+	} else {
+		var err error
+		bun, _, err = p.Registry.PullBundle(opts.Reference, opts.InsecureRegistry)
+		if err != nil {
+			return errors.Wrapf(err, "unable to pull bundle %s", opts.Reference)
+		}
+	}
+
+	stamp, err := configadapter.LoadStamp(bun)
+	if err != nil {
+		return errors.Wrap(err, "only bundles built by porter can be rebased")
+	}
+
+	oldBase := opts.OldBase
+	if oldBase == "" && stamp.Build != nil {
+		oldBase = stamp.Build.BaseImage
+	}
+	if oldBase == "" {
+		return errors.New("the bundle does not record the base image of its invocation image, specify it with --old-base")
+	}
+
+	destRef, err := reference.ParseNormalizedNamed(opts.Destination)
+	if err != nil {
+		return errors.Wrapf(err, "invalid destination %s", opts.Destination)
+	}
+	destTag := "latest"
+	if tagged, ok := destRef.(reference.Tagged); ok {
+		destTag = tagged.Tag()
+	}
+
+	fmt.Fprintf(p.Out, "Rebasing %s from %s onto %s...\n", bun.Name, oldBase, opts.Base)
+
+	var newBaseDigest string
+	for i, invImg := range bun.InvocationImages {
+		newImgName, err := getNewImageNameFromBundleReference(invImg.Image, opts.Destination)
+		if err != nil {
+			return err
+		}
+
+		// Rebase the invocation image that the bundle was published with,
+		// even when its tag has moved since. Archives are looked up by name.
+		source := invImg.Image
+		if layoutDir == "" {
+			if source, err = pinImageDigest(invImg.Image, invImg.Digest); err != nil {
+				return err
+			}
+		}
+
+		result, err := p.Registry.RebaseImage(cnabtooci.RebaseImageOptions{
+			Image:            source,
+			OldBase:          oldBase,
+			NewBase:          opts.Base,
+			Destination:      fmt.Sprintf("%s:%s", newImgName.Name(), destTag),
+			LayoutDir:        layoutDir,
+			InsecureRegistry: opts.InsecureRegistry,
+		})
+		if err != nil {
+			return err
+		}
+
+		newBaseDigest = result.NewBaseDigest
+
+		imgDigest, err := image.NewDigest(result.Digest)
+		if err != nil {
+			return errors.Wrapf(err, "invalid digest %s for the rebased invocation image", result.Digest)
+		}
+		if err = p.updateBundleWithNewImage(bun, newImgName, imgDigest, i); err != nil {
+			return err
+		}
+	}
+
+	// Images referenced by an archived bundle are only in the archive, so
+	// they must be published alongside the rebased bundle
+	if layoutDir != "" && len(bun.Images) > 0 {
+		layout, err := ggcr.NewRegistryClient().ReadLayout(layoutDir)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse OCI Layout from archive %s", opts.ArchiveFile)
+		}
+		for name, img := range bun.Images {
+			newImgName, err := getNewImageNameFromBundleReference(img.Image, opts.Destination)
+			if err != nil {
+				return err
+			}
+			digest, err := pushUpdatedImage(layout, img.Image, newImgName)
+			if err != nil {
+				return err
+			}
+			if err = p.updateBundleWithNewImage(bun, newImgName, digest, name); err != nil {
+				return err
+			}
+		}
+	}
+
+	if stamp.Build == nil {
+		stamp.Build = &configadapter.BuildRecord{}
+	}
+	if stamp.Build.BaseImage, err = pinImageDigest(opts.Base, newBaseDigest); err != nil {
+		return err
+	}
+	bun.Custom[config.CustomPorterKey] = stamp
+
+	rm, err := p.Registry.PushBundle(bun, opts.Destination, opts.InsecureRegistry)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.Out, "Published the rebased bundle to %s\n", opts.Destination)
+	return p.refreshCachedBundle(bun, opts.Destination, rm)
+}
+
+// pinImageDigest returns the image reference pinned to the digest, replacing
+// its tag. The image is returned unchanged when the digest is unknown.
+func pinImageDigest(img string, digest string) (string, error) {
+	if digest == "" {
+		return img, nil
+	}
+
+	ref, err := reference.ParseNormalizedNamed(img)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid image reference %s", img)
+	}
+	if _, ok := ref.(reference.Digested); ok {
+		return img, nil
+	}
+	return fmt.Sprintf("%s@%s", reference.FamiliarName(ref), digest), nil
+}
@@ -0,0 +1,157 @@
+package porter
+
+import (
+	"testing"
+
+	cnabtooci "get.porter.sh/porter/pkg/cnab/cnab-to-oci"
+	configadapter "get.porter.sh/porter/pkg/cnab/config-adapter"
+	"get.porter.sh/porter/pkg/config"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-to-oci/relocation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebaseOptions_Validate(t *testing.T) {
+	testcases := []struct {
+		name     string
+		opts     RebaseOptions
+		wantDest string
+		wantErr  string
+	}{
+		{
+			name:     "tag",
+			opts:     RebaseOptions{BundlePullOptions: BundlePullOptions{Reference: "getporter/hello:v0.1.0"}, Base: "debian:stretch-20210621-slim", Tag: "v0.1.0-patch1"},
+			wantDest: "docker.io/getporter/hello:v0.1.0-patch1",
+		},
+		{
+			name:     "destination",
+			opts:     RebaseOptions{BundlePullOptions: BundlePullOptions{Reference: "getporter/hello:v0.1.0"}, Base: "debian", Destination: "example.com/hello:v0.1.0-patch1"},
+			wantDest: "example.com/hello:v0.1.0-patch1",
+		},
+		{
+			name:    "missing base",
+			opts:    RebaseOptions{BundlePullOptions: BundlePullOptions{Reference: "getporter/hello:v0.1.0"}, Tag: "v0.1.0-patch1"},
+			wantErr: "--base is required",
+		},
+		{
+			name:    "missing source",
+			opts:    RebaseOptions{Base: "debian", Tag: "v0.1.0-patch1"},
+			wantErr: "either --reference or --archive is required",
+		},
+		{
+			name:    "missing tag",
+			opts:    RebaseOptions{BundlePullOptions: BundlePullOptions{Reference: "getporter/hello:v0.1.0"}, Base: "debian"},
+			wantErr: "either --tag or --destination is required",
+		},
+		{
+			name:    "same tag",
+			opts:    RebaseOptions{BundlePullOptions: BundlePullOptions{Reference: "getporter/hello:v0.1.0"}, Base: "debian", Tag: "v0.1.0"},
+			wantErr: "is the same as the original bundle",
+		},
+		{
+			name:    "full reference as tag",
+			opts:    RebaseOptions{BundlePullOptions: BundlePullOptions{Reference: "getporter/hello:v0.1.0"}, Base: "debian", Tag: "getporter/hello:v0.2.0"},
+			wantErr: "--tag should only be the Docker tag portion",
+		},
+		{
+			name:    "invalid base",
+			opts:    RebaseOptions{BundlePullOptions: BundlePullOptions{Reference: "getporter/hello:v0.1.0"}, Base: "Debian:latest", Tag: "v0.1.0-patch1"},
+			wantErr: "invalid base image Debian:latest",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewTestPorter(t)
+			err := tc.opts.Validate(p.Context)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantDest, tc.opts.Destination)
+		})
+	}
+}
+
+func TestPorter_Rebase(t *testing.T) {
+	newTestBundle := func(baseImage string) bundle.Bundle {
+		stamp := configadapter.Stamp{
+			ManifestDigest: "abc123",
+			Build:          &configadapter.BuildRecord{BaseImage: baseImage},
+		}
+		return bundle.Bundle{
+			Name:    "hello",
+			Version: "0.1.0",
+			InvocationImages: []bundle.InvocationImage{
+				{BaseImage: bundle.BaseImage{
+					Image:     "docker.io/getporter/hello-installer:v0.1.0",
+					ImageType: "docker",
+					Digest:    "sha256:6b5a28ccbb76f12ce771a23757880c6083234255c5ba191fca1c5db1f71c1687",
+				}},
+			},
+			Custom: map[string]interface{}{config.CustomPorterKey: stamp},
+		}
+	}
+
+	t.Run("old base from the stamp", func(t *testing.T) {
+		p := NewTestPorter(t)
+		p.TestRegistry.MockPullBundle = func(tag string, insecureRegistry bool) (bundle.Bundle, *relocation.ImageRelocationMap, error) {
+			return newTestBundle("debian@sha256:7c2e21d2c6c4a8b5a8b2f2c1bd8d2fcb6b6d4d2e0d8e0c4e4f6e5b7d5a3c2b1a"), nil, nil
+		}
+		var gotRebase cnabtooci.RebaseImageOptions
+		p.TestRegistry.MockRebaseImage = func(opts cnabtooci.RebaseImageOptions) (cnabtooci.RebasedImage, error) {
+			gotRebase = opts
+			return cnabtooci.RebasedImage{
+				Digest:        "sha256:d5bd6d3eb6f3f1a4d2c2f6ff8d1ff0e4a6b1b3e1aef1e8d6a1b0c5f9d3e2a1b0",
+				NewBaseDigest: "sha256:0f5b2ba3b0e2f3c2b4d3a6a9e8c1d7f2e4b6a8c0d2e4f6a8b0c2d4e6f8a0b2c4",
+			}, nil
+		}
+		var gotBundle bundle.Bundle
+		var gotTag string
+		p.TestRegistry.MockPushBundle = func(bun bundle.Bundle, tag string, insecureRegistry bool) (*relocation.ImageRelocationMap, error) {
+			gotBundle = bun
+			gotTag = tag
+			return nil, nil
+		}
+
+		opts := RebaseOptions{Base: "debian:stretch-20210621-slim", Tag: "v0.1.0-patch1"}
+		opts.Reference = "getporter/hello:v0.1.0"
+		require.NoError(t, opts.Validate(p.Context))
+		require.NoError(t, p.Rebase(opts))
+
+		assert.Equal(t, "getporter/hello-installer@sha256:6b5a28ccbb76f12ce771a23757880c6083234255c5ba191fca1c5db1f71c1687", gotRebase.Image,
+			"the invocation image should be read by its digest")
+		assert.Equal(t, "debian@sha256:7c2e21d2c6c4a8b5a8b2f2c1bd8d2fcb6b6d4d2e0d8e0c4e4f6e5b7d5a3c2b1a", gotRebase.OldBase)
+		assert.Equal(t, "debian:stretch-20210621-slim", gotRebase.NewBase)
+		assert.Equal(t, "docker.io/getporter/hello-installer:v0.1.0-patch1", gotRebase.Destination)
+
+		assert.Equal(t, "docker.io/getporter/hello:v0.1.0-patch1", gotTag)
+		invImg := gotBundle.InvocationImages[0]
+		assert.Equal(t, "docker.io/getporter/hello-installer@sha256:d5bd6d3eb6f3f1a4d2c2f6ff8d1ff0e4a6b1b3e1aef1e8d6a1b0c5f9d3e2a1b0", invImg.Image)
+		assert.Equal(t, "sha256:d5bd6d3eb6f3f1a4d2c2f6ff8d1ff0e4a6b1b3e1aef1e8d6a1b0c5f9d3e2a1b0", invImg.Digest)
+
+		stamp, err := configadapter.LoadStamp(gotBundle)
+		require.NoError(t, err)
+		assert.Equal(t, "debian@sha256:0f5b2ba3b0e2f3c2b4d3a6a9e8c1d7f2e4b6a8c0d2e4f6a8b0c2d4e6f8a0b2c4", stamp.Build.BaseImage,
+			"the stamp should record the digest of the new base image")
+		assert.Equal(t, "abc123", stamp.ManifestDigest, "the rest of the stamp should be kept")
+		assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Published the rebased bundle to docker.io/getporter/hello:v0.1.0-patch1")
+	})
+
+	t.Run("old base unknown", func(t *testing.T) {
+		p := NewTestPorter(t)
+		p.TestRegistry.MockPullBundle = func(tag string, insecureRegistry bool) (bundle.Bundle, *relocation.ImageRelocationMap, error) {
+			return newTestBundle(""), nil, nil
+		}
+
+		opts := RebaseOptions{Base: "debian:stretch-20210621-slim", Tag: "v0.1.0-patch1"}
+		opts.Reference = "getporter/hello:v0.1.0"
+		require.NoError(t, opts.Validate(p.Context))
+		err := p.Rebase(opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "specify it with --old-base")
+	})
+}
@@ -0,0 +1,194 @@
+package porter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"get.porter.sh/porter/pkg/context"
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/runtime/resources"
+	dtprinter "github.com/carolynvs/datetime-printer"
+	"github.com/cnabio/cnab-go/claim"
+	"github.com/pkg/errors"
+)
+
+// ResourceListOptions represent options for an installation resources command
+type ResourceListOptions struct {
+	sharedOptions
+	printer.PrintOptions
+
+	// Type only lists resources with a matching type.
+	Type string
+
+	// ResourceName only lists resources with a matching name.
+	ResourceName string
+
+	// History lists the resources reported by every run of the installation,
+	// instead of the resources that it currently owns.
+	History bool
+}
+
+// Validate validates the provided args, using the provided context,
+// setting attributes of ResourceListOptions as applicable
+func (o *ResourceListOptions) Validate(args []string, cxt *context.Context) error {
+	err := o.sharedOptions.validateInstallationName(args)
+	if err != nil {
+		return err
+	}
+
+	// Attempt to derive installation name from context
+	err = o.sharedOptions.defaultBundleFiles(cxt)
+	if err != nil {
+		return errors.Wrap(err, "installation name must be provided")
+	}
+
+	// Check the filters before reading any runs
+	_, err = resources.Resource{}.Match(o.Type, o.ResourceName)
+	if err != nil {
+		return err
+	}
+
+	return o.ParseFormat()
+}
+
+// InstallationResource is a resource reported by a run of an installation.
+type InstallationResource struct {
+	resources.Resource `yaml:",inline"`
+
+	// ClaimID of the run that reported the resource.
+	ClaimID string `json:"run" yaml:"run"`
+
+	// Action of the run that reported the resource.
+	Action string `json:"action" yaml:"action"`
+
+	// Reported is when the run that reported the resource started.
+	Reported time.Time `json:"reported" yaml:"reported"`
+}
+
+// ListInstallationResources returns the resources that an installation owns,
+// according to the resources output of its runs. Each run reports every
+// resource that the installation owns, so the inventory is the resources
+// reported by the latest successful run of an action that modifies the
+// installation, until the installation is successfully uninstalled. Runs
+// where no step reported resources leave the inventory unchanged.
+func (p *Porter) ListInstallationResources(opts *ResourceListOptions) ([]InstallationResource, error) {
+	err := p.applyDefaultOptions(&opts.sharedOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	installation, err := p.Claims.ReadInstallation(opts.Name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read installation %s", opts.Name)
+	}
+
+	inventory := []InstallationResource{}
+	history := []InstallationResource{}
+	for _, c := range installation.Claims {
+		r, err := c.GetLastResult()
+		if err != nil {
+			// The run has not recorded a result yet
+			continue
+		}
+
+		reported, ok, err := p.readReportedResources(c, r)
+		if err != nil {
+			return nil, err
+		}
+
+		entries := []InstallationResource{}
+		for _, res := range reported {
+			match, err := res.Match(opts.Type, opts.ResourceName)
+			if err != nil {
+				return nil, err
+			}
+			if !match {
+				continue
+			}
+
+			entries = append(entries, InstallationResource{
+				Resource: res,
+				ClaimID:  c.ID,
+				Action:   c.Action,
+				Reported: c.Created,
+			})
+		}
+		history = append(history, entries...)
+
+		if r.Status != claim.StatusSucceeded {
+			continue
+		}
+		if c.Action == claim.ActionUninstall {
+			inventory = []InstallationResource{}
+			continue
+		}
+		// Actions that do not modify the installation, such as status, may
+		// not report every resource that it owns
+		if modifies, _ := c.IsModifyingAction(); ok && modifies {
+			inventory = entries
+		}
+	}
+
+	if opts.History {
+		return history, nil
+	}
+	return inventory, nil
+}
+
+// readReportedResources reads the resources output recorded by a run, and
+// returns if the run reported its resources. Runs of bundles that do not
+// define the output, and runs where no step reported resources, which record
+// the empty default value of the output, have no resources.
+func (p *Porter) readReportedResources(c claim.Claim, r claim.Result) (resources.Resources, bool, error) {
+	o, err := p.Claims.ReadOutput(c, r, resources.OutputName)
+	if err != nil {
+		if errors.Cause(err) == claim.ErrOutputNotFound {
+			return nil, false, nil
+		}
+		return nil, false, errors.Wrapf(err, "could not read the %s output of run %s", resources.OutputName, c.ID)
+	}
+	if strings.TrimSpace(string(o.Value)) == "" {
+		return nil, false, nil
+	}
+
+	reported, err := resources.Parse(string(o.Value))
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "could not read the resources reported by run %s", c.ID)
+	}
+	return reported, true, nil
+}
+
+// PrintInstallationResources prints the resources owned by an installation.
+func (p *Porter) PrintInstallationResources(opts ResourceListOptions) error {
+	inventory, err := p.ListInstallationResources(&opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, inventory)
+	case printer.FormatYaml:
+		return printer.PrintYaml(p.Out, inventory)
+	case printer.FormatTable:
+		// have every row use the same "now" starting ... NOW!
+		now := time.Now()
+		tp := dtprinter.DateTimePrinter{
+			Now: func() time.Time { return now },
+		}
+
+		row :=
+			func(v interface{}) []interface{} {
+				r, ok := v.(InstallationResource)
+				if !ok {
+					return nil
+				}
+				return []interface{}{r.Type, r.ID, r.Name, r.Action, tp.Format(r.Reported), r.ClaimID}
+			}
+		return printer.PrintTable(p.Out, inventory, row,
+			"TYPE", "ID", "NAME", "ACTION", "REPORTED", "RUN")
+	default:
+		return fmt.Errorf("invalid format: %s", opts.Format)
+	}
+}
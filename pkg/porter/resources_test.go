@@ -0,0 +1,182 @@
+package porter
+
+import (
+	"testing"
+
+	"get.porter.sh/porter/pkg/printer"
+	"get.porter.sh/porter/pkg/runtime/resources"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/bundle/definition"
+	"github.com/cnabio/cnab-go/claim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceListOptions_Validate(t *testing.T) {
+	p := NewTestPorter(t)
+
+	t.Run("installation name", func(t *testing.T) {
+		opts := ResourceListOptions{}
+		opts.RawFormat = "table"
+		err := opts.Validate([]string{"wordpress"}, p.Context)
+		require.NoError(t, err)
+		assert.Equal(t, "wordpress", opts.Name)
+		assert.Equal(t, printer.FormatTable, opts.Format)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		opts := ResourceListOptions{Type: "["}
+		opts.RawFormat = "table"
+		err := opts.Validate([]string{"wordpress"}, p.Context)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid type filter "["`)
+	})
+}
+
+func TestPorter_ListInstallationResources(t *testing.T) {
+	p := NewTestPorter(t)
+
+	b := bundle.Bundle{
+		Definitions: definition.Definitions{
+			"resources-output": &definition.Schema{Type: "array"},
+		},
+		Outputs: map[string]bundle.Output{
+			resources.OutputName: {Definition: "resources-output"},
+		},
+	}
+
+	createRun := func(action string, status string, reported string) claim.Claim {
+		c := p.TestClaims.CreateClaim("wordpress", action, b, nil)
+		r := p.TestClaims.CreateResult(c, status)
+		if reported != "" {
+			p.TestClaims.CreateOutput(c, r, resources.OutputName, []byte(reported))
+		}
+		return c
+	}
+
+	install := createRun(claim.ActionInstall, claim.StatusSucceeded,
+		`[{"type": "kubernetes/Namespace", "id": "wordpress"}, {"type": "kubernetes/Deployment", "id": "wordpress/web", "name": "web"}]`)
+	failedUpgrade := createRun(claim.ActionUpgrade, claim.StatusFailed,
+		`[{"type": "kubernetes/Deployment", "id": "wordpress/web", "name": "web"}, {"type": "aws_db_instance", "id": "db-1234", "name": "wordpress"}]`)
+	createRun("status", claim.StatusSucceeded, "")
+
+	t.Run("inventory", func(t *testing.T) {
+		opts := ResourceListOptions{sharedOptions: sharedOptions{Name: "wordpress"}}
+		got, err := p.ListInstallationResources(&opts)
+		require.NoError(t, err)
+
+		require.Len(t, got, 2, "resources reported by failed runs should not change the inventory")
+		assert.Equal(t, "wordpress", got[0].ID)
+		assert.Equal(t, install.ID, got[0].ClaimID)
+		assert.Equal(t, "wordpress/web", got[1].ID)
+		assert.Equal(t, install.ID, got[1].ClaimID)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		opts := ResourceListOptions{
+			sharedOptions: sharedOptions{Name: "wordpress"},
+			Type:          "kubernetes/*",
+			ResourceName:  "web",
+		}
+		got, err := p.ListInstallationResources(&opts)
+		require.NoError(t, err)
+
+		require.Len(t, got, 1)
+		assert.Equal(t, "wordpress/web", got[0].ID)
+	})
+
+	t.Run("history", func(t *testing.T) {
+		opts := ResourceListOptions{sharedOptions: sharedOptions{Name: "wordpress"}, History: true}
+		got, err := p.ListInstallationResources(&opts)
+		require.NoError(t, err)
+
+		require.Len(t, got, 4)
+		assert.Equal(t, install.ID, got[1].ClaimID)
+		assert.Equal(t, "wordpress/web", got[1].ID)
+		assert.Equal(t, failedUpgrade.ID, got[2].ClaimID)
+		assert.Equal(t, "wordpress/web", got[2].ID)
+		assert.Equal(t, "db-1234", got[3].ID, "the history should include resources reported by failed runs")
+	})
+
+	t.Run("latest successful run", func(t *testing.T) {
+		upgrade := createRun(claim.ActionUpgrade, claim.StatusSucceeded,
+			`[{"type": "kubernetes/Deployment", "id": "wordpress/web", "name": "web", "links": {"dashboard": "https://example.com/web"}}, {"type": "aws_db_instance", "id": "db-1234", "name": "wordpress"}]`)
+
+		opts := ResourceListOptions{sharedOptions: sharedOptions{Name: "wordpress"}}
+		got, err := p.ListInstallationResources(&opts)
+		require.NoError(t, err)
+
+		require.Len(t, got, 2, "resources that the latest run no longer reports should be removed from the inventory")
+		assert.Equal(t, "wordpress/web", got[0].ID)
+		assert.Equal(t, upgrade.ID, got[0].ClaimID)
+		assert.Equal(t, claim.ActionUpgrade, got[0].Action)
+		assert.Equal(t, "https://example.com/web", got[0].Links["dashboard"])
+		assert.Equal(t, "db-1234", got[1].ID)
+	})
+
+	t.Run("upgrade without resources", func(t *testing.T) {
+		// No step reported resources, so the run recorded the empty default value
+		upgrade := p.TestClaims.CreateClaim("wordpress", claim.ActionUpgrade, b, nil)
+		r := p.TestClaims.CreateResult(upgrade, claim.StatusSucceeded)
+		p.TestClaims.CreateOutput(upgrade, r, resources.OutputName, []byte(""))
+
+		opts := ResourceListOptions{sharedOptions: sharedOptions{Name: "wordpress"}}
+		got, err := p.ListInstallationResources(&opts)
+		require.NoError(t, err)
+		require.Len(t, got, 2, "a run that does not report resources should not change the inventory")
+		assert.Equal(t, "wordpress/web", got[0].ID)
+		assert.NotEqual(t, upgrade.ID, got[0].ClaimID)
+	})
+
+	t.Run("non-modifying action", func(t *testing.T) {
+		createRun("status", claim.StatusSucceeded, "[]")
+
+		opts := ResourceListOptions{sharedOptions: sharedOptions{Name: "wordpress"}}
+		got, err := p.ListInstallationResources(&opts)
+		require.NoError(t, err)
+		assert.Len(t, got, 2, "an action that does not modify the installation should not change the inventory")
+	})
+
+	t.Run("uninstalled", func(t *testing.T) {
+		createRun(claim.ActionUninstall, claim.StatusSucceeded, "[]")
+
+		opts := ResourceListOptions{sharedOptions: sharedOptions{Name: "wordpress"}}
+		got, err := p.ListInstallationResources(&opts)
+		require.NoError(t, err)
+		assert.Empty(t, got, "a successful uninstall should clear the inventory")
+
+		opts.History = true
+		got, err = p.ListInstallationResources(&opts)
+		require.NoError(t, err)
+		assert.Len(t, got, 6, "the history should keep the resources reported before the uninstall")
+	})
+}
+
+func TestPorter_PrintInstallationResources_JSON(t *testing.T) {
+	p := NewTestPorter(t)
+
+	b := bundle.Bundle{
+		Definitions: definition.Definitions{
+			"resources-output": &definition.Schema{Type: "array"},
+		},
+		Outputs: map[string]bundle.Output{
+			resources.OutputName: {Definition: "resources-output"},
+		},
+	}
+	c := p.TestClaims.CreateClaim("wordpress", claim.ActionInstall, b, nil)
+	r := p.TestClaims.CreateResult(c, claim.StatusSucceeded)
+	p.TestClaims.CreateOutput(c, r, resources.OutputName, []byte(`{"type": "kubernetes/Namespace", "id": "wordpress"}`))
+
+	opts := ResourceListOptions{
+		sharedOptions: sharedOptions{Name: "wordpress"},
+		PrintOptions:  printer.PrintOptions{Format: printer.FormatJson},
+	}
+	err := p.PrintInstallationResources(opts)
+	require.NoError(t, err)
+
+	gotOutput := p.TestConfig.TestContext.GetOutput()
+	assert.Contains(t, gotOutput, `"type": "kubernetes/Namespace"`)
+	assert.Contains(t, gotOutput, `"id": "wordpress"`)
+	assert.Contains(t, gotOutput, `"run": "`+c.ID+`"`)
+	assert.Contains(t, gotOutput, `"action": "install"`)
+}
@@ -0,0 +1,448 @@
+package porter
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"get.porter.sh/porter/pkg/pkgmgmt/feed"
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+)
+
+const (
+	// porterFeedPath is the location of the feed of porter releases, relative to the mirror.
+	porterFeedPath = "porter/atom.xml"
+
+	// checksumsFile is the name of the file published alongside the porter
+	// binaries with the sha256 checksum of each binary, in the format used by sha256sum.
+	checksumsFile = "checksums.txt"
+
+	// previousVersionDir is the directory in PORTER_HOME where the binaries
+	// replaced by porter self-update are kept for porter self-update --rollback.
+	previousVersionDir = "previous"
+)
+
+// SelfUpdateOptions are the options for porter self-update.
+type SelfUpdateOptions struct {
+	pkgmgmt.PackageDownloadOptions
+
+	// Version of porter to install, defaults to latest.
+	Version string
+
+	// Rollback to the version of porter that was installed before the last update.
+	Rollback bool
+}
+
+func (o *SelfUpdateOptions) Validate() error {
+	if o.Rollback {
+		if o.Version != "" {
+			return errors.New("--version cannot be used with --rollback")
+		}
+		return nil
+	}
+
+	if o.Version == "" {
+		o.Version = "latest"
+	}
+
+	return o.PackageDownloadOptions.Validate()
+}
+
+// porterBinaries are the locations of the porter client and runtime binaries.
+type porterBinaries struct {
+	Client  string
+	Runtime string
+}
+
+func (p *Porter) getPorterBinaries(dir string) porterBinaries {
+	return porterBinaries{
+		Client:  filepath.Join(dir, "porter"+pkgmgmt.FileExt),
+		Runtime: filepath.Join(dir, "runtimes", "porter-runtime"),
+	}
+}
+
+// SelfUpdate replaces the porter binaries in PORTER_HOME with the requested
+// version from the mirror, keeping the current binaries so that the update
+// can be rolled back.
+func (p *Porter) SelfUpdate(opts SelfUpdateOptions) error {
+	if opts.Rollback {
+		return p.rollbackSelfUpdate()
+	}
+
+	release, err := p.findPorterRelease(opts.PackageDownloadOptions, opts.Version)
+	if err != nil {
+		return err
+	}
+
+	if release.Version == pkg.Version {
+		fmt.Fprintf(p.Out, "porter %s is already installed\n", release.Version)
+		return nil
+	}
+
+	home, err := p.GetHomeDir()
+	if err != nil {
+		return err
+	}
+	current := p.getPorterBinaries(home)
+
+	clientURL := release.FindDownloadURL(runtime.GOOS, runtime.GOARCH)
+	if clientURL == nil {
+		return errors.Errorf("porter %s did not publish a download for %s/%s", release.Version, runtime.GOOS, runtime.GOARCH)
+	}
+	runtimeURL := release.FindDownloadURL("linux", "amd64")
+	if runtimeURL == nil {
+		return errors.Errorf("porter %s did not publish a download for linux/amd64", release.Version)
+	}
+
+	fmt.Fprintf(p.Out, "Updating porter to %s...\n", release.Version)
+
+	checksums, err := p.downloadChecksums(*clientURL)
+	if err != nil {
+		return err
+	}
+
+	// Download the new binaries next to the ones that they replace, so that
+	// they can be moved into place with a rename
+	staged := porterBinaries{
+		Client:  current.Client + ".new",
+		Runtime: current.Runtime + ".new",
+	}
+	defer p.FileSystem.Remove(staged.Client)
+	defer p.FileSystem.Remove(staged.Runtime)
+
+	if err = p.downloadVerifiedFile(*clientURL, checksums, staged.Client); err != nil {
+		return err
+	}
+	if err = p.downloadVerifiedFile(*runtimeURL, checksums, staged.Runtime); err != nil {
+		return err
+	}
+
+	previous := p.getPorterBinaries(filepath.Join(home, previousVersionDir))
+	if err = p.FileSystem.RemoveAll(filepath.Dir(previous.Client)); err != nil {
+		return errors.Wrap(err, "could not remove the previous version of porter")
+	}
+	if err = p.replacePorterBinaries(current, staged, previous); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.Out, "Updated porter from %s to %s. Run porter self-update --rollback to go back to %s.\n", pkg.Version, release.Version, pkg.Version)
+	p.warnIfNotInPorterHome(current)
+	return nil
+}
+
+// rollbackSelfUpdate swaps the current porter binaries with the ones that
+// were replaced by the last porter self-update.
+func (p *Porter) rollbackSelfUpdate() error {
+	home, err := p.GetHomeDir()
+	if err != nil {
+		return err
+	}
+	current := p.getPorterBinaries(home)
+	previous := p.getPorterBinaries(filepath.Join(home, previousVersionDir))
+
+	exists, _ := p.FileSystem.Exists(previous.Client)
+	if !exists {
+		return errors.Errorf("there is no previous version of porter to roll back to in %s", filepath.Dir(previous.Client))
+	}
+
+	// Stage a copy of the previous binaries next to the current ones, so that
+	// they can be renamed into place, and keep the current binaries as the
+	// previous version so that the rollback can itself be rolled back
+	staged := porterBinaries{
+		Client:  current.Client + ".rollback",
+		Runtime: current.Runtime + ".rollback",
+	}
+	defer p.FileSystem.Remove(staged.Client)
+	defer p.FileSystem.Remove(staged.Runtime)
+
+	if err = p.copyBinaries(previous, staged); err != nil {
+		return err
+	}
+	if err = p.replacePorterBinaries(current, staged, previous); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(p.Out, "Rolled back to the previous version of porter")
+	p.warnIfNotInPorterHome(current)
+	return nil
+}
+
+// replacePorterBinaries copies the current binaries to the backup location and
+// then moves the replacement binaries over the current ones.
+func (p *Porter) replacePorterBinaries(current porterBinaries, replacement porterBinaries, backup porterBinaries) error {
+	if err := p.copyBinaries(current, backup); err != nil {
+		return err
+	}
+
+	return p.moveBinaries(replacement, current)
+}
+
+// moveRunningBinaryAside is set on Windows, where the running porter
+// executable cannot be replaced or deleted, but it can be renamed.
+var moveRunningBinaryAside = runtime.GOOS == "windows"
+
+// moveBinaries renames the porter binaries over their destination, skipping
+// any that do not exist. Each destination binary is set aside first, and when
+// a binary cannot be moved into place, the binaries that were already moved
+// are restored so that the client and runtime binaries always match.
+func (p *Porter) moveBinaries(src porterBinaries, dest porterBinaries) error {
+	var moved []string
+	err := p.eachBinary(src, dest, func(from string, to string) error {
+		if err := p.setBinaryAside(to); err != nil {
+			return err
+		}
+		moved = append(moved, to)
+		return errors.Wrapf(p.FileSystem.Rename(from, to), "could not move %s to %s", from, to)
+	})
+
+	if err != nil {
+		for _, to := range moved {
+			if restoreErr := p.restoreBinary(to); restoreErr != nil {
+				return errors.Wrapf(err, "could not restore %s from %s: %s", to, to+asideBinaryExt, restoreErr)
+			}
+		}
+		return err
+	}
+
+	// The running binary cannot be removed on Windows, so it is removed by the
+	// next update instead
+	for _, to := range moved {
+		p.FileSystem.Remove(to + asideBinaryExt)
+	}
+	return nil
+}
+
+// asideBinaryExt is the extension of a binary that was set aside while it is replaced.
+const asideBinaryExt = ".old"
+
+// setBinaryAside keeps a binary that is about to be replaced next to it, so
+// that it can be restored. The binary is renamed aside on Windows, and copied
+// on other platforms so that it is replaced with a single rename.
+func (p *Porter) setBinaryAside(binary string) error {
+	aside := binary + asideBinaryExt
+	p.FileSystem.Remove(aside)
+
+	exists, err := p.FileSystem.Exists(binary)
+	if err != nil {
+		return errors.Wrapf(err, "could not check if %s exists", binary)
+	}
+	if !exists {
+		return nil
+	}
+
+	if moveRunningBinaryAside {
+		err = p.FileSystem.Rename(binary, aside)
+	} else {
+		err = p.CopyFile(binary, aside)
+	}
+	return errors.Wrapf(err, "could not set %s aside to %s", binary, aside)
+}
+
+// restoreBinary puts back the binary that was set aside, or removes the binary
+// when there was none before.
+func (p *Porter) restoreBinary(binary string) error {
+	aside := binary + asideBinaryExt
+	exists, err := p.FileSystem.Exists(aside)
+	if err != nil {
+		return errors.Wrapf(err, "could not check if %s exists", aside)
+	}
+	if !exists {
+		if err = p.FileSystem.Remove(binary); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return p.FileSystem.Rename(aside, binary)
+}
+
+// copyBinaries copies the porter binaries, skipping any that do not exist.
+func (p *Porter) copyBinaries(src porterBinaries, dest porterBinaries) error {
+	return p.eachBinary(src, dest, func(from string, to string) error {
+		if err := p.CopyFile(from, to); err != nil {
+			return errors.Wrapf(err, "could not copy %s to %s", from, to)
+		}
+		return nil
+	})
+}
+
+func (p *Porter) eachBinary(src porterBinaries, dest porterBinaries, apply func(from string, to string) error) error {
+	pairs := [][2]string{
+		{src.Client, dest.Client},
+		{src.Runtime, dest.Runtime},
+	}
+	for _, pair := range pairs {
+		from, to := pair[0], pair[1]
+		exists, err := p.FileSystem.Exists(from)
+		if err != nil {
+			return errors.Wrapf(err, "could not check if %s exists", from)
+		}
+		if !exists {
+			continue
+		}
+
+		if err = p.FileSystem.MkdirAll(filepath.Dir(to), 0755); err != nil {
+			return errors.Wrapf(err, "could not create directory %s", filepath.Dir(to))
+		}
+		if err = apply(from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Porter) warnIfNotInPorterHome(current porterBinaries) {
+	porterPath, err := p.GetPorterPath()
+	if err != nil {
+		return
+	}
+	if filepath.Clean(porterPath) != filepath.Clean(current.Client) {
+		fmt.Fprintf(p.Err, "WARNING: the porter binary that is running, %s, is not in PORTER_HOME and was not updated\n", porterPath)
+	}
+}
+
+// findPorterRelease looks up a version of porter in the feed of porter
+// releases on the mirror.
+func (p *Porter) findPorterRelease(opts pkgmgmt.PackageDownloadOptions, version string) (*feed.MixinFileset, error) {
+	feedURL := opts.GetMirror()
+	feedURL.Path = path.Join(feedURL.Path, porterFeedPath)
+
+	contents, err := p.download(feedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := p.FileSystem.TempDir("", "porter")
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating temp directory")
+	}
+	defer p.FileSystem.RemoveAll(tmpDir)
+
+	feedPath := filepath.Join(tmpDir, "atom.xml")
+	if err = p.FileSystem.WriteFile(feedPath, contents, 0644); err != nil {
+		return nil, errors.Wrapf(err, "could not write %s", feedPath)
+	}
+
+	releases := feed.NewMixinFeed(p.Context)
+	if err = releases.Load(feedPath); err != nil {
+		return nil, err
+	}
+
+	release := releases.Search("porter", version)
+	if release == nil {
+		return nil, errors.Errorf("the feed at %s does not contain an entry for porter @ %s", feedURL.String(), version)
+	}
+	return release, nil
+}
+
+// downloadChecksums retrieves the checksums published alongside a porter binary.
+func (p *Porter) downloadChecksums(binaryURL url.URL) (map[string]string, error) {
+	checksumsURL := binaryURL
+	checksumsURL.Path = path.Join(path.Dir(binaryURL.Path), checksumsFile)
+
+	contents, err := p.download(checksumsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// sha256sum marks files that were read in binary mode with a *
+		checksums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return checksums, nil
+}
+
+// downloadVerifiedFile downloads an executable and saves it to the destination
+// only if its sha256 checksum matches the published checksum.
+func (p *Porter) downloadVerifiedFile(src url.URL, checksums map[string]string, dest string) error {
+	file := path.Base(src.Path)
+	wantSum, ok := checksums[file]
+	if !ok {
+		return errors.Errorf("no checksum was published for %s", src.String())
+	}
+
+	contents, err := p.download(src)
+	if err != nil {
+		return err
+	}
+
+	gotSum := sha256.Sum256(contents)
+	if hex.EncodeToString(gotSum[:]) != wantSum {
+		return errors.Errorf("the checksum of %s does not match the published checksum, refusing to install it", src.String())
+	}
+
+	if err = p.FileSystem.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return errors.Wrapf(err, "could not create directory %s", filepath.Dir(dest))
+	}
+	return errors.Wrapf(p.FileSystem.WriteFile(dest, contents, 0755), "could not write %s", dest)
+}
+
+func (p *Porter) download(src url.URL) ([]byte, error) {
+	if p.Debug {
+		fmt.Fprintf(p.Err, "Downloading %s\n", src.String())
+	}
+
+	resp, err := http.Get(src.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "error downloading %s", src.String())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("bad status returned when downloading %s (%d) %s", src.String(), resp.StatusCode, resp.Status)
+	}
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	return contents, errors.Wrapf(err, "error downloading %s", src.String())
+}
+
+// VersionCheck is the result of comparing the installed version of porter
+// against the latest version published on the mirror.
+type VersionCheck struct {
+	Version         string `json:"version"`
+	LatestVersion   string `json:"latestVersion"`
+	UpdateAvailable bool   `json:"updateAvailable"`
+}
+
+// CheckVersion compares the installed version of porter against the latest
+// version published on the mirror.
+func (p *Porter) CheckVersion(opts pkgmgmt.PackageDownloadOptions) (VersionCheck, error) {
+	check := VersionCheck{Version: pkg.Version}
+
+	release, err := p.findPorterRelease(opts, "latest")
+	if err != nil {
+		return check, err
+	}
+	check.LatestVersion = release.Version
+
+	current, err := semver.NewVersion(pkg.Version)
+	if err != nil {
+		// Development builds do not have a valid version, so always suggest the latest release
+		check.UpdateAvailable = true
+		return check, nil
+	}
+	latest, err := semver.NewVersion(release.Version)
+	if err != nil {
+		return check, errors.Wrapf(err, "invalid version %s in the porter feed", release.Version)
+	}
+	check.UpdateAvailable = latest.GreaterThan(current)
+	return check, nil
+}
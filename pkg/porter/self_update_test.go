@@ -0,0 +1,270 @@
+package porter
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"get.porter.sh/porter/pkg"
+	"get.porter.sh/porter/pkg/printer"
+	"github.com/carolynvs/aferox"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestMirror serves a fake porter feed, binaries and checksums. The
+// contents of each binary is its url path.
+func newTestMirror(t *testing.T, badChecksums bool) *httptest.Server {
+	feed, err := ioutil.ReadFile("testdata/self-update/atom.xml")
+	require.NoError(t, err)
+
+	var mirrorURL string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch path.Base(r.URL.Path) {
+		case "atom.xml":
+			fmt.Fprintln(w, strings.Replace(string(feed), "https://cdn.porter.sh", mirrorURL, -1))
+		case checksumsFile:
+			for _, file := range []string{"porter-darwin-amd64", "porter-linux-amd64", "porter-windows-amd64.exe"} {
+				contents := path.Join(path.Dir(r.URL.Path), file)
+				if badChecksums {
+					contents = "oops"
+				}
+				fmt.Fprintf(w, "%x  %s\n", sha256.Sum256([]byte(contents)), file)
+			}
+		default:
+			fmt.Fprint(w, r.URL.Path)
+		}
+	}))
+	mirrorURL = ts.URL
+	return ts
+}
+
+func TestPorter_CheckVersion(t *testing.T) {
+	ts := newTestMirror(t, false)
+	defer ts.Close()
+
+	testcases := []struct {
+		version    string
+		wantUpdate bool
+	}{
+		{version: "v1.2.3", wantUpdate: true},
+		{version: "v1.3.0", wantUpdate: false},
+		{version: "v1.4.0-beta.1", wantUpdate: false},
+		{version: "dev", wantUpdate: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.version, func(t *testing.T) {
+			pkg.Version = tc.version
+			p := NewTestPorter(t)
+
+			opts := VersionOpts{Check: true}
+			opts.Mirror = ts.URL
+			require.NoError(t, opts.Validate())
+
+			check, err := p.CheckVersion(opts.PackageDownloadOptions)
+			require.NoError(t, err)
+			assert.Equal(t, "v1.3.0", check.LatestVersion)
+			assert.Equal(t, tc.wantUpdate, check.UpdateAvailable)
+		})
+	}
+}
+
+func TestPorter_PrintVersionCheck(t *testing.T) {
+	ts := newTestMirror(t, false)
+	defer ts.Close()
+
+	pkg.Version = "v1.2.3"
+
+	t.Run("plaintext", func(t *testing.T) {
+		p := NewTestPorter(t)
+		opts := VersionOpts{Check: true}
+		opts.Mirror = ts.URL
+		require.NoError(t, opts.Validate())
+
+		require.NoError(t, p.PrintVersion(opts))
+		assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "porter v1.2.3 is installed, a newer version is available: v1.3.0")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		p := NewTestPorter(t)
+		opts := VersionOpts{Check: true}
+		opts.RawFormat = string(printer.FormatJson)
+		opts.Mirror = ts.URL
+		require.NoError(t, opts.Validate())
+
+		require.NoError(t, p.PrintVersion(opts))
+		assert.Contains(t, p.TestConfig.TestContext.GetOutput(), `"updateAvailable": true`)
+	})
+}
+
+func TestPorter_SelfUpdate(t *testing.T) {
+	ts := newTestMirror(t, false)
+	defer ts.Close()
+
+	pkg.Version = "v1.2.3"
+	p := NewTestPorter(t)
+	home, _ := p.GetHomeDir()
+	p.SetPorterPath(path.Join(home, "porter"))
+	require.NoError(t, p.FileSystem.WriteFile(path.Join(home, "porter"), []byte("old client"), 0755))
+	require.NoError(t, p.FileSystem.WriteFile(path.Join(home, "runtimes/porter-runtime"), []byte("old runtime"), 0755))
+
+	opts := SelfUpdateOptions{Version: "v1.3.0"}
+	opts.Mirror = ts.URL
+	require.NoError(t, opts.Validate())
+	require.NoError(t, p.SelfUpdate(opts))
+
+	assert.Contains(t, p.TestConfig.TestContext.GetOutput(), "Updated porter from v1.2.3 to v1.3.0")
+	assertFileContents(t, p, path.Join(home, "runtimes/porter-runtime"), "/v1.3.0/porter-linux-amd64")
+	assertFileContents(t, p, path.Join(home, "previous/porter"), "old client")
+	assertFileContents(t, p, path.Join(home, "previous/runtimes/porter-runtime"), "old runtime")
+	staged, _ := p.FileSystem.Exists(path.Join(home, "porter.new"))
+	assert.False(t, staged, "the staged client should be cleaned up")
+	aside, _ := p.FileSystem.Exists(path.Join(home, "porter.old"))
+	assert.False(t, aside, "the replaced client should be cleaned up")
+
+	t.Run("rollback", func(t *testing.T) {
+		opts := SelfUpdateOptions{Rollback: true}
+		require.NoError(t, opts.Validate())
+		require.NoError(t, p.SelfUpdate(opts))
+
+		assertFileContents(t, p, path.Join(home, "porter"), "old client")
+		assertFileContents(t, p, path.Join(home, "runtimes/porter-runtime"), "old runtime")
+		assertFileContents(t, p, path.Join(home, "previous/runtimes/porter-runtime"), "/v1.3.0/porter-linux-amd64")
+	})
+}
+
+func TestPorter_SelfUpdate_BadChecksum(t *testing.T) {
+	ts := newTestMirror(t, true)
+	defer ts.Close()
+
+	pkg.Version = "v1.2.3"
+	p := NewTestPorter(t)
+	home, _ := p.GetHomeDir()
+	require.NoError(t, p.FileSystem.WriteFile(path.Join(home, "porter"), []byte("old client"), 0755))
+
+	opts := SelfUpdateOptions{}
+	opts.Mirror = ts.URL
+	require.NoError(t, opts.Validate())
+	err := p.SelfUpdate(opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match the published checksum")
+
+	assertFileContents(t, p, path.Join(home, "porter"), "old client")
+	previous, _ := p.FileSystem.Exists(path.Join(home, "previous"))
+	assert.False(t, previous, "the installed binaries should not be touched")
+}
+
+func TestPorter_SelfUpdate_NoRollback(t *testing.T) {
+	p := NewTestPorter(t)
+
+	opts := SelfUpdateOptions{Rollback: true}
+	require.NoError(t, opts.Validate())
+	err := p.SelfUpdate(opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "there is no previous version of porter to roll back to")
+}
+
+// lockedBinaryFs simulates Windows, where a running binary cannot be replaced
+// or removed, but it can be renamed. Renames to failRename always fail.
+type lockedBinaryFs struct {
+	afero.Fs
+	locked     string
+	failRename string
+}
+
+func (fs lockedBinaryFs) Rename(oldname string, newname string) error {
+	if newname == fs.failRename {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: os.ErrPermission}
+	}
+	if newname == fs.locked {
+		if exists, _ := afero.Exists(fs.Fs, newname); exists {
+			return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: os.ErrPermission}
+		}
+	}
+	return fs.Fs.Rename(oldname, newname)
+}
+
+func (fs lockedBinaryFs) Remove(name string) error {
+	if name == fs.locked || name == fs.locked+asideBinaryExt {
+		return &os.PathError{Op: "remove", Path: name, Err: os.ErrPermission}
+	}
+	return fs.Fs.Remove(name)
+}
+
+func TestPorter_MoveBinaries(t *testing.T) {
+	setup := func(t *testing.T, fs lockedBinaryFs) (*TestPorter, porterBinaries, porterBinaries) {
+		p := NewTestPorter(t)
+		home, _ := p.GetHomeDir()
+		current := p.getPorterBinaries(home)
+		staged := porterBinaries{Client: current.Client + ".new", Runtime: current.Runtime + ".new"}
+		require.NoError(t, p.FileSystem.WriteFile(current.Client, []byte("old client"), 0755))
+		require.NoError(t, p.FileSystem.WriteFile(current.Runtime, []byte("old runtime"), 0755))
+		require.NoError(t, p.FileSystem.WriteFile(staged.Client, []byte("new client"), 0755))
+		require.NoError(t, p.FileSystem.WriteFile(staged.Runtime, []byte("new runtime"), 0755))
+
+		fs.Fs = p.FileSystem.Fs
+		if fs.locked == "locked" {
+			fs.locked = current.Client
+		}
+		if fs.failRename == "runtime" {
+			fs.failRename = current.Runtime
+		}
+		p.FileSystem = aferox.NewAferox("/", fs)
+		return p, current, staged
+	}
+
+	t.Run("rolls back the client when the runtime cannot be moved", func(t *testing.T) {
+		p, current, staged := setup(t, lockedBinaryFs{failRename: "runtime"})
+
+		err := p.moveBinaries(staged, current)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not move "+staged.Runtime)
+
+		assertFileContents(t, p, current.Client, "old client")
+		assertFileContents(t, p, current.Runtime, "old runtime")
+		aside, _ := p.FileSystem.Exists(current.Client + asideBinaryExt)
+		assert.False(t, aside, "the client that was set aside should be restored")
+	})
+
+	t.Run("running binary on windows", func(t *testing.T) {
+		defer func(orig bool) { moveRunningBinaryAside = orig }(moveRunningBinaryAside)
+
+		moveRunningBinaryAside = false
+		p, current, staged := setup(t, lockedBinaryFs{locked: "locked"})
+		require.Error(t, p.moveBinaries(staged, current), "the running binary cannot be replaced without setting it aside")
+		assertFileContents(t, p, current.Client, "old client")
+
+		moveRunningBinaryAside = true
+		p, current, staged = setup(t, lockedBinaryFs{locked: "locked"})
+		require.NoError(t, p.moveBinaries(staged, current))
+		assertFileContents(t, p, current.Client, "new client")
+		assertFileContents(t, p, current.Runtime, "new runtime")
+		assertFileContents(t, p, current.Client+asideBinaryExt, "old client")
+	})
+}
+
+func TestSelfUpdateOptions_Validate(t *testing.T) {
+	opts := SelfUpdateOptions{Rollback: true, Version: "v1.2.3"}
+	err := opts.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--version cannot be used with --rollback")
+
+	opts = SelfUpdateOptions{}
+	require.NoError(t, opts.Validate())
+	assert.Equal(t, "latest", opts.Version)
+}
+
+func assertFileContents(t *testing.T, p *TestPorter, file string, want string) {
+	t.Helper()
+	got, err := p.FileSystem.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got))
+}
@@ -6,6 +6,13 @@ import (
 )
 
 func (p *Porter) MigrateStorage() error {
+	if err := p.ensureWritable("migrate storage"); err != nil {
+		return err
+	}
+	if err := p.ensurePersistent("migrate storage"); err != nil {
+		return err
+	}
+
 	logfilePath, err := p.Storage.Migrate()
 
 	fmt.Fprintf(p.Out, "\nSaved migration logs to %s\n", logfilePath)
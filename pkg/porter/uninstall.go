@@ -67,6 +67,14 @@ func (opts *UninstallDeleteOptions) handleUninstallErrs(out io.Writer, err error
 // UninstallBundle accepts a set of pre-validated UninstallOptions and uses
 // them to uninstall a bundle.
 func (p *Porter) UninstallBundle(opts UninstallOptions) error {
+	if opts.RequestApproval {
+		return p.requestApproval(opts)
+	}
+
+	if err := p.ensureWritable("uninstall"); err != nil {
+		return err
+	}
+
 	err := p.prepullBundleByReference(opts.BundleActionOptions)
 	if err != nil {
 		return errors.Wrap(err, "unable to pull bundle before uninstall")
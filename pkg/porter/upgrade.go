@@ -28,6 +28,10 @@ func (o UpgradeOptions) GetActionVerb() string {
 // UpgradeBundle accepts a set of pre-validated UpgradeOptions and uses
 // them to upgrade a bundle.
 func (p *Porter) UpgradeBundle(opts UpgradeOptions) error {
+	if opts.RequestApproval {
+		return p.requestApproval(opts)
+	}
+
 	err := p.prepullBundleByReference(opts.BundleActionOptions)
 	if err != nil {
 		return errors.Wrap(err, "unable to pull bundle before upgrade")
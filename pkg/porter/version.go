@@ -17,7 +17,23 @@ import (
 
 type VersionOpts struct {
 	version.Options
+	pkgmgmt.PackageDownloadOptions
 	System bool
+
+	// Check if a newer version of porter is published on the mirror.
+	Check bool
+}
+
+func (o *VersionOpts) Validate() error {
+	if err := o.Options.Validate(); err != nil {
+		return err
+	}
+
+	if o.System && o.Check {
+		return errors.New("--system and --check cannot be used together")
+	}
+
+	return o.PackageDownloadOptions.Validate()
 }
 
 type SystemInfo struct {
@@ -63,9 +79,36 @@ func (p *Porter) PrintVersion(opts VersionOpts) error {
 		return p.PrintDebugInfo(p.Context, opts, metadata)
 	}
 
+	if opts.Check {
+		return p.PrintVersionCheck(opts)
+	}
+
 	return version.PrintVersion(p.Context, opts.Options, metadata)
 }
 
+// PrintVersionCheck prints if a newer version of porter is available.
+func (p *Porter) PrintVersionCheck(opts VersionOpts) error {
+	check, err := p.CheckVersion(opts.PackageDownloadOptions)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case printer.FormatJson:
+		return printer.PrintJson(p.Out, check)
+	case printer.FormatPlaintext:
+		if check.UpdateAvailable {
+			fmt.Fprintf(p.Out, "porter %s is installed, a newer version is available: %s\n", check.Version, check.LatestVersion)
+			fmt.Fprintln(p.Out, "Run porter self-update to update porter")
+		} else {
+			fmt.Fprintf(p.Out, "porter %s is the latest version\n", check.Version)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported format: %s", opts.Format)
+	}
+}
+
 func getSystemInfo() *SystemInfo {
 	return &SystemInfo{
 		OS:   runtime.GOOS,
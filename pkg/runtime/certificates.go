@@ -0,0 +1,192 @@
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"get.porter.sh/porter/pkg/config"
+	"github.com/pkg/errors"
+)
+
+// trustStore is a location where CA certificates are installed, and the
+// command that updates the trust store after they are added.
+type trustStore struct {
+	// Dir where the certificates are copied.
+	Dir string
+
+	// Command that rebuilds the trust store from Dir.
+	Command []string
+}
+
+// trustStores that are supported, in the order that they are checked.
+var trustStores = []trustStore{
+	// Debian, Ubuntu and Alpine
+	{Dir: "/usr/local/share/ca-certificates", Command: []string{"update-ca-certificates"}},
+	// Fedora, CentOS and RHEL
+	{Dir: "/etc/pki/ca-trust/source/anchors", Command: []string{"update-ca-trust", "extract"}},
+}
+
+// systemCABundles are the CA bundles of the supported distributions, which
+// are extended with porter's CA certificates when the trust store cannot be
+// updated.
+var systemCABundles = []string{
+	// Debian, Ubuntu and Alpine
+	"/etc/ssl/certs/ca-certificates.crt",
+	// Fedora, CentOS and RHEL
+	"/etc/pki/tls/certs/ca-bundle.crt",
+}
+
+// systemCADirs are the directories of CA certificates of the supported
+// distributions, which are kept when SSL_CERT_DIR is set.
+var systemCADirs = []string{
+	"/etc/ssl/certs",
+	"/etc/pki/tls/certs",
+}
+
+// installCACertificates installs the CA certificates that porter placed in
+// the invocation image, from the porter config file, into the trust store, so
+// that the mixins trust them. When the trust store cannot be updated, for
+// example because the invocation image runs as a non-root user, the
+// certificates are added to a trust bundle in a writable directory instead.
+func (r *PorterRuntime) installCACertificates() error {
+	files, err := r.FileSystem.ReadDir(config.CACertificatesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "could not list the CA certificates in %s", config.CACertificatesDir)
+	}
+
+	var certs []string
+	for _, f := range files {
+		if !f.IsDir() {
+			certs = append(certs, f.Name())
+		}
+	}
+	if len(certs) == 0 {
+		return nil
+	}
+
+	var store *trustStore
+	for i, ts := range trustStores {
+		if _, ok := r.LookPath(ts.Command[0]); ok {
+			store = &trustStores[i]
+			break
+		}
+	}
+	if store != nil {
+		err = r.updateTrustStore(store, certs)
+		if err == nil || !os.IsPermission(errors.Cause(err)) {
+			return err
+		}
+	}
+
+	installed, bundleErr := r.installCABundle(certs)
+	if bundleErr != nil {
+		return bundleErr
+	}
+	if installed {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "the user running the invocation image cannot install CA certificates and the invocation image does not have a CA bundle to extend. "+
+			"Install the certificates in the Dockerfile template, or remove ca-certificates from the porter config file")
+	}
+	fmt.Fprintf(r.Err, "WARNING: could not install the CA certificates in %s because the invocation image does not have %s or a CA bundle to extend\n",
+		config.CACertificatesDir, listTrustStoreCommands())
+	return nil
+}
+
+// updateTrustStore copies the certificates into the trust store and rebuilds it.
+func (r *PorterRuntime) updateTrustStore(store *trustStore, certs []string) error {
+	err := r.FileSystem.MkdirAll(store.Dir, 0755)
+	if err != nil {
+		return errors.Wrapf(err, "could not create %s", store.Dir)
+	}
+	for _, cert := range certs {
+		dest := filepath.Join(store.Dir, "porter-"+cert)
+		if err = r.CopyFile(filepath.Join(config.CACertificatesDir, cert), dest); err != nil {
+			return errors.Wrapf(err, "could not copy CA certificate %s to %s", cert, store.Dir)
+		}
+	}
+
+	fmt.Fprintf(r.Out, "installing %d CA certificates\n", len(certs))
+	cmd := r.NewCommand(store.Command[0], store.Command[1:]...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "could not update the trust store with %s: %s", strings.Join(store.Command, " "), output)
+	}
+	return nil
+}
+
+// installCABundle writes a trust bundle, with the system CA certificates and
+// the certificates from porter, to a writable directory and points the
+// mixins at it with SSL_CERT_FILE and SSL_CERT_DIR. Returns false when the
+// invocation image does not have a system CA bundle to extend.
+func (r *PorterRuntime) installCABundle(certs []string) (bool, error) {
+	var systemBundle string
+	for _, path := range append([]string{r.Getenv("SSL_CERT_FILE")}, systemCABundles...) {
+		if path == "" {
+			continue
+		}
+		if exists, _ := r.FileSystem.Exists(path); exists {
+			systemBundle = path
+			break
+		}
+	}
+	if systemBundle == "" {
+		return false, nil
+	}
+
+	bundle, err := r.FileSystem.ReadFile(systemBundle)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not read the CA bundle %s", systemBundle)
+	}
+
+	dir, err := r.FileSystem.TempDir("", "porter-ca-certificates")
+	if err != nil {
+		return false, errors.Wrap(err, "could not create a directory for the CA bundle")
+	}
+	certsDir := filepath.Join(dir, "certs")
+	if err = r.FileSystem.MkdirAll(certsDir, 0755); err != nil {
+		return false, errors.Wrapf(err, "could not create %s", certsDir)
+	}
+
+	fmt.Fprintf(r.Out, "installing %d CA certificates into %s\n", len(certs), dir)
+	for _, cert := range certs {
+		data, err := r.FileSystem.ReadFile(filepath.Join(config.CACertificatesDir, cert))
+		if err != nil {
+			return false, errors.Wrapf(err, "could not read CA certificate %s", cert)
+		}
+		if err = r.FileSystem.WriteFile(filepath.Join(certsDir, "porter-"+cert), data, 0644); err != nil {
+			return false, errors.Wrapf(err, "could not copy CA certificate %s to %s", cert, certsDir)
+		}
+		bundle = append(append(bundle, '\n'), data...)
+	}
+
+	bundlePath := filepath.Join(dir, "ca-certificates.crt")
+	if err = r.FileSystem.WriteFile(bundlePath, bundle, 0644); err != nil {
+		return false, errors.Wrapf(err, "could not write the CA bundle %s", bundlePath)
+	}
+
+	certDirs := []string{certsDir}
+	for _, systemDir := range systemCADirs {
+		if exists, _ := r.FileSystem.DirExists(systemDir); exists {
+			certDirs = append(certDirs, systemDir)
+		}
+	}
+
+	r.Setenv("SSL_CERT_FILE", bundlePath)
+	r.Setenv("SSL_CERT_DIR", strings.Join(certDirs, string(os.PathListSeparator)))
+	return true, nil
+}
+
+func listTrustStoreCommands() string {
+	cmds := make([]string, len(trustStores))
+	for i, ts := range trustStores {
+		cmds[i] = ts.Command[0]
+	}
+	return strings.Join(cmds, " or ")
+}
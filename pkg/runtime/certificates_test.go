@@ -0,0 +1,124 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"get.porter.sh/porter/pkg/test"
+	"github.com/carolynvs/aferox"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPorterRuntime_installCACertificates(t *testing.T) {
+	t.Run("no certificates", func(t *testing.T) {
+		r := NewTestPorterRuntime(t)
+
+		err := r.installCACertificates()
+		require.NoError(t, err)
+		assert.Empty(t, r.TestContext.GetOutput())
+	})
+
+	t.Run("debian", func(t *testing.T) {
+		r := NewTestPorterRuntime(t)
+		r.TestContext.AddTestFileContents([]byte("acme root"), "/cnab/app/porter/ca-certificates/01-acme-root.crt")
+		r.TestContext.AddTestFileContents([]byte(""), "/usr/sbin/update-ca-certificates")
+		r.Setenv("PATH", "/usr/sbin")
+		r.Setenv(test.ExpectedCommandEnv, "update-ca-certificates")
+
+		err := r.installCACertificates()
+		require.NoError(t, err)
+		assert.Equal(t, "installing 1 CA certificates\n", r.TestContext.GetOutput())
+
+		cert, err := r.FileSystem.ReadFile("/usr/local/share/ca-certificates/porter-01-acme-root.crt")
+		require.NoError(t, err, "the certificate was not copied into the trust store")
+		assert.Equal(t, "acme root", string(cert))
+	})
+
+	t.Run("rhel", func(t *testing.T) {
+		r := NewTestPorterRuntime(t)
+		r.TestContext.AddTestFileContents([]byte("acme root"), "/cnab/app/porter/ca-certificates/01-acme-root.crt")
+		r.TestContext.AddTestFileContents([]byte(""), "/usr/bin/update-ca-trust")
+		r.Setenv("PATH", "/usr/bin")
+		r.Setenv(test.ExpectedCommandEnv, "update-ca-trust extract")
+
+		err := r.installCACertificates()
+		require.NoError(t, err)
+
+		exists, _ := r.FileSystem.Exists("/etc/pki/ca-trust/source/anchors/porter-01-acme-root.crt")
+		assert.True(t, exists, "the certificate was not copied into the trust store")
+	})
+
+	t.Run("non-root user", func(t *testing.T) {
+		r := NewTestPorterRuntime(t)
+		r.TestContext.AddTestFileContents([]byte("acme root"), "/cnab/app/porter/ca-certificates/01-acme-root.crt")
+		r.TestContext.AddTestFileContents([]byte("system roots"), "/etc/ssl/certs/ca-certificates.crt")
+		r.TestContext.AddTestFileContents([]byte(""), "/usr/sbin/update-ca-certificates")
+		r.Setenv("PATH", "/usr/sbin")
+		r.FileSystem = aferox.NewAferox("/", readOnlyDirFs{Fs: r.FileSystem.Fs, dir: "/usr/local/share"})
+
+		err := r.installCACertificates()
+		require.NoError(t, err)
+
+		bundlePath := r.Getenv("SSL_CERT_FILE")
+		require.NotEmpty(t, bundlePath, "SSL_CERT_FILE should point to the trust bundle")
+		bundle, err := r.FileSystem.ReadFile(bundlePath)
+		require.NoError(t, err)
+		assert.Equal(t, "system roots\nacme root", string(bundle), "the trust bundle should extend the system CA bundle")
+
+		certDirs := filepath.SplitList(r.Getenv("SSL_CERT_DIR"))
+		require.Len(t, certDirs, 2)
+		assert.Equal(t, filepath.Join(filepath.Dir(bundlePath), "certs"), certDirs[0])
+		assert.Equal(t, "/etc/ssl/certs", certDirs[1], "the system certificates should still be trusted")
+	})
+
+	t.Run("non-root user without a CA bundle", func(t *testing.T) {
+		r := NewTestPorterRuntime(t)
+		r.TestContext.AddTestFileContents([]byte("acme root"), "/cnab/app/porter/ca-certificates/01-acme-root.crt")
+		r.TestContext.AddTestFileContents([]byte(""), "/usr/sbin/update-ca-certificates")
+		r.Setenv("PATH", "/usr/sbin")
+		r.FileSystem = aferox.NewAferox("/", readOnlyDirFs{Fs: r.FileSystem.Fs, dir: "/usr/local/share"})
+
+		err := r.installCACertificates()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the user running the invocation image cannot install CA certificates")
+	})
+
+	t.Run("unsupported trust store", func(t *testing.T) {
+		r := NewTestPorterRuntime(t)
+		r.TestContext.AddTestFileContents([]byte("acme root"), "/cnab/app/porter/ca-certificates/01-acme-root.crt")
+		r.Setenv("PATH", "/usr/bin")
+
+		err := r.installCACertificates()
+		require.NoError(t, err)
+		assert.Contains(t, r.TestContext.GetError(), "WARNING: could not install the CA certificates in /cnab/app/porter/ca-certificates because the invocation image does not have update-ca-certificates or update-ca-trust")
+	})
+}
+
+// readOnlyDirFs denies writes to a directory, like a trust store that is
+// owned by root when the invocation image runs as a non-root user.
+type readOnlyDirFs struct {
+	afero.Fs
+	dir string
+}
+
+func (fs readOnlyDirFs) denied(path string) bool {
+	return strings.HasPrefix(filepath.Clean(path), fs.dir)
+}
+
+func (fs readOnlyDirFs) MkdirAll(path string, perm os.FileMode) error {
+	if fs.denied(path) {
+		return &os.PathError{Op: "mkdir", Path: path, Err: os.ErrPermission}
+	}
+	return fs.Fs.MkdirAll(path, perm)
+}
+
+func (fs readOnlyDirFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if fs.denied(name) && flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE) != 0 {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrPermission}
+	}
+	return fs.Fs.OpenFile(name, flag, perm)
+}
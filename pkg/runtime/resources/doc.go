@@ -0,0 +1,4 @@
+// Package resources defines the resources output, an inventory of the cloud
+// or cluster resources that a bundle created, which steps append to and the
+// runtime merges across steps.
+package resources // import "get.porter.sh/porter/pkg/runtime/resources"
@@ -0,0 +1,141 @@
+package resources
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// OutputName is the name of the output that steps write the resources that
+// they created to, and that the bundle uses to record its inventory.
+const OutputName = "resources"
+
+// Resource is a cloud or cluster resource owned by an installation.
+type Resource struct {
+	// Type of the resource, for example aws_s3_bucket or kubernetes/Deployment.
+	Type string `json:"type" yaml:"type"`
+
+	// ID of the resource, unique for its type.
+	ID string `json:"id" yaml:"id"`
+
+	// Name of the resource, when it differs from the ID.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Links to the resource with its provider, such as a console page, keyed by a label.
+	Links map[string]string `json:"links,omitempty" yaml:"links,omitempty"`
+}
+
+// Validate checks that the resource can be identified.
+func (r Resource) Validate() error {
+	if r.Type == "" {
+		return errors.Errorf("resource %q is missing a type", r.ID)
+	}
+	if r.ID == "" {
+		return errors.Errorf("resource of type %s is missing an id", r.Type)
+	}
+	return nil
+}
+
+// Resources is an inventory of resources.
+type Resources []Resource
+
+// Parse reads the resources reported by a step, which may be either a JSON
+// list of resources or a single resource. An empty value has no resources.
+func Parse(value string) (Resources, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "null" {
+		return Resources{}, nil
+	}
+
+	var result Resources
+	if strings.HasPrefix(value, "{") {
+		var r Resource
+		if err := json.Unmarshal([]byte(value), &r); err != nil {
+			return nil, errors.Wrapf(err, "could not parse the %s output", OutputName)
+		}
+		result = Resources{r}
+	} else if err := json.Unmarshal([]byte(value), &result); err != nil {
+		return nil, errors.Wrapf(err, "could not parse the %s output, it should be a list of resources", OutputName)
+	}
+
+	for _, r := range result {
+		if err := r.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s output", OutputName)
+		}
+	}
+	return result, nil
+}
+
+// Merge returns the resources with more appended. A resource with the same
+// type and id as an existing resource replaces it, keeping its position.
+func (r Resources) Merge(more Resources) Resources {
+	result := make(Resources, 0, len(r)+len(more))
+	index := make(map[string]int, len(r)+len(more))
+	for _, res := range append(append(Resources{}, r...), more...) {
+		key := res.Type + "/" + res.ID
+		if i, ok := index[key]; ok {
+			result[i] = res
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, res)
+	}
+	return result
+}
+
+// Match reports whether the resource's type and name match the provided
+// patterns, using the syntax of path.Match. An empty pattern matches any
+// value. Resources without a name are matched by their id.
+func (r Resource) Match(resourceType string, name string) (bool, error) {
+	ok, err := matches(resourceType, r.Type)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid type filter %q", resourceType)
+	}
+	if !ok {
+		return false, nil
+	}
+
+	resName := r.Name
+	if resName == "" {
+		resName = r.ID
+	}
+	ok, err = matches(name, resName)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid name filter %q", name)
+	}
+	return ok, nil
+}
+
+// Filter returns the resources that match the provided type and name
+// patterns, see Resource.Match.
+func (r Resources) Filter(resourceType string, name string) (Resources, error) {
+	result := Resources{}
+	for _, res := range r {
+		ok, err := res.Match(resourceType, name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, res)
+		}
+	}
+	return result, nil
+}
+
+func matches(pattern string, value string) (bool, error) {
+	if pattern == "" {
+		return true, nil
+	}
+	return path.Match(pattern, value)
+}
+
+// String returns the resources as the JSON value of the resources output.
+func (r Resources) String() string {
+	if r == nil {
+		r = Resources{}
+	}
+	b, _ := json.MarshalIndent(r, "", "  ")
+	return string(b)
+}
@@ -0,0 +1,111 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		got, err := Parse(`[{"type": "aws_s3_bucket", "id": "arn:aws:s3:::logs", "name": "logs", "links": {"console": "https://s3.console.aws.amazon.com/s3/buckets/logs"}}]`)
+		require.NoError(t, err)
+		want := Resources{{
+			Type:  "aws_s3_bucket",
+			ID:    "arn:aws:s3:::logs",
+			Name:  "logs",
+			Links: map[string]string{"console": "https://s3.console.aws.amazon.com/s3/buckets/logs"},
+		}}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("single resource", func(t *testing.T) {
+		got, err := Parse(`{"type": "kubernetes/Namespace", "id": "wordpress"}`)
+		require.NoError(t, err)
+		assert.Equal(t, Resources{{Type: "kubernetes/Namespace", ID: "wordpress"}}, got)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		got, err := Parse("\n")
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("missing id", func(t *testing.T) {
+		_, err := Parse(`[{"type": "kubernetes/Namespace", "name": "wordpress"}]`)
+		require.EqualError(t, err, "invalid resources output: resource of type kubernetes/Namespace is missing an id")
+	})
+
+	t.Run("not json", func(t *testing.T) {
+		_, err := Parse("wordpress")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not parse the resources output")
+	})
+}
+
+func TestResources_Merge(t *testing.T) {
+	existing := Resources{
+		{Type: "kubernetes/Namespace", ID: "wordpress"},
+		{Type: "kubernetes/Deployment", ID: "wordpress/web", Name: "web"},
+	}
+	more := Resources{
+		{Type: "kubernetes/Deployment", ID: "wordpress/web", Name: "web", Links: map[string]string{"dashboard": "https://example.com/web"}},
+		{Type: "aws_db_instance", ID: "db-1234", Name: "wordpress"},
+	}
+
+	got := existing.Merge(more)
+
+	want := Resources{
+		{Type: "kubernetes/Namespace", ID: "wordpress"},
+		{Type: "kubernetes/Deployment", ID: "wordpress/web", Name: "web", Links: map[string]string{"dashboard": "https://example.com/web"}},
+		{Type: "aws_db_instance", ID: "db-1234", Name: "wordpress"},
+	}
+	assert.Equal(t, want, got)
+	assert.Len(t, existing, 2, "Merge should not modify the existing resources")
+}
+
+func TestResources_Filter(t *testing.T) {
+	inventory := Resources{
+		{Type: "aws_s3_bucket", ID: "arn:aws:s3:::logs", Name: "logs"},
+		{Type: "aws_db_instance", ID: "db-1234", Name: "wordpress"},
+		{Type: "kubernetes/Namespace", ID: "wordpress"},
+	}
+
+	testcases := []struct {
+		name         string
+		resourceType string
+		resourceName string
+		want         Resources
+	}{
+		{"no filter", "", "", inventory},
+		{"exact type", "aws_s3_bucket", "", inventory[:1]},
+		{"type pattern", "aws_*", "", inventory[:2]},
+		{"name matches id", "", "wordpress", inventory[1:]},
+		{"type and name", "kubernetes/*", "word*", inventory[2:]},
+		{"no match", "azure_*", "", Resources{}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := inventory.Filter(tc.resourceType, tc.resourceName)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := inventory.Filter("[", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid type filter "["`)
+	})
+}
+
+func TestResources_String(t *testing.T) {
+	var empty Resources
+	assert.Equal(t, "[]", empty.String())
+
+	got, err := Parse(Resources{{Type: "kubernetes/Namespace", ID: "wordpress"}}.String())
+	require.NoError(t, err)
+	assert.Equal(t, Resources{{Type: "kubernetes/Namespace", ID: "wordpress"}}, got)
+}
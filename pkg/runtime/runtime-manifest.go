@@ -87,6 +87,13 @@ func (m *RuntimeManifest) GetInstallationName() string {
 func (m *RuntimeManifest) loadDependencyDefinitions() error {
 	m.bundles = make(map[string]bundle.Bundle, len(m.Dependencies))
 	for _, dep := range m.Dependencies {
+		// Optional dependencies that were skipped are not copied into the bundle
+		if dep.Optional {
+			if exists, _ := m.FileSystem.Exists(GetDependencyDefinitionPath(dep.Name)); !exists {
+				continue
+			}
+		}
+
 		bunD, err := GetDependencyDefinition(m.Context, dep.Name)
 		if err != nil {
 			return err
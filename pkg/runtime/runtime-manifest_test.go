@@ -673,7 +673,7 @@ WARNING: both tag (deprecated) and reference were provided for dependency "mysql
 			name:       "missing reference",
 			dep:        manifest.Dependency{Name: "mysql", Reference: ""},
 			wantOutput: "",
-			wantError:  `reference is required for dependency "mysql"`,
+			wantError:  `reference or path is required for dependency "mysql"`,
 		}, {
 			name:       "version double specified",
 			dep:        manifest.Dependency{Name: "mysql", Reference: "deislabs/azure-mysql:5.7", Versions: []string{"5.7.x-6"}},
@@ -3,12 +3,15 @@ package runtime
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/context"
 	"get.porter.sh/porter/pkg/manifest"
 	"get.porter.sh/porter/pkg/pkgmgmt"
+	"get.porter.sh/porter/pkg/runtime/resources"
+	"get.porter.sh/porter/pkg/runtime/steplogs"
 	"get.porter.sh/porter/pkg/yaml"
 	"github.com/cnabio/cnab-go/bundle"
 	"github.com/cnabio/cnab-go/bundle/loader"
@@ -21,6 +24,13 @@ type PorterRuntime struct {
 	*context.Context
 	mixins          pkgmgmt.PackageManager
 	RuntimeManifest *RuntimeManifest
+
+	// inventory of the resources reported by the steps that have run so far.
+	inventory resources.Resources
+
+	// lines tracks whether the output ends with a newline, so that the step
+	// markers are written on their own line.
+	lines *steplogs.LineWriter
 }
 
 func NewPorterRuntime(cxt *context.Context, mixins pkgmgmt.PackageManager) *PorterRuntime {
@@ -42,6 +52,11 @@ func (r *PorterRuntime) Execute(rm *RuntimeManifest) error {
 		return err
 	}
 
+	err = r.installCACertificates()
+	if err != nil {
+		return err
+	}
+
 	// Prepare prepares the runtime environment prior to step execution.
 	// As an example, for parameters of type "file", we may need to decode file contents
 	// on the filesystem before execution of the step/action
@@ -61,80 +76,141 @@ func (r *PorterRuntime) Execute(rm *RuntimeManifest) error {
 		return errors.Wrap(err, "unable to resolve bundle images")
 	}
 
-	err = r.FileSystem.MkdirAll(context.MixinOutputsDir, 0755)
+	err = r.createOutputsDir(context.MixinOutputsDir)
 	if err != nil {
-		return errors.Wrapf(err, "could not create outputs directory %s", context.MixinOutputsDir)
+		return err
 	}
 
-	for _, step := range r.RuntimeManifest.GetSteps() {
+	for i, step := range r.RuntimeManifest.GetSteps() {
 		if step != nil {
-			err := r.RuntimeManifest.ResolveStep(step)
+			err := r.executeStep(i+1, step)
 			if err != nil {
-				return errors.Wrap(err, "unable to resolve step")
+				return err
 			}
+		}
+	}
 
-			description, _ := step.GetDescription()
-			if len(description) > 0 {
-				fmt.Fprintln(r.Out, description)
-			}
+	err = r.applyUnboundBundleOutputs()
+	if err != nil {
+		return err
+	}
 
-			// Hand over values needing masking in context output streams
-			r.Context.SetSensitiveValues(r.RuntimeManifest.GetSensitiveValues())
+	fmt.Fprintln(r.Out, "execution completed successfully!")
+	return nil
+}
 
-			input := &ActionInput{
-				action: r.RuntimeManifest.Action,
-				Steps:  []*manifest.Step{step},
-			}
-			inputBytes, _ := yaml.Marshal(input)
-			cmd := pkgmgmt.CommandOptions{
-				Command: string(r.RuntimeManifest.Action),
-				Input:   string(inputBytes),
-				Runtime: true,
-			}
-			err = r.mixins.Run(r.Context, step.GetMixinName(), cmd)
-			if err != nil {
-				return errors.Wrap(err, "mixin execution failed")
-			}
+// executeStep runs a single step, delimiting its output in the logs with
+// markers so that the logs can be split by step.
+func (r *PorterRuntime) executeStep(index int, step *manifest.Step) error {
+	if r.lines == nil {
+		r.lines = steplogs.NewLineWriter(r.Out)
+		r.Out = r.lines
+	}
 
-			outputs, err := r.readMixinOutputs()
-			if err != nil {
-				return errors.Wrap(err, "could not read step outputs")
-			}
+	description, _ := step.GetDescription()
+	r.lines.EndLine()
+	steplogs.WriteStart(r.Out, index, description, step.GetMixinName())
 
-			err = r.RuntimeManifest.ApplyStepOutputs(outputs)
-			if err != nil {
-				return err
-			}
+	err := r.runStep(step)
+	status := steplogs.StatusSucceeded
+	if err != nil {
+		status = steplogs.StatusFailed
+	}
+	// The mixin's output may not end with a newline
+	r.lines.EndLine()
+	steplogs.WriteEnd(r.Out, index, status)
 
-			// Apply any Bundle Outputs declared in this step
-			err = r.applyStepOutputsToBundle(outputs)
-			if err != nil {
-				return err
-			}
-		}
+	return err
+}
+
+func (r *PorterRuntime) runStep(step *manifest.Step) error {
+	err := r.RuntimeManifest.ResolveStep(step)
+	if err != nil {
+		return errors.Wrap(err, "unable to resolve step")
 	}
 
-	err = r.applyUnboundBundleOutputs()
+	description, _ := step.GetDescription()
+	if len(description) > 0 {
+		fmt.Fprintln(r.Out, description)
+	}
+
+	// Hand over values needing masking in context output streams
+	r.Context.SetSensitiveValues(r.RuntimeManifest.GetSensitiveValues())
+
+	input := &ActionInput{
+		action: r.RuntimeManifest.Action,
+		Steps:  []*manifest.Step{step},
+	}
+	inputBytes, _ := yaml.Marshal(input)
+	cmd := pkgmgmt.CommandOptions{
+		Command: string(r.RuntimeManifest.Action),
+		Input:   string(inputBytes),
+		Runtime: true,
+	}
+	err = r.mixins.Run(r.Context, step.GetMixinName(), cmd)
+	if err != nil {
+		return errors.Wrap(err, "mixin execution failed")
+	}
+
+	outputs, err := r.readMixinOutputs()
+	if err != nil {
+		return errors.Wrap(err, "could not read step outputs")
+	}
+
+	err = r.mergeResources(outputs)
 	if err != nil {
 		return err
 	}
 
-	fmt.Fprintln(r.Out, "execution completed successfully!")
-	return nil
+	err = r.RuntimeManifest.ApplyStepOutputs(outputs)
+	if err != nil {
+		return err
+	}
+
+	// Apply any Bundle Outputs declared in this step
+	return r.applyStepOutputsToBundle(outputs)
 }
 
-func (r *PorterRuntime) createOutputsDir() error {
-	// Ensure outputs directory exists
-	if err := r.FileSystem.MkdirAll(config.BundleOutputsDir, 0755); err != nil {
-		return errors.Wrap(err, "unable to ensure CNAB outputs directory exists")
+// mergeResources appends the resources reported by a step to the inventory
+// from the previous steps, and replaces the step's resources output with the
+// merged inventory so that the bundle output includes every step.
+func (r *PorterRuntime) mergeResources(outputs map[string]string) error {
+	value, ok := outputs[resources.OutputName]
+	if !ok {
+		return nil
+	}
+
+	reported, err := resources.Parse(value)
+	if err != nil {
+		return err
 	}
+
+	r.inventory = r.inventory.Merge(reported)
+	outputs[resources.OutputName] = r.inventory.String()
 	return nil
 }
 
+// createOutputsDir ensures that a directory where outputs are written exists.
+// The directory is writable by the root group as well as the current user, so
+// that it can be used when the invocation image runs as an arbitrary user id.
+func (r *PorterRuntime) createOutputsDir(dir string) error {
+	err := r.FileSystem.MkdirAll(dir, 0775)
+	if err == nil {
+		return nil
+	}
+
+	if os.IsPermission(errors.Cause(err)) {
+		return errors.Wrapf(err, "the user running the invocation image cannot write outputs to %s. "+
+			"Set nonRoot: true in the porter.yaml to build an invocation image that runs as a non-root user, "+
+			"or make the directory writable by the user in the Dockerfile template", dir)
+	}
+	return errors.Wrapf(err, "could not create outputs directory %s", dir)
+}
+
 // applyStepOutputsToBundle writes the provided step outputs to the proper location
 // in the bundle execution environment.
 func (r *PorterRuntime) applyStepOutputsToBundle(outputs map[string]string) error {
-	err := r.createOutputsDir()
+	err := r.createOutputsDir(config.BundleOutputsDir)
 	if err != nil {
 		return err
 	}
@@ -161,7 +237,7 @@ func (r *PorterRuntime) applyStepOutputsToBundle(outputs map[string]string) erro
 // and if they can be bound, i.e. they grab a file from the bundle's filesystem,
 // apply the output.
 func (r *PorterRuntime) applyUnboundBundleOutputs() error {
-	err := r.createOutputsDir()
+	err := r.createOutputsDir(config.BundleOutputsDir)
 	if err != nil {
 		return err
 	}
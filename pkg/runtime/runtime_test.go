@@ -1,15 +1,24 @@
 package runtime
 
 import (
+	"bytes"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"get.porter.sh/porter/pkg/config"
 	"get.porter.sh/porter/pkg/context"
 	"get.porter.sh/porter/pkg/manifest"
+	"get.porter.sh/porter/pkg/mixin"
+	"get.porter.sh/porter/pkg/pkgmgmt"
+	"get.porter.sh/porter/pkg/runtime/resources"
+	"get.porter.sh/porter/pkg/runtime/steplogs"
+	"github.com/carolynvs/aferox"
 	"github.com/cnabio/cnab-go/bundle/definition"
 	"github.com/cnabio/cnab-go/claim"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -304,3 +313,133 @@ func TestLoadImageMappingFilesGoodFiles(t *testing.T) {
 	assert.NotEmpty(t, reloMap)
 	assert.Equal(t, "mysql", bun.Name)
 }
+
+func TestPorterRuntime_createOutputsDir(t *testing.T) {
+	t.Run("writable", func(t *testing.T) {
+		r := NewTestPorterRuntime(t)
+
+		err := r.createOutputsDir(config.BundleOutputsDir)
+		require.NoError(t, err)
+
+		info, err := r.FileSystem.Stat(config.BundleOutputsDir)
+		require.NoError(t, err)
+		assert.True(t, info.IsDir())
+	})
+
+	t.Run("not writable by the user", func(t *testing.T) {
+		r := NewTestPorterRuntime(t)
+		r.FileSystem = aferox.NewAferox("/", afero.NewReadOnlyFs(afero.NewMemMapFs()))
+
+		err := r.createOutputsDir(config.BundleOutputsDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the user running the invocation image cannot write outputs to /cnab/app/outputs")
+		assert.Contains(t, err.Error(), "nonRoot: true")
+	})
+}
+
+func TestPorterRuntime_executeStep(t *testing.T) {
+	newStep := func() *manifest.Step {
+		return &manifest.Step{Data: map[string]interface{}{
+			"exec": map[string]interface{}{"description": "Say Hello"},
+		}}
+	}
+
+	t.Run("succeeded", func(t *testing.T) {
+		r := NewTestPorterRuntime(t)
+		r.RuntimeManifest = NewRuntimeManifest(r.Context, claim.ActionInstall, &manifest.Manifest{})
+		require.NoError(t, r.createOutputsDir(context.MixinOutputsDir))
+
+		err := r.executeStep(1, newStep())
+		require.NoError(t, err)
+
+		steps := steplogs.Parse(r.TestContext.GetOutput())
+		require.Len(t, steps, 1)
+		assert.Equal(t, 1, steps[0].Index)
+		assert.Equal(t, "Say Hello", steps[0].Description)
+		assert.Equal(t, "exec", steps[0].Mixin)
+		assert.Equal(t, steplogs.StatusSucceeded, steps[0].Status)
+		assert.Contains(t, steps[0].Logs, "Say Hello\n")
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		r := NewTestPorterRuntime(t)
+		r.RuntimeManifest = NewRuntimeManifest(r.Context, claim.ActionInstall, &manifest.Manifest{})
+		mixins := r.mixins.(*mixin.TestMixinProvider)
+		mixins.RunAssertions = append(mixins.RunAssertions, func(pkgContext *context.Context, name string, commandOpts pkgmgmt.CommandOptions) error {
+			return errors.New("oops")
+		})
+
+		err := r.executeStep(2, newStep())
+		require.Error(t, err)
+
+		steps := steplogs.Parse(r.TestContext.GetOutput())
+		require.Len(t, steps, 1)
+		assert.Equal(t, 2, steps[0].Index)
+		assert.Equal(t, steplogs.StatusFailed, steps[0].Status)
+	})
+
+	t.Run("output without a trailing newline", func(t *testing.T) {
+		r := NewTestPorterRuntime(t)
+		r.RuntimeManifest = NewRuntimeManifest(r.Context, claim.ActionInstall, &manifest.Manifest{})
+		require.NoError(t, r.createOutputsDir(context.MixinOutputsDir))
+		mixins := r.mixins.(*mixin.TestMixinProvider)
+		mixins.RunAssertions = append(mixins.RunAssertions, func(pkgContext *context.Context, name string, commandOpts pkgmgmt.CommandOptions) error {
+			fmt.Fprint(pkgContext.Out, "progress: 100%")
+			return nil
+		})
+
+		require.NoError(t, r.executeStep(1, newStep()))
+		require.NoError(t, r.executeStep(2, newStep()))
+
+		output := r.TestContext.GetOutput()
+		steps := steplogs.Parse(output)
+		require.Len(t, steps, 2)
+		assert.Equal(t, steplogs.StatusSucceeded, steps[0].Status, "the end marker should be on its own line")
+		assert.True(t, strings.HasSuffix(steps[0].Logs, "progress: 100%\n"))
+		assert.NotContains(t, steplogs.Strip(output), steplogs.MarkerPrefix)
+
+		var filtered bytes.Buffer
+		_, err := steplogs.NewFilterWriter(&filtered).Write([]byte(output))
+		require.NoError(t, err)
+		assert.NotContains(t, filtered.String(), steplogs.MarkerPrefix)
+	})
+}
+
+func TestPorterRuntime_runStep_MergesResources(t *testing.T) {
+	r := NewTestPorterRuntime(t)
+	m := &manifest.Manifest{
+		Outputs: manifest.OutputDefinitions{
+			resources.OutputName: {
+				Name:   resources.OutputName,
+				Schema: definition.Schema{Type: "array"},
+			},
+		},
+	}
+	r.RuntimeManifest = NewRuntimeManifest(r.Context, claim.ActionInstall, m)
+	require.NoError(t, r.createOutputsDir(context.MixinOutputsDir))
+
+	reported := []string{
+		`[{"type": "kubernetes/Namespace", "id": "wordpress"}, {"type": "kubernetes/Deployment", "id": "wordpress/web"}]`,
+		`{"type": "kubernetes/Deployment", "id": "wordpress/web", "name": "web"}`,
+	}
+	mixins := r.mixins.(*mixin.TestMixinProvider)
+	mixins.RunAssertions = append(mixins.RunAssertions, func(pkgContext *context.Context, name string, commandOpts pkgmgmt.CommandOptions) error {
+		value := reported[0]
+		reported = reported[1:]
+		return pkgContext.FileSystem.WriteFile(filepath.Join(context.MixinOutputsDir, resources.OutputName), []byte(value), 0644)
+	})
+
+	step := &manifest.Step{Data: map[string]interface{}{"exec": map[string]interface{}{}}}
+	require.NoError(t, r.runStep(step))
+	require.NoError(t, r.runStep(step))
+
+	value, err := r.FileSystem.ReadFile(filepath.Join(config.BundleOutputsDir, resources.OutputName))
+	require.NoError(t, err)
+	got, err := resources.Parse(string(value))
+	require.NoError(t, err)
+	want := resources.Resources{
+		{Type: "kubernetes/Namespace", ID: "wordpress"},
+		{Type: "kubernetes/Deployment", ID: "wordpress/web", Name: "web"},
+	}
+	assert.Equal(t, want, got)
+}
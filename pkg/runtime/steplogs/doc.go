@@ -0,0 +1,3 @@
+// Package steplogs delimits the output of each step in the logs of a bundle
+// run, so that the logs can be split by step after the run.
+package steplogs // import "get.porter.sh/porter/pkg/runtime/steplogs"
@@ -0,0 +1,185 @@
+package steplogs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MarkerPrefix starts each line in the logs that marks the start or end
+	// of a step.
+	MarkerPrefix = "::porter-step::"
+
+	// EventStart marks the start of a step.
+	EventStart = "start"
+
+	// EventEnd marks the end of a step.
+	EventEnd = "end"
+
+	// StatusSucceeded is the status of a step that completed successfully.
+	StatusSucceeded = "succeeded"
+
+	// StatusFailed is the status of a step that returned an error.
+	StatusFailed = "failed"
+
+	// StatusUnknown is the status of a step that did not record its end, for
+	// example because the invocation image was stopped.
+	StatusUnknown = "unknown"
+)
+
+// Marker is written to the logs by the runtime at the start and end of each step.
+type Marker struct {
+	Event       string    `json:"event"`
+	Index       int       `json:"index"`
+	Description string    `json:"description,omitempty"`
+	Mixin       string    `json:"mixin,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+// WriteStart writes a marker for the start of a step. Steps are numbered from 1.
+func WriteStart(w io.Writer, index int, description string, mixin string) error {
+	return writeMarker(w, Marker{
+		Event:       EventStart,
+		Index:       index,
+		Description: description,
+		Mixin:       mixin,
+		Time:        time.Now().UTC(),
+	})
+}
+
+// WriteEnd writes a marker for the end of a step.
+func WriteEnd(w io.Writer, index int, status string) error {
+	return writeMarker(w, Marker{
+		Event:  EventEnd,
+		Index:  index,
+		Status: status,
+		Time:   time.Now().UTC(),
+	})
+}
+
+func writeMarker(w io.Writer, m Marker) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal step log marker")
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", MarkerPrefix, b)
+	return err
+}
+
+// Step is the logs of a single step, along with its metadata.
+type Step struct {
+	Index       int       `json:"index" yaml:"index"`
+	Description string    `json:"description,omitempty" yaml:"description,omitempty"`
+	Mixin       string    `json:"mixin" yaml:"mixin"`
+	Status      string    `json:"status" yaml:"status"`
+	Start       time.Time `json:"start" yaml:"start"`
+	End         time.Time `json:"end,omitempty" yaml:"end,omitempty"`
+	Logs        string    `json:"-" yaml:"-"`
+}
+
+// Size of the step's logs in bytes.
+func (s Step) Size() int {
+	return len(s.Logs)
+}
+
+// Duration of the step, or zero when its end was not recorded.
+func (s Step) Duration() time.Duration {
+	if s.End.IsZero() {
+		return 0
+	}
+	return s.End.Sub(s.Start)
+}
+
+// Parse splits logs by the step markers. Output that is not between the
+// start and end of a step, such as the runtime's own messages, is not
+// included. Logs without markers return no steps.
+func Parse(logs string) []Step {
+	var steps []Step
+	var current *Step
+	var buf strings.Builder
+
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		marker, ok := parseMarker(line)
+		if !ok {
+			if current != nil {
+				buf.WriteString(line)
+				buf.WriteString("\n")
+			}
+			continue
+		}
+
+		switch marker.Event {
+		case EventStart:
+			if current != nil {
+				// The previous step did not record its end
+				current.Logs = buf.String()
+				steps = append(steps, *current)
+			}
+			buf.Reset()
+			current = &Step{
+				Index:       marker.Index,
+				Description: marker.Description,
+				Mixin:       marker.Mixin,
+				Status:      StatusUnknown,
+				Start:       marker.Time,
+			}
+		case EventEnd:
+			if current == nil || current.Index != marker.Index {
+				continue
+			}
+			current.Status = marker.Status
+			current.End = marker.Time
+			current.Logs = buf.String()
+			steps = append(steps, *current)
+			current = nil
+			buf.Reset()
+		}
+	}
+
+	if current != nil {
+		current.Logs = buf.String()
+		steps = append(steps, *current)
+	}
+
+	return steps
+}
+
+// Strip removes the step markers from logs.
+func Strip(logs string) string {
+	if !strings.Contains(logs, MarkerPrefix) {
+		return logs
+	}
+
+	lines := strings.SplitAfter(logs, "\n")
+	var b strings.Builder
+	for _, line := range lines {
+		if _, ok := parseMarker(strings.TrimSuffix(line, "\n")); ok {
+			continue
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+func parseMarker(line string) (Marker, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	if !strings.HasPrefix(line, MarkerPrefix) {
+		return Marker{}, false
+	}
+
+	var m Marker
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, MarkerPrefix)), &m); err != nil {
+		return Marker{}, false
+	}
+	return m, true
+}
@@ -0,0 +1,134 @@
+package steplogs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	logs, err := ioutil.ReadFile("testdata/logs.txt")
+	require.NoError(t, err)
+
+	steps := Parse(string(logs))
+	require.Len(t, steps, 2)
+
+	install := steps[0]
+	assert.Equal(t, 1, install.Index)
+	assert.Equal(t, "Install MySQL", install.Description)
+	assert.Equal(t, "helm3", install.Mixin)
+	assert.Equal(t, StatusSucceeded, install.Status)
+	assert.Equal(t, 5*time.Second, install.Duration())
+	assert.Equal(t, "Install MySQL\nmysql installed\n", install.Logs)
+
+	createDB := steps[1]
+	assert.Equal(t, 2, createDB.Index)
+	assert.Equal(t, StatusFailed, createDB.Status)
+	assert.Equal(t, "Create database\nERROR 1045 (28000): Access denied for user 'root'\n", createDB.Logs)
+}
+
+func TestParse_StepNotEnded(t *testing.T) {
+	var logs bytes.Buffer
+	require.NoError(t, WriteStart(&logs, 1, "Install MySQL", "helm3"))
+	logs.WriteString("installing...\n")
+
+	steps := Parse(logs.String())
+	require.Len(t, steps, 1)
+	assert.Equal(t, StatusUnknown, steps[0].Status)
+	assert.Equal(t, time.Duration(0), steps[0].Duration())
+	assert.Equal(t, "installing...\n", steps[0].Logs)
+}
+
+func TestParse_NoMarkers(t *testing.T) {
+	steps := Parse("logs from an older version of porter\n")
+	assert.Empty(t, steps)
+}
+
+func TestStrip(t *testing.T) {
+	logs, err := ioutil.ReadFile("testdata/logs.txt")
+	require.NoError(t, err)
+
+	stripped := Strip(string(logs))
+	assert.NotContains(t, stripped, MarkerPrefix)
+	assert.Contains(t, stripped, "executing install action from mybuns (installation: mybuns)\nInstall MySQL\nmysql installed\nCreate database\n")
+}
+
+func TestFilterWriter(t *testing.T) {
+	logs, err := ioutil.ReadFile("testdata/logs.txt")
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name      string
+		chunkSize int
+	}{
+		{name: "whole", chunkSize: len(logs)},
+		{name: "lines", chunkSize: 40},
+		{name: "bytes", chunkSize: 1},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := NewFilterWriter(&out)
+			for i := 0; i < len(logs); i += tc.chunkSize {
+				end := i + tc.chunkSize
+				if end > len(logs) {
+					end = len(logs)
+				}
+				n, err := w.Write(logs[i:end])
+				require.NoError(t, err)
+				assert.Equal(t, end-i, n)
+			}
+
+			assert.Equal(t, Strip(string(logs)), out.String())
+		})
+	}
+
+	t.Run("looks like a marker", func(t *testing.T) {
+		var out bytes.Buffer
+		w := NewFilterWriter(&out)
+		const output = ":: not a marker\n::porter-step::oops\nprogress: 50%"
+		_, err := w.Write([]byte(output))
+		require.NoError(t, err)
+		assert.Equal(t, output, out.String())
+	})
+}
+
+func TestLineWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := NewLineWriter(&out)
+	require.NoError(t, w.EndLine())
+	assert.Empty(t, out.String(), "no newline should be written when nothing was written")
+
+	fmt.Fprint(w, "progress: 100%")
+	require.NoError(t, w.EndLine())
+	require.NoError(t, WriteEnd(w, 1, StatusSucceeded))
+	require.NoError(t, w.EndLine())
+
+	steps := Parse(MarkerPrefix + `{"event":"start","index":1}` + "\n" + out.String())
+	require.Len(t, steps, 1)
+	assert.Equal(t, StatusSucceeded, steps[0].Status, "the end marker should be on its own line")
+	assert.Equal(t, "progress: 100%\n", steps[0].Logs)
+}
+
+func TestWriteStart(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteStart(&out, 3, "Deploy", "kubernetes"))
+
+	line := out.String()
+	assert.True(t, strings.HasPrefix(line, MarkerPrefix), "the marker should start with the prefix")
+	assert.True(t, strings.HasSuffix(line, "\n"), "the marker should be a complete line")
+
+	m, ok := parseMarker(strings.TrimSuffix(line, "\n"))
+	require.True(t, ok)
+	assert.Equal(t, EventStart, m.Event)
+	assert.Equal(t, 3, m.Index)
+	assert.Equal(t, "Deploy", m.Description)
+	assert.Equal(t, "kubernetes", m.Mixin)
+}
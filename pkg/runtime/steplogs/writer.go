@@ -0,0 +1,116 @@
+package steplogs
+
+import (
+	"bytes"
+	"io"
+)
+
+var markerPrefix = []byte(MarkerPrefix)
+
+// FilterWriter removes the step markers from output as it is written, so that
+// they are not displayed when a bundle is run. Lines that may be a marker are
+// held until they are complete, all other output is written immediately. The
+// runtime writes each marker on its own line with a LineWriter, so output
+// that ends without a newline is released by the next marker.
+type FilterWriter struct {
+	w           io.Writer
+	line        []byte
+	holding     bool
+	atLineStart bool
+}
+
+// NewFilterWriter creates a writer that removes step markers from the output
+// written to w.
+func NewFilterWriter(w io.Writer) *FilterWriter {
+	return &FilterWriter{w: w, atLineStart: true}
+}
+
+func (f *FilterWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if f.holding {
+			chunk := p
+			if i := bytes.IndexByte(p, '\n'); i >= 0 {
+				chunk = p[:i+1]
+			}
+			p = p[len(chunk):]
+			f.line = append(f.line, chunk...)
+
+			complete := f.line[len(f.line)-1] == '\n'
+			if !mayBeMarker(f.line, complete) || (complete && !isMarker(f.line)) {
+				// Not a marker after all, so write what was held
+				f.holding = false
+				f.atLineStart = complete
+				if _, err := f.w.Write(f.line); err != nil {
+					return n, err
+				}
+			} else if complete {
+				// Drop the marker
+				f.holding = false
+				f.atLineStart = true
+			}
+			continue
+		}
+
+		if f.atLineStart && p[0] == markerPrefix[0] {
+			f.holding = true
+			f.line = f.line[:0]
+			continue
+		}
+
+		chunk := p
+		i := bytes.IndexByte(p, '\n')
+		if i >= 0 {
+			chunk = p[:i+1]
+		}
+		p = p[len(chunk):]
+		f.atLineStart = i >= 0
+		if _, err := f.w.Write(chunk); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func mayBeMarker(line []byte, complete bool) bool {
+	if len(line) < len(markerPrefix) {
+		return !complete && bytes.HasPrefix(markerPrefix, line)
+	}
+	return bytes.HasPrefix(line, markerPrefix)
+}
+
+func isMarker(line []byte) bool {
+	_, ok := parseMarker(string(bytes.TrimSuffix(line, []byte("\n"))))
+	return ok
+}
+
+// LineWriter tracks whether the output written to it ends with a newline, so
+// that a step marker can be written on its own line after output, such as a
+// mixin's, that does not end with one.
+type LineWriter struct {
+	w           io.Writer
+	atLineStart bool
+}
+
+// NewLineWriter creates a writer that tracks whether the output written to w
+// ends with a newline.
+func NewLineWriter(w io.Writer) *LineWriter {
+	return &LineWriter{w: w, atLineStart: true}
+}
+
+func (l *LineWriter) Write(p []byte) (int, error) {
+	n, err := l.w.Write(p)
+	if n > 0 {
+		l.atLineStart = p[n-1] == '\n'
+	}
+	return n, err
+}
+
+// EndLine writes a newline when the output does not end with one.
+func (l *LineWriter) EndLine() error {
+	if l.atLineStart {
+		return nil
+	}
+	_, err := l.Write([]byte("\n"))
+	return err
+}
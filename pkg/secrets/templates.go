@@ -0,0 +1,195 @@
+package secrets
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"get.porter.sh/porter/pkg/context"
+	cnabsecrets "github.com/cnabio/cnab-go/secrets"
+	"github.com/cnabio/cnab-go/valuesource"
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+)
+
+// templateExpr matches a template expression embedded in a strategy value,
+// for example {{ env.CLUSTER }}. Only the supported prefixes are matched, so
+// that other templates, such as helm or go templates passed to a bundle, are
+// left as-is.
+var templateExpr = regexp.MustCompile(`{{\s*((?:env|installation|credentials|parameters)\.[^{}\s]*)\s*}}`)
+
+// TemplateContext is the data available to templates in the source values of
+// a credential or parameter set.
+//
+// Templates are written as {{ env.NAME }}, {{ installation.name }}, and
+// {{ credentials.NAME }} or {{ parameters.NAME }} to reference another entry
+// in the same set. Anything else between braces is not a template.
+type TemplateContext struct {
+	// Context provides access to the environment variables.
+	*context.Context
+
+	// Installation is the name of the installation the set is being resolved for.
+	Installation string
+}
+
+// ResolveStrategies resolves each strategy in a set, first expanding any
+// templates in the source value and then resolving the expanded value with the
+// secret store. The itemType is the type of entry in the set, either
+// "credential" or "parameter", and entries are referenced in templates by
+// its plural, for example {{ credentials.NAME }}. Entries that reference other
+// entries in the set are resolved after the entries they depend upon, and
+// cycles are reported as an error.
+//
+// Values that are sourced from a secret, or built from a template that
+// references a secret-derived entry, are registered as sensitive with the
+// context so that they are masked when printed.
+func ResolveStrategies(store cnabsecrets.Store, tc TemplateContext, itemType string, setName string, strategies []valuesource.Strategy) (valuesource.Set, error) {
+	r := strategyResolver{
+		store:      store,
+		tc:         tc,
+		itemType:   itemType,
+		setName:    setName,
+		strategies: make(map[string]valuesource.Strategy, len(strategies)),
+		resolved:   make(valuesource.Set, len(strategies)),
+		sensitive:  make(map[string]bool, len(strategies)),
+		failed:     make(map[string]bool, len(strategies)),
+		visiting:   make(map[string]bool, len(strategies)),
+	}
+	for _, s := range strategies {
+		r.strategies[s.Name] = s
+	}
+
+	var resolveErrors error
+	for _, s := range strategies {
+		if r.failed[s.Name] {
+			// The error was already reported when a dependent entry was resolved
+			continue
+		}
+		_, err := r.resolve(s.Name, nil)
+		if err != nil {
+			resolveErrors = multierror.Append(resolveErrors, err)
+		}
+	}
+
+	var sensitiveValues []string
+	for name, isSensitive := range r.sensitive {
+		if isSensitive {
+			sensitiveValues = append(sensitiveValues, r.resolved[name])
+		}
+	}
+	if tc.Context != nil {
+		tc.AddSensitiveValues(sensitiveValues)
+	}
+
+	return r.resolved, resolveErrors
+}
+
+type strategyResolver struct {
+	store      cnabsecrets.Store
+	tc         TemplateContext
+	itemType   string
+	setName    string
+	strategies map[string]valuesource.Strategy
+	resolved   valuesource.Set
+
+	// sensitive tracks which resolved entries were derived from a secret.
+	sensitive map[string]bool
+
+	// failed tracks the entries that could not be resolved.
+	failed map[string]bool
+
+	// visiting tracks the entries currently being resolved, for cycle detection.
+	visiting map[string]bool
+}
+
+// resolve the named entry, returning whether its value was derived from a secret.
+// path is the chain of entries that led to this entry being resolved.
+func (r *strategyResolver) resolve(name string, path []string) (bool, error) {
+	if r.failed[name] {
+		return false, errors.Errorf("%s %s.%s could not be resolved", r.itemType, r.setName, name)
+	}
+	if _, done := r.resolved[name]; done {
+		return r.sensitive[name], nil
+	}
+
+	path = append(path, name)
+	if r.visiting[name] {
+		return false, errors.Errorf("cycle detected in %s set %s: %s", r.itemType, r.setName, strings.Join(path, " -> "))
+	}
+	r.visiting[name] = true
+	defer delete(r.visiting, name)
+
+	s := r.strategies[name]
+	keyValue, sensitive, err := r.expand(s.Source.Value, path)
+	if err == nil {
+		var value string
+		value, err = r.store.Resolve(s.Source.Key, keyValue)
+		r.resolved[name] = value
+	}
+	if err != nil {
+		r.failed[name] = true
+		r.resolved[name] = ""
+		return false, errors.Wrapf(err, "unable to resolve %s %s.%s from %s %s", r.itemType, r.setName, name, s.Source.Key, s.Source.Value)
+	}
+
+	sensitive = sensitive || s.Source.Key == SourceSecret
+	r.sensitive[name] = sensitive
+	return sensitive, nil
+}
+
+// expand the templates in a source value, returning whether any referenced
+// entry was derived from a secret.
+func (r *strategyResolver) expand(value string, path []string) (string, bool, error) {
+	var sensitive bool
+	var expandErr error
+	result := templateExpr.ReplaceAllStringFunc(value, func(match string) string {
+		if expandErr != nil {
+			return match
+		}
+		expr := templateExpr.FindStringSubmatch(match)[1]
+		exprValue, exprSensitive, err := r.evaluate(expr, path)
+		if err != nil {
+			expandErr = err
+			return match
+		}
+		sensitive = sensitive || exprSensitive
+		return exprValue
+	})
+	return result, sensitive, expandErr
+}
+
+// evaluate a single template expression, such as env.CLUSTER.
+func (r *strategyResolver) evaluate(expr string, path []string) (string, bool, error) {
+	parts := strings.SplitN(expr, ".", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", false, errors.Errorf("invalid template expression %q", expr)
+	}
+
+	switch parts[0] {
+	case "env":
+		if r.tc.Context == nil {
+			return "", false, errors.Errorf("environment variable %s is not defined", parts[1])
+		}
+		value, ok := r.tc.LookupEnv(parts[1])
+		if !ok {
+			return "", false, errors.Errorf("environment variable %s is not defined", parts[1])
+		}
+		return value, false, nil
+	case "installation":
+		if parts[1] != "name" {
+			return "", false, errors.Errorf("invalid template expression %q, only installation.name is supported", expr)
+		}
+		return r.tc.Installation, false, nil
+	case r.itemType + "s":
+		if _, ok := r.strategies[parts[1]]; !ok {
+			return "", false, fmt.Errorf("%s set %s does not define %s", r.itemType, r.setName, parts[1])
+		}
+		sensitive, err := r.resolve(parts[1], path)
+		if err != nil {
+			return "", false, err
+		}
+		return r.resolved[parts[1]], sensitive, nil
+	default:
+		return "", false, errors.Errorf("invalid template expression %q, supported prefixes are env, installation and %ss", expr, r.itemType)
+	}
+}
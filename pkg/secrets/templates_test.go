@@ -0,0 +1,141 @@
+package secrets
+
+import (
+	"fmt"
+	"testing"
+
+	"get.porter.sh/porter/pkg/context"
+	"github.com/cnabio/cnab-go/secrets/host"
+	"github.com/cnabio/cnab-go/valuesource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// templateTestStore resolves secrets from a map and everything else from the host.
+type templateTestStore struct {
+	host.SecretStore
+	secrets map[string]string
+}
+
+func (s *templateTestStore) Resolve(keyName string, keyValue string) (string, error) {
+	if keyName != SourceSecret {
+		return s.SecretStore.Resolve(keyName, keyValue)
+	}
+	value, ok := s.secrets[keyValue]
+	if !ok {
+		return "", fmt.Errorf("secret %s not found", keyValue)
+	}
+	return value, nil
+}
+
+func strategy(name string, key string, value string) valuesource.Strategy {
+	return valuesource.Strategy{Name: name, Source: valuesource.Source{Key: key, Value: value}}
+}
+
+func TestResolveStrategies(t *testing.T) {
+	store := &templateTestStore{secrets: map[string]string{
+		"dev-token": "topsecret",
+	}}
+
+	t.Run("env and installation", func(t *testing.T) {
+		cxt := context.NewTestContext(t)
+		cxt.Setenv("CLUSTER", "east")
+		tc := TemplateContext{Context: cxt.Context, Installation: "mysql"}
+
+		resolved, err := ResolveStrategies(store, tc, "credential", "mycreds", []valuesource.Strategy{
+			strategy("url", host.SourceValue, "https://{{ env.CLUSTER }}.example.com/{{installation.name}}"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "https://east.example.com/mysql", resolved["url"])
+	})
+
+	t.Run("templated secret name", func(t *testing.T) {
+		cxt := context.NewTestContext(t)
+		cxt.Setenv("ENVIRONMENT", "dev")
+		tc := TemplateContext{Context: cxt.Context}
+
+		resolved, err := ResolveStrategies(store, tc, "credential", "mycreds", []valuesource.Strategy{
+			strategy("token", SourceSecret, "{{ env.ENVIRONMENT }}-token"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "topsecret", resolved["token"])
+	})
+
+	t.Run("reference other entries", func(t *testing.T) {
+		cxt := context.NewTestContext(t)
+		tc := TemplateContext{Context: cxt.Context}
+
+		resolved, err := ResolveStrategies(store, tc, "parameter", "myparams", []valuesource.Strategy{
+			strategy("connstr", host.SourceValue, "postgres://{{ parameters.user }}:{{ parameters.password }}@db"),
+			strategy("user", host.SourceValue, "admin"),
+			strategy("password", SourceSecret, "dev-token"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "postgres://admin:topsecret@db", resolved["connstr"])
+
+		fmt.Fprintln(cxt.Out, resolved["connstr"])
+		assert.NotContains(t, cxt.GetOutput(), "topsecret", "secret derived values should be masked")
+		assert.Contains(t, cxt.GetOutput(), "*******")
+	})
+
+	t.Run("other templates are left as-is", func(t *testing.T) {
+		cxt := context.NewTestContext(t)
+		tc := TemplateContext{Context: cxt.Context, Installation: "mysql"}
+
+		resolved, err := ResolveStrategies(store, tc, "parameter", "myparams", []valuesource.Strategy{
+			strategy("release", host.SourceValue, "{{ .Release.Name }}-{{ installation.name }}"),
+			strategy("values", host.SourceValue, "{{ bundle.parameters.port }} {{include \"chart\"}}"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "{{ .Release.Name }}-mysql", resolved["release"])
+		assert.Equal(t, "{{ bundle.parameters.port }} {{include \"chart\"}}", resolved["values"])
+	})
+
+	t.Run("sensitive values are registered once", func(t *testing.T) {
+		cxt := context.NewTestContext(t)
+		tc := TemplateContext{Context: cxt.Context}
+		strategies := []valuesource.Strategy{strategy("password", SourceSecret, "dev-token")}
+
+		_, err := ResolveStrategies(store, tc, "parameter", "myparams", strategies)
+		require.NoError(t, err)
+		out := cxt.Out
+		_, err = ResolveStrategies(store, tc, "parameter", "myparams", strategies)
+		require.NoError(t, err)
+		assert.Same(t, out, cxt.Out, "the output should only be wrapped once")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		cxt := context.NewTestContext(t)
+		tc := TemplateContext{Context: cxt.Context}
+
+		_, err := ResolveStrategies(store, tc, "credential", "mycreds", []valuesource.Strategy{
+			strategy("a", host.SourceValue, "{{ credentials.b }}"),
+			strategy("b", host.SourceValue, "{{ credentials.a }}"),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cycle detected in credential set mycreds: a -> b -> a")
+	})
+
+	t.Run("missing env", func(t *testing.T) {
+		cxt := context.NewTestContext(t)
+		cxt.Clearenv()
+		tc := TemplateContext{Context: cxt.Context}
+
+		resolved, err := ResolveStrategies(store, tc, "credential", "mycreds", []valuesource.Strategy{
+			strategy("url", host.SourceValue, "https://{{ env.CLUSTER }}.example.com"),
+		})
+		require.EqualError(t, err, "1 error occurred:\n\t* unable to resolve credential mycreds.url from value https://{{ env.CLUSTER }}.example.com: environment variable CLUSTER is not defined\n\n")
+		assert.Equal(t, "", resolved["url"])
+	})
+
+	t.Run("undefined entry", func(t *testing.T) {
+		cxt := context.NewTestContext(t)
+		tc := TemplateContext{Context: cxt.Context}
+
+		_, err := ResolveStrategies(store, tc, "credential", "mycreds", []valuesource.Strategy{
+			strategy("url", host.SourceValue, "{{ credentials.host }}"),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "credential set mycreds does not define host")
+	})
+}
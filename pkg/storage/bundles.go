@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cnabio/cnab-go/claim"
+	"github.com/cnabio/cnab-go/schema"
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ItemTypeBundleDefinitions is the type of the bundle definitions that
+	// are referenced by claims, named by the digest of the bundle definition.
+	ItemTypeBundleDefinitions = "bundle-definitions"
+
+	// BundleDefinitionsSchemaVersion is the version of the storage format for
+	// claims that reference their bundle definition, instead of including it.
+	BundleDefinitionsSchemaVersion schema.Version = "1.0.0"
+
+	// claimBundleKey is the field of a claim that contains its bundle definition.
+	claimBundleKey = "bundle"
+
+	// claimBundleDigestKey is the field of a stored claim that references its
+	// bundle definition, replacing the bundle field.
+	claimBundleDigestKey = "bundleDigest"
+)
+
+// dedupeClaimBundle stores the bundle definition of a claim separately, named by its
+// digest, and returns the claim with a reference to the bundle definition.
+// Claims for the same bundle share a single copy of its bundle definition.
+// Data that cannot be parsed, such as an encrypted claim, is returned as-is.
+func (m *Manager) dedupeClaimBundle(data []byte) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return data, nil
+	}
+
+	bun, ok := doc[claimBundleKey]
+	if !ok {
+		return data, nil
+	}
+
+	var compactBun bytes.Buffer
+	if err := json.Compact(&compactBun, bun); err != nil {
+		return nil, errors.Wrap(err, "could not parse the bundle definition of the claim")
+	}
+
+	// Always save the bundle definition, even when it was saved before, because
+	// another process may have removed it since then when it was no longer used.
+	// The document is named by its digest, so saving it again does not change it.
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(compactBun.Bytes()))
+	err := m.BackingStore.Save(ItemTypeBundleDefinitions, "", bundleDefinitionName(digest), compactBun.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not save bundle definition %s", digest)
+	}
+	m.cacheBundleDefinition(digest, compactBun.Bytes())
+
+	digestB, err := json.Marshal(digest)
+	if err != nil {
+		return nil, err
+	}
+	delete(doc, claimBundleKey)
+	doc[claimBundleDigestKey] = digestB
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// rehydrateClaim replaces the bundle definition reference in a stored claim
+// with the bundle definition, so that the claim is in the format defined by
+// the CNAB Claim spec. Claims that include their bundle definition are
+// returned as-is.
+func (m *Manager) rehydrateClaim(data []byte) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return data, nil
+	}
+
+	digest, err := readClaimBundleDigest(doc)
+	if err != nil || digest == "" {
+		return data, err
+	}
+
+	bun, err := m.readBundleDefinition(digest)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(doc, claimBundleDigestKey)
+	doc[claimBundleKey] = bun
+
+	return json.Marshal(doc)
+}
+
+// readClaimBundleDigest returns the digest of the bundle definition that a
+// stored claim references, or an empty string when the claim includes its
+// bundle definition.
+func readClaimBundleDigest(doc map[string]json.RawMessage) (string, error) {
+	digestB, ok := doc[claimBundleDigestKey]
+	if !ok {
+		return "", nil
+	}
+
+	var digest string
+	if err := json.Unmarshal(digestB, &digest); err != nil {
+		return "", errors.Wrapf(err, "invalid bundle definition reference %s", string(digestB))
+	}
+	return digest, nil
+}
+
+// readBundleDefinition reads a bundle definition by its digest. Bundle
+// definitions never change, so they are cached once read, and a bundle that
+// is referenced by many claims is only read once.
+func (m *Manager) readBundleDefinition(digest string) ([]byte, error) {
+	if bun, ok := m.bundleDefinitions[digest]; ok {
+		return bun, nil
+	}
+
+	bun, err := m.BackingStore.Read(ItemTypeBundleDefinitions, bundleDefinitionName(digest))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read bundle definition %s", digest)
+	}
+
+	m.cacheBundleDefinition(digest, bun)
+	return bun, nil
+}
+
+func (m *Manager) cacheBundleDefinition(digest string, bun []byte) {
+	if m.bundleDefinitions == nil {
+		m.bundleDefinitions = make(map[string][]byte)
+	}
+	m.bundleDefinitions[digest] = bun
+}
+
+// listUsedBundleDefinitions returns the digests of the bundle definitions
+// that are referenced by at least one claim.
+func (m *Manager) listUsedBundleDefinitions() (map[string]bool, error) {
+	installationNames, err := m.BackingStore.List(claim.ItemTypeInstallations, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list installations")
+	}
+
+	used := make(map[string]bool)
+	for _, installationName := range installationNames {
+		claims, err := m.BackingStore.ReadAll(claim.ItemTypeClaims, installationName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read the claims of installation %s", installationName)
+		}
+
+		for _, data := range claims {
+			var doc map[string]json.RawMessage
+			if err := json.Unmarshal(data, &doc); err != nil {
+				// Claims that cannot be parsed, such as an encrypted claim,
+				// include their bundle definition
+				continue
+			}
+			digest, err := readClaimBundleDigest(doc)
+			if err != nil {
+				return nil, err
+			}
+			if digest != "" {
+				used[digest] = true
+			}
+		}
+	}
+	return used, nil
+}
+
+// deleteBundleDefinition removes a bundle definition from storage.
+func (m *Manager) deleteBundleDefinition(digest string) error {
+	delete(m.bundleDefinitions, digest)
+	err := m.BackingStore.Delete(ItemTypeBundleDefinitions, bundleDefinitionName(digest))
+	return errors.Wrapf(err, "could not remove bundle definition %s, which is no longer used", digest)
+}
+
+// removeUnusedBundleDefinitions removes the bundle definitions that are not
+// referenced by any claim. Deleting a claim keeps its bundle definition, because
+// finding the other claims that reference it requires reading every claim, so
+// unused bundle definitions are removed when storage is migrated.
+func (m *Manager) removeUnusedBundleDefinitions(w io.Writer) error {
+	used, err := m.listUsedBundleDefinitions()
+	if err != nil {
+		return errors.Wrap(err, "could not determine which bundle definitions are used")
+	}
+
+	names, err := m.BackingStore.List(ItemTypeBundleDefinitions, "")
+	if err != nil {
+		return errors.Wrap(err, "could not list bundle definitions")
+	}
+
+	var removeErr *multierror.Error
+	for _, name := range names {
+		digest := strings.Replace(name, "-", ":", 1)
+		if used[digest] {
+			continue
+		}
+
+		fmt.Fprintf(w, " - Removing unused bundle definition %s\n", digest)
+		if err := m.deleteBundleDefinition(digest); err != nil {
+			removeErr = multierror.Append(removeErr, err)
+		}
+	}
+	return removeErr.ErrorOrNil()
+}
+
+// bundleDefinitionName is the name of the document for a bundle definition,
+// which cannot contain a colon because some storage, such as the filesystem
+// on Windows, does not allow it.
+func bundleDefinitionName(digest string) string {
+	return strings.Replace(digest, ":", "-", 1)
+}
+
+// ShouldMigrateBundleDefinitions determines if there are claims that include
+// their bundle definition, instead of referencing a shared copy. The
+// migration is optional because claims in either format can be read.
+func (m *Manager) ShouldMigrateBundleDefinitions() bool {
+	return m.schema.BundleDefinitions != BundleDefinitionsSchemaVersion
+}
+
+// migrateBundleDefinitions moves the bundle definition out of each claim that
+// includes one, so that claims for the same bundle share a single copy.
+func (m *Manager) migrateBundleDefinitions(w io.Writer) error {
+	fmt.Fprintln(w, "Migrating claims to reference their bundle definition, so that claims for the same bundle share a single copy.")
+
+	installationNames, err := m.BackingStore.List(claim.ItemTypeInstallations, "")
+	if err != nil {
+		return errors.Wrap(err, "Migration failed, unable to list installation names")
+	}
+
+	var migrationErr *multierror.Error
+	for _, installationName := range installationNames {
+		claimIDs, err := m.BackingStore.List(claim.ItemTypeClaims, installationName)
+		if err != nil {
+			migrationErr = multierror.Append(migrationErr, errors.Wrapf(err, "Cannot list the claims of installation %s. Skipping.", installationName))
+			continue
+		}
+
+		fmt.Fprintf(w, " - Migrating the claims of installation %s...\n", installationName)
+		for _, claimID := range claimIDs {
+			data, err := m.BackingStore.Read(claim.ItemTypeClaims, claimID)
+			if err != nil {
+				migrationErr = multierror.Append(migrationErr, errors.Wrapf(err, "Cannot read claim %s to migrate it. Skipping.", claimID))
+				continue
+			}
+
+			deduped, err := m.dedupeClaimBundle(data)
+			if err != nil {
+				migrationErr = multierror.Append(migrationErr, errors.Wrapf(err, "Cannot migrate claim %s. Skipping.", claimID))
+				continue
+			}
+			if bytes.Equal(data, deduped) {
+				continue
+			}
+
+			err = m.BackingStore.Save(claim.ItemTypeClaims, installationName, claimID, deduped)
+			if err != nil {
+				migrationErr = multierror.Append(migrationErr, errors.Wrapf(err, "Cannot save migrated claim %s. Skipping.", claimID))
+			}
+		}
+	}
+
+	if err := m.removeUnusedBundleDefinitions(w); err != nil {
+		migrationErr = multierror.Append(migrationErr, err)
+	}
+
+	return migrationErr.ErrorOrNil()
+}
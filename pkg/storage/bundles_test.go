@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"get.porter.sh/porter/pkg/config"
+	"get.porter.sh/porter/pkg/storage/filesystem"
+	"github.com/cnabio/cnab-go/bundle"
+	"github.com/cnabio/cnab-go/claim"
+	"github.com/cnabio/cnab-go/utils/crud"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_DedupeClaimBundles(t *testing.T) {
+	c := config.NewTestConfig(t)
+	dataStore := crud.NewBackingStore(crud.NewMockStore())
+	mgr := NewManager(c.Config, dataStore)
+	claimStore := claim.NewClaimStore(mgr, nil, nil)
+
+	bun := bundle.Bundle{Name: "mybuns", Version: "0.1.0", InvocationImages: []bundle.InvocationImage{
+		{BaseImage: bundle.BaseImage{Image: "getporter/mybuns:v0.1.0"}},
+	}}
+	installClaim, err := claim.New("mybuns", claim.ActionInstall, bun, nil)
+	require.NoError(t, err)
+	require.NoError(t, claimStore.SaveClaim(installClaim))
+	upgradeClaim, err := installClaim.NewClaim(claim.ActionUpgrade, bun, nil)
+	require.NoError(t, err)
+	require.NoError(t, claimStore.SaveClaim(upgradeClaim))
+
+	// Both claims should share a single copy of the bundle definition
+	defs, err := dataStore.List(ItemTypeBundleDefinitions, "")
+	require.NoError(t, err)
+	assert.Len(t, defs, 1, "expected a single bundle definition")
+
+	rawClaim, err := dataStore.Read(claim.ItemTypeClaims, installClaim.ID)
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(rawClaim, &doc))
+	assert.NotContains(t, doc, "bundle", "the stored claim should not include the bundle definition")
+	assert.Contains(t, doc, "bundleDigest", "the stored claim should reference the bundle definition")
+
+	// Claims are returned with their bundle definition
+	i, err := claimStore.ReadInstallation("mybuns")
+	require.NoError(t, err)
+	require.Len(t, i.Claims, 2)
+	for _, c := range i.Claims {
+		assert.Equal(t, bun, c.Bundle)
+	}
+
+	gotClaim, err := claimStore.ReadClaim(upgradeClaim.ID)
+	require.NoError(t, err)
+	assert.Equal(t, bun, gotClaim.Bundle)
+}
+
+func TestManager_DeleteUnusedBundleDefinitions(t *testing.T) {
+	c := config.NewTestConfig(t)
+	dataStore := crud.NewBackingStore(crud.NewMockStore())
+	mgr := NewManager(c.Config, dataStore)
+	claimStore := claim.NewClaimStore(mgr, nil, nil)
+
+	bun := bundle.Bundle{Name: "mybuns", Version: "0.1.0"}
+	installClaim, err := claim.New("mybuns", claim.ActionInstall, bun, nil)
+	require.NoError(t, err)
+	require.NoError(t, claimStore.SaveClaim(installClaim))
+	upgradeClaim, err := installClaim.NewClaim(claim.ActionUpgrade, bun, nil)
+	require.NoError(t, err)
+	require.NoError(t, claimStore.SaveClaim(upgradeClaim))
+
+	otherClaim, err := claim.New("otherbuns", claim.ActionInstall, bundle.Bundle{Name: "otherbuns", Version: "0.1.0"}, nil)
+	require.NoError(t, err)
+	require.NoError(t, claimStore.SaveClaim(otherClaim))
+
+	defs, err := dataStore.List(ItemTypeBundleDefinitions, "")
+	require.NoError(t, err)
+	require.Len(t, defs, 2)
+
+	require.NoError(t, claimStore.DeleteClaim(installClaim.ID))
+	require.NoError(t, mgr.removeUnusedBundleDefinitions(ioutil.Discard))
+	defs, err = dataStore.List(ItemTypeBundleDefinitions, "")
+	require.NoError(t, err)
+	assert.Len(t, defs, 2, "a bundle definition that is still referenced should be kept")
+
+	require.NoError(t, claimStore.DeleteInstallation("mybuns"))
+	defs, err = dataStore.List(ItemTypeBundleDefinitions, "")
+	require.NoError(t, err)
+	assert.Len(t, defs, 2, "deleting a claim should not remove its bundle definition")
+
+	require.NoError(t, mgr.removeUnusedBundleDefinitions(ioutil.Discard))
+	defs, err = dataStore.List(ItemTypeBundleDefinitions, "")
+	require.NoError(t, err)
+	assert.Len(t, defs, 1, "a bundle definition that is no longer referenced should be removed")
+
+	gotClaim, err := claimStore.ReadClaim(otherClaim.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "otherbuns", gotClaim.Bundle.Name)
+
+	// Saving a claim for a bundle whose definition was removed saves it again
+	reinstallClaim, err := claim.New("mybuns", claim.ActionInstall, bun, nil)
+	require.NoError(t, err)
+	require.NoError(t, claimStore.SaveClaim(reinstallClaim))
+	gotClaim, err = claim.NewClaimStore(NewManager(c.Config, dataStore), nil, nil).ReadClaim(reinstallClaim.ID)
+	require.NoError(t, err)
+	assert.Equal(t, bun, gotClaim.Bundle)
+}
+
+func TestManager_ReadClaimWithBundle(t *testing.T) {
+	c := config.NewTestConfig(t)
+	dataStore := crud.NewBackingStore(crud.NewMockStore())
+	mgr := NewManager(c.Config, dataStore)
+	claimStore := claim.NewClaimStore(mgr, nil, nil)
+
+	// Claims saved before bundle definitions were deduplicated include the bundle
+	bun := bundle.Bundle{Name: "mybuns", Version: "0.1.0"}
+	installClaim, err := claim.New("mybuns", claim.ActionInstall, bun, nil)
+	require.NoError(t, err)
+	require.NoError(t, claim.NewClaimStore(dataStore, nil, nil).SaveClaim(installClaim))
+
+	gotClaim, err := claimStore.ReadClaim(installClaim.ID)
+	require.NoError(t, err)
+	assert.Equal(t, bun, gotClaim.Bundle)
+}
+
+func TestManager_MigrateBundleDefinitions(t *testing.T) {
+	config := config.NewTestConfig(t)
+	_, home := config.TestContext.UseFilesystem()
+	config.SetHomeDir(home)
+	defer config.TestContext.Cleanup()
+
+	dataStore := crud.NewBackingStore(filesystem.NewStore(*config.Config, hclog.NewNullLogger()))
+	mgr := NewManager(config.Config, dataStore)
+	claimStore := claim.NewClaimStore(mgr, nil, nil)
+
+	claimsDir := filepath.Join(home, "claims")
+	config.FileSystem.Mkdir(claimsDir, 0755)
+	config.TestContext.AddTestFile("testdata/claims/upgraded.json", filepath.Join(claimsDir, "upgraded.json"))
+	require.NoError(t, dataStore.Save(ItemTypeBundleDefinitions, "", "sha256-unused", []byte(`{}`)))
+
+	_, err := mgr.Migrate()
+	require.NoError(t, err, "Migrate failed")
+	assert.Equal(t, BundleDefinitionsSchemaVersion, mgr.schema.BundleDefinitions)
+
+	defs, err := dataStore.List(ItemTypeBundleDefinitions, "")
+	require.NoError(t, err)
+	assert.Len(t, defs, 1, "the migrated claims should share a single bundle definition")
+	assert.NotContains(t, defs, "sha256-unused", "unused bundle definitions should be removed")
+
+	i, err := claimStore.ReadInstallation("mybun")
+	require.NoError(t, err, "ReadInstallation of the migrated claims failed")
+	require.Len(t, i.Claims, 2)
+	assert.NotEmpty(t, i.Claims[0].Bundle.Name)
+	assert.Equal(t, i.Claims[0].Bundle, i.Claims[1].Bundle)
+
+	// Unused bundle definitions are removed when the schema is up-to-date
+	require.NoError(t, dataStore.Save(ItemTypeBundleDefinitions, "", "sha256-unused", []byte(`{}`)))
+	_, err = mgr.Migrate()
+	require.NoError(t, err, "Migrate failed")
+	defs, err = dataStore.List(ItemTypeBundleDefinitions, "")
+	require.NoError(t, err)
+	assert.Len(t, defs, 1)
+	assert.NotContains(t, defs, "sha256-unused", "unused bundle definitions should be removed")
+}
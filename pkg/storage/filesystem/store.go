@@ -51,6 +51,9 @@ func NewFileExtensions() map[string]string {
 	// TODO (carolynvs): change to parameters.ItemType once parameters move to cnab-go
 	ext["parameters"] = jsonExt
 
+	// Bundle definitions referenced by claims, defined in storage.ItemTypeBundleDefinitions
+	ext["bundle-definitions"] = jsonExt
+
 	// Handle top level files, like schema.json
 	ext[""] = jsonExt
 
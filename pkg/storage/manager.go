@@ -42,6 +42,9 @@ type Manager struct {
 	// Allow the schema to be out-of-date, defaults to false. Prevents
 	// connections to underlying storage when the schema is out-of-date
 	allowOutOfDateSchema bool
+
+	// bundleDefinitions that have been read or saved, indexed by digest.
+	bundleDefinitions map[string][]byte
 }
 
 // NewManager creates a storage manager for a backing datastore.
@@ -117,12 +120,23 @@ func (m *Manager) List(itemType string, group string) ([]string, error) {
 }
 
 func (m *Manager) Save(itemType string, group string, name string, data []byte) error {
+	if m.ReadOnly {
+		return errors.Wrapf(config.ErrReadOnly, "cannot save %s %s", itemType, name)
+	}
+
 	handleClose, err := m.HandleConnect()
 	defer handleClose()
 	if err != nil {
 		return err
 	}
 
+	if itemType == claim.ItemTypeClaims {
+		data, err = m.dedupeClaimBundle(data)
+		if err != nil {
+			return errors.Wrapf(err, "could not save claim %s", name)
+		}
+	}
+
 	return m.BackingStore.Save(itemType, group, name, data)
 }
 
@@ -133,7 +147,12 @@ func (m *Manager) Read(itemType string, name string) ([]byte, error) {
 		return nil, err
 	}
 
-	return m.BackingStore.Read(itemType, name)
+	data, err := m.BackingStore.Read(itemType, name)
+	if err != nil || itemType != claim.ItemTypeClaims {
+		return data, err
+	}
+
+	return m.rehydrateClaim(data)
 }
 
 func (m *Manager) ReadAll(itemType string, group string) ([][]byte, error) {
@@ -143,10 +162,25 @@ func (m *Manager) ReadAll(itemType string, group string) ([][]byte, error) {
 		return nil, err
 	}
 
-	return m.BackingStore.ReadAll(itemType, group)
+	items, err := m.BackingStore.ReadAll(itemType, group)
+	if err != nil || itemType != claim.ItemTypeClaims {
+		return items, err
+	}
+
+	for i, data := range items {
+		items[i], err = m.rehydrateClaim(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
 }
 
 func (m *Manager) Delete(itemType string, name string) error {
+	if m.ReadOnly {
+		return errors.Wrapf(config.ErrReadOnly, "cannot delete %s %s", itemType, name)
+	}
+
 	handleClose, err := m.HandleConnect()
 	defer handleClose()
 	if err != nil {
@@ -186,6 +220,10 @@ func (m *Manager) MigrationRequired() bool {
 
 // Migrate executes a migration on any/all of Porter's storage sub-systems.
 func (m *Manager) Migrate() (string, error) {
+	if m.ReadOnly {
+		return "", errors.Wrap(config.ErrReadOnly, "cannot migrate storage")
+	}
+
 	m.resetSchema()
 
 	// Let us call connect and not have it kick us out because the schema is out-of-date
@@ -239,6 +277,16 @@ func (m *Manager) Migrate() (string, error) {
 		fmt.Fprintln(w, "Parameters schema is up-to-date")
 	}
 
+	if m.ShouldMigrateBundleDefinitions() {
+		fmt.Fprintf(w, "Bundle definitions schema is out-of-date (want: %s got: %s)\n", BundleDefinitionsSchemaVersion, m.schema.BundleDefinitions)
+		err = m.migrateBundleDefinitions(w)
+		migrationErr = multierror.Append(migrationErr, err)
+	} else {
+		fmt.Fprintln(w, "Bundle definitions schema is up-to-date")
+		err = m.removeUnusedBundleDefinitions(w)
+		migrationErr = multierror.Append(migrationErr, err)
+	}
+
 	if migrationErr.ErrorOrNil() == nil {
 		err = m.writeSchema(w)
 		migrationErr = multierror.Append(migrationErr, err)
@@ -285,6 +333,12 @@ func (m *Manager) initEmptyPorterHome(w io.Writer) (bool, error) {
 		return false, err
 	}
 
+	if m.ReadOnly {
+		// Use the current schema without persisting it
+		m.schema = currentSchema()
+		return true, nil
+	}
+
 	return true, m.writeSchema(w)
 }
 
@@ -313,13 +367,20 @@ func (m *Manager) migrateClaims(w io.Writer) error {
 	return migrationErr.ErrorOrNil()
 }
 
-// writeSchema updates the schema with the most recent version then writes it to disk.
-func (m *Manager) writeSchema(w io.Writer) error {
-	m.schema = Schema{
+// currentSchema returns the schema of each storage system supported by this version of Porter.
+func currentSchema() Schema {
+	return Schema{
 		Claims:      schema.Version(claim.CNABSpecVersion),
 		Credentials: schema.Version(credentials.CNABSpecVersion),
 		Parameters:  schema.Version(ParameterSetCNABSpecVersion),
+
+		BundleDefinitions: BundleDefinitionsSchemaVersion,
 	}
+}
+
+// writeSchema updates the schema with the most recent version then writes it to disk.
+func (m *Manager) writeSchema(w io.Writer) error {
+	m.schema = currentSchema()
 	schemaB, err := json.Marshal(m.schema)
 	if err != nil {
 		return errors.Wrap(err, "Unable to marshal storage schema file")
@@ -361,22 +422,9 @@ func (m *Manager) migrateInstallation(w io.Writer, name string) error {
 		return errors.Wrap(err, "could not read claim file")
 	}
 
-	if getSchemaVersion(oldClaimData) == "" {
-		oldClaimData, err = m.migrateUnversionedClaim(w, name, oldClaimData)
-		if err != nil {
-			return err
-		}
-	}
-
-	var old claimd7ffba8
-	err = json.Unmarshal(oldClaimData, &old)
+	newClaims, newResults, newOutputs, err := ConvertLegacyClaim(w, name, oldClaimData)
 	if err != nil {
-		return errors.Wrapf(err, "could not load claim file:\n%s", string(oldClaimData))
-	}
-
-	newClaims, newResults, newOutputs, err := m.splitClaim(old)
-	if err != nil {
-		return errors.Wrapf(err, "could not split claim:\n%v", old)
+		return err
 	}
 
 	claimStore := claim.NewClaimStore(m.BackingStore, nil, nil)
@@ -410,9 +458,39 @@ func (m *Manager) migrateInstallation(w io.Writer, name string) error {
 	return nil
 }
 
+// ConvertLegacyClaim converts a claim stored in the older single document format,
+// either unversioned or cnab-claim-1.0.0-DRAFT+d7ffba8, into a set of claims,
+// results and outputs in the current supported schema. The name is the name
+// of the installation, used when reporting progress to w.
+func ConvertLegacyClaim(w io.Writer, name string, data []byte) ([]claim.Claim, []claim.Result, []claim.Output, error) {
+	var err error
+	if getSchemaVersion(data) == "" {
+		data, err = migrateUnversionedClaim(w, name, data)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	var old claimd7ffba8
+	err = json.Unmarshal(data, &old)
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "could not load claim file:\n%s", string(data))
+	}
+	if old.Bundle == nil {
+		return nil, nil, nil, errors.New("claim does not contain a bundle definition")
+	}
+
+	newClaims, newResults, newOutputs, err := splitClaim(old)
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "could not split claim:\n%v", old)
+	}
+
+	return newClaims, newResults, newOutputs, nil
+}
+
 // splitClaim takes a claim in the old single document format and creates a set of claims, results and outputs
 // in the current supported schema.
-func (m *Manager) splitClaim(old claimd7ffba8) ([]claim.Claim, []claim.Result, []claim.Output, error) {
+func splitClaim(old claimd7ffba8) ([]claim.Claim, []claim.Result, []claim.Output, error) {
 	// Handle old status values
 	switch old.Result.Status {
 	case "success":
@@ -468,7 +546,7 @@ func (m *Manager) splitClaim(old claimd7ffba8) ([]claim.Claim, []claim.Result, [
 
 // migrateUnversionedClaim migrates a claim from Name -> Installation from before
 // claims has a schemaVersion field.
-func (m *Manager) migrateUnversionedClaim(w io.Writer, name string, data []byte) ([]byte, error) {
+func migrateUnversionedClaim(w io.Writer, name string, data []byte) ([]byte, error) {
 	var rawData map[string]interface{}
 	err := json.Unmarshal(data, &rawData)
 	if err != nil {
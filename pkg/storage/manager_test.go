@@ -337,3 +337,41 @@ func TestManager_ShouldMigrateParameters(t *testing.T) {
 }
 
 // NOTE: TestManager_MigrateParameters is in parameterset_test.go to avoid a circular dependency
+
+func TestManager_ReadOnly(t *testing.T) {
+	c := config.NewTestConfig(t)
+	c.ReadOnly = true
+	dataStore := crud.NewBackingStore(crud.NewMockStore())
+	dataStore.Save("claims", "", "mybun", []byte("{}"))
+	mgr := NewManager(c.Config, dataStore)
+	mgr.schema = currentSchema()
+	mgr.schemaLoaded = true
+
+	_, err := mgr.Read("claims", "mybun")
+	require.NoError(t, err, "reads should be allowed in read-only mode")
+
+	err = mgr.Save("claims", "", "mybun", []byte("{}"))
+	require.Error(t, err, "Save should be rejected in read-only mode")
+	assert.Contains(t, err.Error(), "cannot save claims mybun: porter is running in read-only mode")
+
+	err = mgr.Delete("claims", "mybun")
+	require.Error(t, err, "Delete should be rejected in read-only mode")
+	assert.Contains(t, err.Error(), "cannot delete claims mybun: porter is running in read-only mode")
+
+	_, err = mgr.Migrate()
+	require.Error(t, err, "Migrate should be rejected in read-only mode")
+	assert.Contains(t, err.Error(), "cannot migrate storage: porter is running in read-only mode")
+}
+
+func TestManager_ReadOnly_EmptyHome(t *testing.T) {
+	c := config.NewTestConfig(t)
+	c.ReadOnly = true
+	dataStore := crud.NewBackingStore(crud.NewMockStore())
+	mgr := NewManager(c.Config, dataStore)
+
+	_, err := mgr.List("claims", "")
+	require.NoError(t, err, "List failed")
+
+	_, err = dataStore.Read("", "schema")
+	require.Error(t, err, "the schema should not be written in read-only mode")
+}
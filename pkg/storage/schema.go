@@ -6,4 +6,9 @@ type Schema struct {
 	Claims      schema.Version `json:"claims"`
 	Credentials schema.Version `json:"credentials"`
 	Parameters  schema.Version `json:"parameters"`
+
+	// BundleDefinitions is the version of the storage format for the bundle
+	// definitions referenced by claims. Claims in older formats include their
+	// bundle definition, which does not require a migration.
+	BundleDefinitions schema.Version `json:"bundleDefinitions,omitempty"`
 }
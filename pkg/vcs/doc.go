@@ -0,0 +1 @@
+package vcs // import "get.porter.sh/porter/pkg/vcs"
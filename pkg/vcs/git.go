@@ -0,0 +1,151 @@
+package vcs
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"get.porter.sh/porter/pkg/context"
+	"github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+)
+
+// Info describes the state of the git repository that contains a directory.
+type Info struct {
+	// Commit is the full hash of the checked out commit.
+	Commit string
+
+	// Dirty is true when the directory has uncommitted changes.
+	Dirty bool
+
+	// Tag is the most recent semver tag reachable from the commit, or empty
+	// when the repository has no semver tags.
+	Tag string
+
+	// CommitsSinceTag is the number of commits made after Tag, or the total
+	// number of commits when there is no tag.
+	CommitsSinceTag int
+}
+
+// ShortCommit returns the abbreviated commit hash.
+func (i Info) ShortCommit() string {
+	if len(i.Commit) > 7 {
+		return i.Commit[:7]
+	}
+	return i.Commit
+}
+
+// Inspect reads the state of the git repository containing the specified
+// directory. Generated files in the .cnab directory are not considered when
+// determining if the directory is dirty.
+func Inspect(cxt *context.Context, dir string) (Info, error) {
+	var info Info
+
+	commit, err := git(cxt, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return Info{}, errors.Wrapf(err, "could not determine the current commit of %s, is it a git repository with at least one commit?", dir)
+	}
+	info.Commit = commit
+
+	status, err := git(cxt, dir, "status", "--porcelain", "--", ".", ":(exclude).cnab")
+	if err != nil {
+		return Info{}, errors.Wrapf(err, "could not determine if %s has uncommitted changes", dir)
+	}
+	info.Dirty = status != ""
+
+	// Only consider tags that look like a version, with or without the leading v
+	described, err := git(cxt, dir, "describe", "--tags", "--long", "--match", "v[0-9]*", "--match", "[0-9]*")
+	if err != nil {
+		// There are no version tags, count all the commits instead
+		count, err := git(cxt, dir, "rev-list", "--count", "HEAD")
+		if err != nil {
+			return Info{}, errors.Wrapf(err, "could not count the commits in %s", dir)
+		}
+		info.CommitsSinceTag, err = strconv.Atoi(count)
+		if err != nil {
+			return Info{}, errors.Wrapf(err, "invalid commit count %q", count)
+		}
+		return info, nil
+	}
+
+	info.Tag, info.CommitsSinceTag, err = parseDescribe(described)
+	return info, err
+}
+
+// parseDescribe splits the output of git describe --long, TAG-COUNT-gHASH,
+// into the tag and the number of commits since the tag.
+func parseDescribe(described string) (string, int, error) {
+	parts := strings.Split(described, "-")
+	if len(parts) < 3 {
+		return "", 0, errors.Errorf("unexpected output from git describe %q", described)
+	}
+
+	count, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "unexpected output from git describe %q", described)
+	}
+
+	tag := strings.Join(parts[:len(parts)-2], "-")
+	return tag, count, nil
+}
+
+// Version derives a semantic version from the repository state.
+//   - When the commit is tagged, the tag's version is used: v1.2.3 -> 1.2.3.
+//   - When there are commits after the tag, the next patch version is used as a
+//     prerelease with the number of commits and the commit hash: 1.2.4-dev.5+g1a2b3c4.
+//     If the tag is already a prerelease, the prerelease is extended instead: 1.2.3-beta.1.dev.5+g1a2b3c4.
+//   - When there are no version tags, 0.0.0 is used as the base version.
+//   - Uncommitted changes are flagged in the build metadata: 1.2.3+dirty.
+func (i Info) Version() (string, error) {
+	base := semver.MustParse("0.0.0")
+	if i.Tag != "" {
+		v, err := semver.NewVersion(i.Tag)
+		if err != nil {
+			return "", errors.Wrapf(err, "the most recent tag %q is not a valid semantic version", i.Tag)
+		}
+		base = v
+	}
+
+	var metadata []string
+	v := *base
+	if i.Tag == "" || i.CommitsSinceTag > 0 {
+		dev := fmt.Sprintf("dev.%d", i.CommitsSinceTag)
+		var err error
+		if base.Prerelease() != "" {
+			v, err = base.SetPrerelease(base.Prerelease() + "." + dev)
+		} else {
+			if i.Tag != "" {
+				v = base.IncPatch()
+			}
+			v, err = v.SetPrerelease(dev)
+		}
+		if err != nil {
+			return "", errors.Wrap(err, "could not set the prerelease version")
+		}
+		metadata = append(metadata, "g"+i.ShortCommit())
+	}
+
+	if i.Dirty {
+		metadata = append(metadata, "dirty")
+	}
+
+	v, err := v.SetMetadata(strings.Join(metadata, "."))
+	if err != nil {
+		return "", errors.Wrap(err, "could not set the version build metadata")
+	}
+
+	return v.String(), nil
+}
+
+func git(cxt *context.Context, dir string, args ...string) (string, error) {
+	args = append([]string{"-C", dir}, args...)
+	cmd := cxt.NewCommand("git", args...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,113 @@
+package vcs
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"get.porter.sh/porter/pkg/context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfo_Version(t *testing.T) {
+	testcases := []struct {
+		name string
+		info Info
+		want string
+	}{
+		{name: "tagged", info: Info{Commit: "1a2b3c4d5e", Tag: "v1.2.3"}, want: "1.2.3"},
+		{name: "tagged without v prefix", info: Info{Commit: "1a2b3c4d5e", Tag: "1.2.3"}, want: "1.2.3"},
+		{name: "tagged and dirty", info: Info{Commit: "1a2b3c4d5e", Tag: "v1.2.3", Dirty: true}, want: "1.2.3+dirty"},
+		{name: "after tag", info: Info{Commit: "1a2b3c4d5e", Tag: "v1.2.3", CommitsSinceTag: 5}, want: "1.2.4-dev.5+g1a2b3c4"},
+		{name: "after prerelease tag", info: Info{Commit: "1a2b3c4d5e", Tag: "v1.2.3-beta.1", CommitsSinceTag: 5}, want: "1.2.3-beta.1.dev.5+g1a2b3c4"},
+		{name: "after tag and dirty", info: Info{Commit: "1a2b3c4d5e", Tag: "v1.2.3", CommitsSinceTag: 5, Dirty: true}, want: "1.2.4-dev.5+g1a2b3c4.dirty"},
+		{name: "no tags", info: Info{Commit: "1a2b3c4d5e", CommitsSinceTag: 3}, want: "0.0.0-dev.3+g1a2b3c4"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.info.Version()
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("invalid tag", func(t *testing.T) {
+		_, err := Info{Commit: "1a2b3c4d5e", Tag: "vlatest"}.Version()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `the most recent tag "vlatest" is not a valid semantic version`)
+	})
+}
+
+func TestParseDescribe(t *testing.T) {
+	tag, count, err := parseDescribe("v1.0.0-rc.1-12-g1a2b3c4")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0-rc.1", tag)
+	assert.Equal(t, 12, count)
+
+	_, _, err = parseDescribe("v1.0.0")
+	assert.EqualError(t, err, `unexpected output from git describe "v1.0.0"`)
+}
+
+func TestInspect(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	cxt := context.NewTestContext(t)
+	cxt.UseFilesystem()
+	defer cxt.Cleanup()
+
+	dir, err := ioutil.TempDir("", "porter-vcs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	write := func(name string) {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0644))
+	}
+
+	_, err = Inspect(cxt.Context, dir)
+	require.Error(t, err, "expected an error for a directory that isn't a repository")
+
+	run("init", "-q")
+	write("porter.yaml")
+	run("add", ".")
+	run("commit", "-q", "-m", "first")
+
+	info, err := Inspect(cxt.Context, dir)
+	require.NoError(t, err)
+	assert.Len(t, info.Commit, 40)
+	assert.False(t, info.Dirty)
+	assert.Empty(t, info.Tag)
+	assert.Equal(t, 1, info.CommitsSinceTag)
+
+	run("tag", "v0.1.0")
+	write("README.md")
+	run("add", ".")
+	run("commit", "-q", "-m", "second")
+
+	info, err = Inspect(cxt.Context, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "v0.1.0", info.Tag)
+	assert.Equal(t, 1, info.CommitsSinceTag)
+
+	// Generated files should not make the directory dirty
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".cnab"), 0755))
+	write(".cnab/bundle.json")
+	info, err = Inspect(cxt.Context, dir)
+	require.NoError(t, err)
+	assert.False(t, info.Dirty)
+
+	write("porter.yaml.bak")
+	info, err = Inspect(cxt.Context, dir)
+	require.NoError(t, err)
+	assert.True(t, info.Dirty)
+}